@@ -0,0 +1,110 @@
+package whatsappdau
+
+import (
+	"net/http"
+	"time"
+)
+
+// AdaptiveThrottle slows the client down as the business use case usage the
+// API reports approaches 100%, instead of running into a lockout. Past Start
+// percent every request waits, up to MaxDelay at 100%, and the client's
+// Backpressure is held for as long so BulkSender and Outbox ease off too.
+// While Meta reports a lockout, requests fail at once with a *LimitError.
+type AdaptiveThrottle struct {
+	// Start is the usage percentage at which slowing begins. Default 75.
+	Start int
+	// MaxDelay is the wait before each request at 100%. Default 5s.
+	MaxDelay time.Duration
+	// Stale is how long a usage report counts towards slowing down. Default
+	// 1 minute. A reported lockout lasts until it ends regardless.
+	Stale time.Duration
+}
+
+// WithAdaptiveThrottling paces requests by the reported usage, see
+// AdaptiveThrottle.
+func WithAdaptiveThrottling(a AdaptiveThrottle) Option {
+	return func(w *WhatsappClient) {
+		w.adaptive = &a
+	}
+}
+
+func (a *AdaptiveThrottle) start() int {
+	if a.Start <= 0 || a.Start >= 100 {
+		return 75
+	}
+	return a.Start
+}
+
+func (a *AdaptiveThrottle) maxDelay() time.Duration {
+	if a.MaxDelay <= 0 {
+		return 5 * time.Second
+	}
+	return a.MaxDelay
+}
+
+func (a *AdaptiveThrottle) stale() time.Duration {
+	if a.Stale <= 0 {
+		return time.Minute
+	}
+	return a.Stale
+}
+
+// delay returns the wait usage calls for, growing with the square of how
+// far it is past Start, and when a lockout ends if one is reported.
+func (a *AdaptiveThrottle) delay(usage []BusinessUseCaseUsage, now time.Time) (time.Duration, time.Time) {
+	percent := 0
+	var lockedUntil time.Time
+	for _, u := range usage {
+		// No fresh report arrives during a lockout, as requests are refused
+		// without calling the API, so a lockout holds however old it is.
+		if regain := u.RegainAccessAt(); regain.After(now) && regain.After(lockedUntil) {
+			lockedUntil = regain
+		}
+		if now.Sub(u.ObservedAt) <= a.stale() {
+			percent = max(percent, u.Percent())
+		}
+	}
+	start := a.start()
+	if percent <= start {
+		return 0, lockedUntil
+	}
+	frac := float64(min(percent, 100)-start) / float64(100-start)
+	return time.Duration(frac * frac * float64(a.maxDelay())), lockedUntil
+}
+
+type adaptiveDoer struct {
+	next     HTTPDoer
+	throttle *AdaptiveThrottle
+	usage    *usageTracker
+	pressure *Backpressure
+}
+
+func (d *adaptiveDoer) Do(req *http.Request) (*http.Response, error) {
+	wait, lockedUntil := d.throttle.delay(d.usage.snapshot(), time.Now())
+	if !lockedUntil.IsZero() {
+		return nil, &LimitError{Limit: 100, RetryAt: lockedUntil, Reason: "business use case usage"}
+	}
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	resp, err := d.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	now := time.Now()
+	wait, lockedUntil = d.throttle.delay(d.usage.snapshot(), now)
+	switch {
+	case !lockedUntil.IsZero():
+		d.pressure.holdUntil(lockedUntil)
+	case wait > 0:
+		d.pressure.holdUntil(now.Add(wait))
+	}
+	return resp, nil
+}
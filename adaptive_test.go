@@ -0,0 +1,39 @@
+package whatsappdau
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveThrottleDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := &AdaptiveThrottle{MaxDelay: 4 * time.Second}
+	tests := []struct {
+		name   string
+		usage  []BusinessUseCaseUsage
+		wait   time.Duration
+		locked time.Time
+	}{
+		{"none", nil, 0, time.Time{}},
+		{"below start", []BusinessUseCaseUsage{{CallCount: 75, ObservedAt: now}}, 0, time.Time{}},
+		{"past start", []BusinessUseCaseUsage{{CallCount: 50, TotalTime: 85, ObservedAt: now}}, 640 * time.Millisecond, time.Time{}},
+		{"full", []BusinessUseCaseUsage{{TotalCPUTime: 100, ObservedAt: now}}, 4 * time.Second, time.Time{}},
+		{"over", []BusinessUseCaseUsage{{CallCount: 140, ObservedAt: now}}, 4 * time.Second, time.Time{}},
+		{"stale", []BusinessUseCaseUsage{{CallCount: 100, ObservedAt: now.Add(-2 * time.Minute)}}, 0, time.Time{}},
+		{"highest counts", []BusinessUseCaseUsage{
+			{CallCount: 80, ObservedAt: now},
+			{CallCount: 100, ObservedAt: now},
+		}, 4 * time.Second, time.Time{}},
+		{"locked out", []BusinessUseCaseUsage{{CallCount: 100, EstimatedTimeToRegainAccess: 5, ObservedAt: now}}, 4 * time.Second, now.Add(5 * time.Minute)},
+		{"lockout outlives stale", []BusinessUseCaseUsage{{CallCount: 100, EstimatedTimeToRegainAccess: 30, ObservedAt: now.Add(-10 * time.Minute)}}, 0, now.Add(20 * time.Minute)},
+		{"lockout over", []BusinessUseCaseUsage{{CallCount: 100, EstimatedTimeToRegainAccess: 5, ObservedAt: now.Add(-10 * time.Minute)}}, 0, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, locked := a.delay(tt.usage, now)
+			if wait != tt.wait || !locked.Equal(tt.locked) {
+				t.Errorf("delay() = %v, %v; want %v, %v", wait, locked, tt.wait, tt.locked)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package whatsappdau
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Admin is an HTTP API for inspecting and adjusting a running instance.
+// Every request needs "Authorization: Bearer <Token>".
+//
+//	GET  /                 everything below in one document
+//	GET  /config           client configuration, token redacted
+//	GET  /limiters         limiter types and budgets
+//	GET  /usage            business use case usage last reported by the API
+//	GET  /breaker          whether sends are held back after throttling
+//	GET  /outbox           queued and dead-lettered entries, if Outbox is set
+//	GET  /sections/{name}  a section added with Expose
+//	GET  /toggles          current toggle values
+//	POST /toggles/{name}   set a toggle, body {"enabled": true}
+//
+// The maintenance and debug toggles are built in; debug switches the package
+// log level with SetDebugLogging, and WithDebug logging for clients built
+// with it. Other subsystems publish their state with Expose.
+type Admin struct {
+	Token      string
+	Client     *WhatsappClient
+	Dispatcher *Dispatcher
+	Coverage   *Coverage
+	Outbox     *Outbox
+
+	mu       sync.Mutex
+	sections map[string]func(ctx context.Context) (interface{}, error)
+	toggles  map[string]adminToggle
+	mux      *http.ServeMux
+}
+
+type adminToggle struct {
+	get func() bool
+	set func(bool)
+}
+
+func NewAdmin(token string, client *WhatsappClient) *Admin {
+	a := &Admin{
+		Token:    token,
+		Client:   client,
+		sections: make(map[string]func(ctx context.Context) (interface{}, error)),
+		toggles:  make(map[string]adminToggle),
+	}
+	if client != nil {
+		a.Toggle("maintenance", client.Maintenance, client.SetMaintenance)
+	}
+	a.Toggle("debug", DebugLogging, func(on bool) {
+		SetDebugLogging(on)
+		if client != nil {
+			client.SetDebug(on)
+		}
+	})
+
+	a.mux = http.NewServeMux()
+	a.mux.HandleFunc("GET /{$}", a.serveAll)
+	a.mux.HandleFunc("GET /config", a.serveSection(a.config))
+	a.mux.HandleFunc("GET /limiters", a.serveSection(a.limiters))
+	a.mux.HandleFunc("GET /usage", a.serveSection(a.usage))
+	a.mux.HandleFunc("GET /breaker", a.serveSection(a.breaker))
+	a.mux.HandleFunc("GET /outbox", a.serveOutbox)
+	a.mux.HandleFunc("GET /sections/{name}", a.serveNamed)
+	a.mux.HandleFunc("GET /toggles", a.serveSection(a.toggleValues))
+	a.mux.HandleFunc("POST /toggles/{name}", a.serveSetToggle)
+	return a
+}
+
+// Expose publishes the result of fn under /sections/{name}.
+func (a *Admin) Expose(name string, fn func(ctx context.Context) (interface{}, error)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sections[name] = fn
+}
+
+// Toggle registers a boolean switch operators can flip at runtime.
+func (a *Admin) Toggle(name string, get func() bool, set func(bool)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.toggles[name] = adminToggle{get: get, set: set}
+}
+
+func (a *Admin) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	header, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if a.Token == "" || !ok || subtle.ConstantTimeCompare([]byte(header), []byte(a.Token)) != 1 {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	a.mux.ServeHTTP(rw, r)
+}
+
+func (a *Admin) config(ctx context.Context) (interface{}, error) {
+	if a.Client == nil {
+		return nil, nil
+	}
+	w := a.Client
+	return map[string]interface{}{
+		"api_url":           w.apiURL,
+		"api_version":       w.APIVersion(),
+		"access_token":      redactToken(w.accessToken),
+		"validation":        !w.skipValidation,
+		"message_splitting": w.splitLongText,
+		"limiters":          len(w.limiters),
+		"dry_run":           w.dryRun,
+		"retry":             w.retry,
+		"transport":         w.transport,
+	}, nil
+}
+
+func (a *Admin) usage(ctx context.Context) (interface{}, error) {
+	if a.Client == nil {
+		return nil, nil
+	}
+	return a.Client.Usage(), nil
+}
+
+// breaker reports the client's Backpressure, which trips open when the API
+// throttles and holds every send until the pause it asked for is over.
+func (a *Admin) breaker(ctx context.Context) (interface{}, error) {
+	var p *Backpressure
+	if a.Outbox != nil {
+		p = pressureOf(a.Outbox.Pressure, a.Outbox.Client)
+	}
+	if p == nil && a.Client != nil {
+		p = a.Client.Backpressure()
+	}
+	if p == nil {
+		return nil, nil
+	}
+	state := map[string]interface{}{"state": "closed"}
+	if until, ok := p.Throttled(); ok {
+		state["state"] = "open"
+		state["until"] = until
+	}
+	return state, nil
+}
+
+func (a *Admin) outbox(ctx context.Context) (interface{}, error) {
+	pending, err := outboxLen(ctx, a.Outbox.Store)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: %w", err)
+	}
+	state := map[string]interface{}{"pending": pending}
+	if a.Outbox.DeadLetters != nil {
+		dead, err := outboxLen(ctx, a.Outbox.DeadLetters)
+		if err != nil {
+			return nil, fmt.Errorf("dead letters: %w", err)
+		}
+		state["dead_letters"] = dead
+	}
+	return state, nil
+}
+
+// outboxLen counts the entries in s, listing them all if it has no Len.
+func outboxLen(ctx context.Context, s OutboxStore) (int, error) {
+	if l, ok := s.(interface{ Len() int }); ok {
+		return l.Len(), nil
+	}
+	entries, err := s.Due(ctx, time.Unix(1<<62, 0), 0)
+	return len(entries), err
+}
+
+func (a *Admin) limiters(ctx context.Context) (interface{}, error) {
+	if a.Client == nil {
+		return nil, nil
+	}
+	type limiterState struct {
+		Type      string `json:"type"`
+		Unique    *int   `json:"unique,omitempty"`
+		Limit     *int   `json:"limit,omitempty"`
+		Remaining *int   `json:"remaining,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+	states := make([]limiterState, 0, len(a.Client.limiters))
+	for _, l := range a.Client.limiters {
+		state := limiterState{Type: fmt.Sprintf("%T", l)}
+		if s, ok := l.(interface {
+			Stats(ctx context.Context) (BudgetStats, error)
+		}); ok {
+			stats, err := s.Stats(ctx)
+			if err != nil {
+				state.Error = err.Error()
+			} else {
+				state.Unique, state.Limit, state.Remaining = &stats.Unique, &stats.Limit, &stats.Remaining
+			}
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (a *Admin) toggleValues(ctx context.Context) (interface{}, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	values := make(map[string]bool, len(a.toggles))
+	for name, t := range a.toggles {
+		values[name] = t.get()
+	}
+	return values, nil
+}
+
+func (a *Admin) dispatcher(ctx context.Context) (interface{}, error) {
+	state := map[string]interface{}{
+		"message_handlers": len(a.Dispatcher.messageHandlers),
+		"status_handlers":  len(a.Dispatcher.statusHandlers),
+		"signature_check":  a.Dispatcher.AppSecret != "",
+		"replay_guard":     a.Dispatcher.Replay != nil,
+	}
+	if a.Coverage != nil {
+		var routes []string
+		for _, item := range a.Coverage.Report().Routes {
+			routes = append(routes, item.Name)
+		}
+		state["routes"] = routes
+	}
+	return state, nil
+}
+
+func (a *Admin) serveAll(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	all := make(map[string]interface{})
+	add := func(name string, fn func(ctx context.Context) (interface{}, error)) {
+		v, err := fn(ctx)
+		if err != nil {
+			v = map[string]string{"error": err.Error()}
+		}
+		all[name] = v
+	}
+	add("config", a.config)
+	add("limiters", a.limiters)
+	add("usage", a.usage)
+	add("breaker", a.breaker)
+	add("toggles", a.toggleValues)
+	if a.Outbox != nil {
+		add("outbox", a.outbox)
+	}
+	if a.Dispatcher != nil {
+		add("dispatcher", a.dispatcher)
+	}
+
+	a.mu.Lock()
+	names := make([]string, 0, len(a.sections))
+	sections := make(map[string]func(ctx context.Context) (interface{}, error), len(a.sections))
+	for name, fn := range a.sections {
+		names = append(names, name)
+		sections[name] = fn
+	}
+	a.mu.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, sections[name])
+	}
+	writeAdminJSON(rw, http.StatusOK, all)
+}
+
+func (a *Admin) serveNamed(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	a.mu.Lock()
+	fn, ok := a.sections[name]
+	a.mu.Unlock()
+	if name == "dispatcher" && a.Dispatcher != nil {
+		fn, ok = a.dispatcher, true
+	}
+	if !ok {
+		http.NotFound(rw, r)
+		return
+	}
+	a.serveSection(fn)(rw, r)
+}
+
+func (a *Admin) serveOutbox(rw http.ResponseWriter, r *http.Request) {
+	if a.Outbox == nil {
+		http.NotFound(rw, r)
+		return
+	}
+	a.serveSection(a.outbox)(rw, r)
+}
+
+func (a *Admin) serveSection(fn func(ctx context.Context) (interface{}, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		v, err := fn(r.Context())
+		if err != nil {
+			writeAdminJSON(rw, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(rw, http.StatusOK, v)
+	}
+}
+
+func (a *Admin) serveSetToggle(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	a.mu.Lock()
+	t, ok := a.toggles[name]
+	a.mu.Unlock()
+	if !ok {
+		http.NotFound(rw, r)
+		return
+	}
+
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if v := r.URL.Query().Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(rw, "enabled must be a boolean", http.StatusBadRequest)
+			return
+		}
+		body.Enabled = &enabled
+	} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+		http.Error(rw, `body must be {"enabled": true|false}`, http.StatusBadRequest)
+		return
+	}
+	t.set(*body.Enabled)
+	writeAdminJSON(rw, http.StatusOK, map[string]bool{name: t.get()})
+}
+
+func writeAdminJSON(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	enc := json.NewEncoder(rw)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// redactToken keeps just enough of a token to tell which one is in use.
+func redactToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + "..." + token[len(token)-4:]
+}
@@ -0,0 +1,74 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminOutboxAndBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := NewWhatsappClient(context.Background(), "http://127.0.0.1:0/v21.0/1/messages", "token", nil).(*WhatsappClient)
+	client.pressure.now = func() time.Time { return now }
+	client.pressure.Observe(&LimitError{RetryAt: now.Add(time.Minute)})
+
+	o := NewOutbox(&scriptedSender{errs: []error{&APIError{StatusCode: http.StatusBadRequest, Code: ErrCodeReengagementRequired}}}, nil)
+	o.Pressure = client.Backpressure()
+	o.DeadLetters = NewMemoryOutboxStore()
+	o.now = func() time.Time { return now }
+	o.Store.Put(context.Background(), OutboxEntry{ID: "1", NextAttempt: now.Add(time.Hour)})
+	if err := o.attempt(context.Background(), OutboxEntry{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAdmin("secret", client)
+	a.Outbox = o
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/outbox", `{"dead_letters":1,"pending":1}`},
+		{"/breaker", `{"state":"open","until":"2024-01-01T12:01:00Z"}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, req)
+		var got bytes.Buffer
+		json.Compact(&got, rw.Body.Bytes())
+		if rw.Code != http.StatusOK || got.String() != tt.want {
+			t.Errorf("GET %s = %d %s, want %s", tt.path, rw.Code, got.String(), tt.want)
+		}
+	}
+}
+
+func TestAdminDebugToggleSetsLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	defer SetLogger(nil)
+	defer SetDebugLogging(false)
+
+	a := NewAdmin("secret", nil)
+	for _, enabled := range []bool{true, false} {
+		buf.Reset()
+		body, _ := json.Marshal(map[string]bool{"enabled": enabled})
+		req := httptest.NewRequest("POST", "/toggles/debug", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, req)
+		if rw.Code != http.StatusOK {
+			t.Fatalf("POST /toggles/debug = %d", rw.Code)
+		}
+		logger().Debug("probe")
+		if got := strings.Contains(buf.String(), "probe"); got != enabled {
+			t.Errorf("debug %v: debug record logged = %v", enabled, got)
+		}
+	}
+}
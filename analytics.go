@@ -0,0 +1,308 @@
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type AnalyticsGranularity string
+
+const (
+	GranularityHalfHour AnalyticsGranularity = "HALF_HOUR"
+	GranularityDaily    AnalyticsGranularity = "DAILY"
+	GranularityMonthly  AnalyticsGranularity = "MONTHLY"
+)
+
+type ConversationDimension string
+
+const (
+	DimensionConversationCategory  ConversationDimension = "CONVERSATION_CATEGORY"
+	DimensionConversationType      ConversationDimension = "CONVERSATION_TYPE"
+	DimensionConversationDirection ConversationDimension = "CONVERSATION_DIRECTION"
+	DimensionCountry               ConversationDimension = "COUNTRY"
+	DimensionPhone                 ConversationDimension = "PHONE"
+)
+
+// ConversationAnalyticsQuery selects the range and segmentation of
+// ConversationAnalytics. Empty filters match everything; Granularity
+// defaults to daily.
+type ConversationAnalyticsQuery struct {
+	Start       time.Time
+	End         time.Time
+	Granularity AnalyticsGranularity
+
+	PhoneNumbers      []string
+	Countries         []string
+	ConversationTypes []string
+	Dimensions        []ConversationDimension
+}
+
+// ConversationDataPoint is the conversation count of one time bucket. The
+// segment fields are set only for the dimensions that were requested.
+type ConversationDataPoint struct {
+	Start                 time.Time
+	End                   time.Time
+	Conversations         int
+	PhoneNumber           string
+	Country               string
+	ConversationType      string
+	ConversationDirection string
+	ConversationCategory  string
+	Cost                  float64
+}
+
+func (dp *ConversationDataPoint) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Start                 int64   `json:"start"`
+		End                   int64   `json:"end"`
+		Conversation          int     `json:"conversation"`
+		PhoneNumber           string  `json:"phone_number"`
+		Country               string  `json:"country"`
+		ConversationType      string  `json:"conversation_type"`
+		ConversationDirection string  `json:"conversation_direction"`
+		ConversationCategory  string  `json:"conversation_category"`
+		Cost                  float64 `json:"cost"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*dp = ConversationDataPoint{
+		Start:                 time.Unix(raw.Start, 0),
+		End:                   time.Unix(raw.End, 0),
+		Conversations:         raw.Conversation,
+		PhoneNumber:           raw.PhoneNumber,
+		Country:               raw.Country,
+		ConversationType:      raw.ConversationType,
+		ConversationDirection: raw.ConversationDirection,
+		ConversationCategory:  raw.ConversationCategory,
+		Cost:                  raw.Cost,
+	}
+	return nil
+}
+
+type ConversationAnalytics struct {
+	DataPoints []ConversationDataPoint
+}
+
+func (a ConversationAnalytics) Total() int {
+	n := 0
+	for _, dp := range a.DataPoints {
+		n += dp.Conversations
+	}
+	return n
+}
+
+func (a ConversationAnalytics) ByType() map[string]int {
+	return a.sum(func(dp ConversationDataPoint) string { return dp.ConversationType })
+}
+
+func (a ConversationAnalytics) ByCountry() map[string]int {
+	return a.sum(func(dp ConversationDataPoint) string { return dp.Country })
+}
+
+func (a ConversationAnalytics) ByPhoneNumber() map[string]int {
+	return a.sum(func(dp ConversationDataPoint) string { return dp.PhoneNumber })
+}
+
+func (a ConversationAnalytics) sum(key func(ConversationDataPoint) string) map[string]int {
+	totals := make(map[string]int)
+	for _, dp := range a.DataPoints {
+		totals[key(dp)] += dp.Conversations
+	}
+	return totals
+}
+
+// ConversationAnalytics fetches conversation counts of the business account
+// wabaID.
+func (w *WhatsappClient) ConversationAnalytics(ctx context.Context, wabaID string, q ConversationAnalyticsQuery) (*ConversationAnalytics, error) {
+	if q.Start.IsZero() || q.End.IsZero() || !q.End.After(q.Start) {
+		return nil, errors.New("conversation analytics: need a start before end")
+	}
+	granularity := q.Granularity
+	if granularity == "" {
+		granularity = GranularityDaily
+	}
+
+	field := fmt.Sprintf("conversation_analytics.start(%d).end(%d).granularity(%s)", q.Start.Unix(), q.End.Unix(), granularity)
+	field += analyticsFilter("phone_numbers", q.PhoneNumbers)
+	field += analyticsFilter("country_codes", q.Countries)
+	field += analyticsFilter("conversation_types", q.ConversationTypes)
+	dims := make([]string, len(q.Dimensions))
+	for i, d := range q.Dimensions {
+		dims[i] = string(d)
+	}
+	field += analyticsFilter("dimensions", dims)
+
+	var response struct {
+		ConversationAnalytics struct {
+			Data []struct {
+				DataPoints []ConversationDataPoint `json:"data_points"`
+			} `json:"data"`
+		} `json:"conversation_analytics"`
+	}
+	endpoint := w.graphURL() + "/" + wabaID + "?" + url.Values{"fields": {field}}.Encode()
+	if err := w.callGraph(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	result := &ConversationAnalytics{}
+	for _, d := range response.ConversationAnalytics.Data {
+		result.DataPoints = append(result.DataPoints, d.DataPoints...)
+	}
+	return result, nil
+}
+
+// analyticsFilter renders a list argument of an analytics field expansion,
+// e.g. .country_codes(["US","KZ"]).
+func analyticsFilter(name string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "." + name + "([" + strings.Join(quoted, ",") + "])"
+}
+
+type PricingDimension string
+
+const (
+	DimensionPricingCategory PricingDimension = "PRICING_CATEGORY"
+	DimensionPricingType     PricingDimension = "PRICING_TYPE"
+	DimensionPricingCountry  PricingDimension = "COUNTRY"
+	DimensionPricingPhone    PricingDimension = "PHONE"
+)
+
+// PricingAnalyticsQuery selects the range and segmentation of
+// PricingAnalytics. Empty filters match everything; Granularity defaults to
+// daily.
+type PricingAnalyticsQuery struct {
+	Start       time.Time
+	End         time.Time
+	Granularity AnalyticsGranularity
+
+	PhoneNumbers      []string
+	Countries         []string
+	PricingTypes      []string
+	PricingCategories []string
+	Dimensions        []PricingDimension
+}
+
+// PricingDataPoint is the billed volume and cost of one time bucket. Cost is
+// in the currency of the business account.
+type PricingDataPoint struct {
+	Start           time.Time
+	End             time.Time
+	Volume          int
+	Cost            float64
+	PhoneNumber     string
+	Country         string
+	PricingType     string
+	PricingCategory string
+}
+
+func (dp *PricingDataPoint) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Start           int64   `json:"start"`
+		End             int64   `json:"end"`
+		Volume          int     `json:"volume"`
+		Cost            float64 `json:"cost"`
+		PhoneNumber     string  `json:"phone_number"`
+		Country         string  `json:"country"`
+		PricingType     string  `json:"pricing_type"`
+		PricingCategory string  `json:"pricing_category"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*dp = PricingDataPoint{
+		Start:           time.Unix(raw.Start, 0),
+		End:             time.Unix(raw.End, 0),
+		Volume:          raw.Volume,
+		Cost:            raw.Cost,
+		PhoneNumber:     raw.PhoneNumber,
+		Country:         raw.Country,
+		PricingType:     raw.PricingType,
+		PricingCategory: raw.PricingCategory,
+	}
+	return nil
+}
+
+type PricingAnalytics struct {
+	DataPoints []PricingDataPoint
+}
+
+func (a PricingAnalytics) TotalCost() float64 {
+	var total float64
+	for _, dp := range a.DataPoints {
+		total += dp.Cost
+	}
+	return total
+}
+
+func (a PricingAnalytics) CostByCategory() map[string]float64 {
+	return a.cost(func(dp PricingDataPoint) string { return dp.PricingCategory })
+}
+
+func (a PricingAnalytics) CostByCountry() map[string]float64 {
+	return a.cost(func(dp PricingDataPoint) string { return dp.Country })
+}
+
+func (a PricingAnalytics) CostByPhoneNumber() map[string]float64 {
+	return a.cost(func(dp PricingDataPoint) string { return dp.PhoneNumber })
+}
+
+func (a PricingAnalytics) cost(key func(PricingDataPoint) string) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, dp := range a.DataPoints {
+		totals[key(dp)] += dp.Cost
+	}
+	return totals
+}
+
+// PricingAnalytics fetches billed message volume and cost of the business
+// account wabaID.
+func (w *WhatsappClient) PricingAnalytics(ctx context.Context, wabaID string, q PricingAnalyticsQuery) (*PricingAnalytics, error) {
+	if q.Start.IsZero() || q.End.IsZero() || !q.End.After(q.Start) {
+		return nil, errors.New("pricing analytics: need a start before end")
+	}
+	granularity := q.Granularity
+	if granularity == "" {
+		granularity = GranularityDaily
+	}
+
+	field := fmt.Sprintf("pricing_analytics.start(%d).end(%d).granularity(%s)", q.Start.Unix(), q.End.Unix(), granularity)
+	field += analyticsFilter("phone_numbers", q.PhoneNumbers)
+	field += analyticsFilter("country_codes", q.Countries)
+	field += analyticsFilter("pricing_types", q.PricingTypes)
+	field += analyticsFilter("pricing_categories", q.PricingCategories)
+	dims := make([]string, len(q.Dimensions))
+	for i, d := range q.Dimensions {
+		dims[i] = string(d)
+	}
+	field += analyticsFilter("dimensions", dims)
+
+	var response struct {
+		PricingAnalytics struct {
+			Data []struct {
+				DataPoints []PricingDataPoint `json:"data_points"`
+			} `json:"data"`
+		} `json:"pricing_analytics"`
+	}
+	endpoint := w.graphURL() + "/" + wabaID + "?" + url.Values{"fields": {field}}.Encode()
+	if err := w.callGraph(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	result := &PricingAnalytics{}
+	for _, d := range response.PricingAnalytics.Data {
+		result.DataPoints = append(result.DataPoints, d.DataPoints...)
+	}
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrAsyncClosed = errors.New("whatsappdau: async sender closed")
+
+// Future is the pending result of a SendAsync call.
+type Future struct {
+	Recipient string
+	done      chan struct{}
+	resp      *MessageResponse
+	err       error
+}
+
+// Done is closed once the send has completed.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the send completes or ctx is done. Giving up on Wait
+// does not cancel the send.
+func (f *Future) Wait(ctx context.Context) (*MessageResponse, error) {
+	select {
+	case <-f.done:
+		return f.resp, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *Future) complete(resp *MessageResponse, err error) {
+	f.resp, f.err = resp, err
+	close(f.done)
+}
+
+type asyncJob struct {
+	ctx      context.Context
+	msg      BulkMessage
+	future   *Future
+	callback func(BulkResult)
+}
+
+// AsyncSender queues sends for a fixed pool of workers so producers don't
+// wait on HTTP round trips:
+//
+//	async := whatsappdau.NewAsyncSender(client, 8, 1000)
+//	defer async.Close()
+//	f := async.SendMessageAsync(ctx, "15551234567", "hello")
+//	...
+//	resp, err := f.Wait(ctx)
+type AsyncSender struct {
+	Client Whatsapp
+	// OnResult, if set, is called as each send completes, from the worker
+	// goroutines.
+	OnResult func(BulkResult)
+	// Outcomes, if set, records every send.
+	Outcomes *OutcomeLog
+
+	jobs   chan asyncJob
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncSender starts workers goroutines sending from a queue holding up
+// to queue requests.
+func NewAsyncSender(client Whatsapp, workers, queue int) *AsyncSender {
+	if workers < 1 {
+		workers = 1
+	}
+	a := &AsyncSender{Client: client, jobs: make(chan asyncJob, queue)}
+	for i := 0; i < workers; i++ {
+		a.wg.Add(1)
+		go a.work()
+	}
+	return a
+}
+
+// SendAsync queues msg for recipient and returns at once, unless the queue
+// is full, in which case it waits for room until ctx is done. ctx is also
+// the context of the send itself. callback, if not nil, is called with the
+// result from a worker goroutine before the future completes.
+func (a *AsyncSender) SendAsync(ctx context.Context, recipient string, msg BulkMessage, callback func(BulkResult)) *Future {
+	f := &Future{Recipient: recipient, done: make(chan struct{})}
+	job := asyncJob{ctx: ctx, msg: msg, future: f, callback: callback}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		a.finish(job, nil, ErrAsyncClosed)
+		return f
+	}
+	select {
+	case a.jobs <- job:
+	case <-ctx.Done():
+		a.finish(job, nil, ctx.Err())
+	}
+	return f
+}
+
+func (a *AsyncSender) SendMessageAsync(ctx context.Context, to, body string, opts ...SendOption) *Future {
+	return a.SendAsync(ctx, to, func(ctx context.Context, s Whatsapp, recipient string) (*MessageResponse, error) {
+		return s.SendMessage(recipient, body, opts...)
+	}, nil)
+}
+
+func (a *AsyncSender) SendTemplateAsync(ctx context.Context, to string, msg TemplateMessage, opts ...SendOption) *Future {
+	return a.SendAsync(ctx, to, func(ctx context.Context, s Whatsapp, recipient string) (*MessageResponse, error) {
+		return s.SendTemplate(ctx, recipient, msg, opts...)
+	}, nil)
+}
+
+// Close stops accepting sends and waits for the queued ones to finish.
+// Later SendAsync calls fail with ErrAsyncClosed.
+func (a *AsyncSender) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.jobs)
+	}
+	a.mu.Unlock()
+	a.wg.Wait()
+}
+
+// Start does nothing: the workers run from NewAsyncSender on. It makes
+// AsyncSender a Service.
+func (a *AsyncSender) Start(ctx context.Context) error {
+	return nil
+}
+
+// Shutdown is Close, giving up waiting once ctx is done.
+func (a *AsyncSender) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *AsyncSender) work() {
+	defer a.wg.Done()
+	for job := range a.jobs {
+		if err := job.ctx.Err(); err != nil {
+			a.finish(job, nil, err)
+			continue
+		}
+		resp, err := job.msg(job.ctx, a.Client, job.future.Recipient)
+		a.finish(job, resp, err)
+	}
+}
+
+func (a *AsyncSender) finish(job asyncJob, resp *MessageResponse, err error) {
+	res := BulkResult{Recipient: job.future.Recipient, Response: resp, Err: err}
+	if a.Outcomes != nil {
+		a.Outcomes.RecordSend(res.Recipient, resp, err)
+	}
+	if a.OnResult != nil {
+		a.OnResult(res)
+	}
+	if job.callback != nil {
+		job.callback(res)
+	}
+	job.future.complete(resp, err)
+}
@@ -0,0 +1,91 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// voiceNoteSniffLen covers an OGG page header with a full segment table
+// plus the start of the first packet.
+const voiceNoteSniffLen = 27 + 255 + 8
+
+// ValidateVoiceNote reads the start of r and returns a *ValidationError
+// unless it is Opus audio in an OGG container. WhatsApp shows anything else
+// sent as audio/ogg as a generic attachment instead of a voice note.
+func ValidateVoiceNote(r io.Reader) error {
+	header := make([]byte, voiceNoteSniffLen)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read audio: %w", err)
+	}
+	return checkVoiceNote(header[:n])
+}
+
+func checkVoiceNote(header []byte) error {
+	if !bytes.HasPrefix(header, []byte("OggS")) {
+		return &ValidationError{Field: "audio", Reason: fmt.Sprintf("voice notes must be OGG/Opus, got %s", audioContainer(header))}
+	}
+	if len(header) < 27 {
+		return &ValidationError{Field: "audio", Reason: "truncated OGG page header"}
+	}
+	payload := 27 + int(header[26])
+	if len(header) < payload+8 {
+		return &ValidationError{Field: "audio", Reason: "truncated OGG page header"}
+	}
+	switch packet := header[payload:]; {
+	case bytes.HasPrefix(packet, []byte("OpusHead")):
+		return nil
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		return &ValidationError{Field: "audio", Reason: "voice notes must use the Opus codec, got OGG/Vorbis"}
+	default:
+		return &ValidationError{Field: "audio", Reason: "voice notes must use the Opus codec, got an unknown OGG stream"}
+	}
+}
+
+// audioContainer names the format header starts with, for error messages.
+func audioContainer(header []byte) string {
+	switch {
+	case len(header) == 0:
+		return "an empty file"
+	case bytes.HasPrefix(header, []byte("ID3")), len(header) > 1 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		return "MP3"
+	case bytes.HasPrefix(header, []byte("RIFF")) && len(header) >= 12 && string(header[8:12]) == "WAVE":
+		return "WAV"
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return "MP4/M4A"
+	case bytes.HasPrefix(header, []byte("#!AMR")):
+		return "AMR"
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return "FLAC"
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "WebM/Matroska"
+	default:
+		return "an unrecognized format"
+	}
+}
+
+// checkVoiceNoteReader validates the start of r and returns a reader
+// yielding all of r again. Seekable readers are rewound so their size stays
+// known to UploadMedia.
+func (w *WhatsappClient) checkVoiceNoteReader(r io.Reader) (io.Reader, error) {
+	if w.skipValidation {
+		return r, nil
+	}
+	header := make([]byte, voiceNoteSniffLen)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	header = header[:n]
+	if err := checkVoiceNote(header); err != nil {
+		return nil, err
+	}
+	if s, ok := r.(io.Seeker); ok {
+		if _, err := s.Seek(int64(-n), io.SeekCurrent); err == nil {
+			return r, nil
+		}
+	}
+	return io.MultiReader(bytes.NewReader(header), r), nil
+}
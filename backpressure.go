@@ -0,0 +1,139 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrBusy is returned by Outbox.Enqueue while sending is throttled or the
+// queue is full.
+var ErrBusy = errors.New("whatsappdau: busy, try again later")
+
+// Backpressure tracks whether sends are currently being throttled, by a
+// Limiter or by the API, so producers can slow down instead of piling up
+// work. Every WhatsappClient keeps one, see (*WhatsappClient).Backpressure;
+// BulkSender and Outbox use it automatically.
+type Backpressure struct {
+	// Pause is how long to hold off after throttling that does not say when
+	// to retry. Default 1s.
+	Pause time.Duration
+
+	mu    sync.Mutex
+	until time.Time
+	now   func() time.Time
+}
+
+func NewBackpressure() *Backpressure {
+	return &Backpressure{Pause: time.Second, now: time.Now}
+}
+
+// Observe records err if it is throttling: a *LimitError holds off until
+// its RetryAt, an API error matching ErrRateLimited for Pause. A LimitError
+// for NewRecipients is not recorded, since replies to recipients already
+// messaged are still allowed; the limiter refuses the new ones itself.
+func (b *Backpressure) Observe(err error) {
+	var limitErr *LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		if !limitErr.NewRecipients {
+			b.holdUntil(limitErr.RetryAt)
+		}
+	case errors.Is(err, ErrRateLimited):
+		b.holdUntil(b.clock().Add(b.pause()))
+	}
+}
+
+// Throttled returns when sending may resume, if that is still ahead.
+func (b *Backpressure) Throttled() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.until, b.until.After(b.clock())
+}
+
+// Wait blocks while sends are throttled or until ctx is done.
+func (b *Backpressure) Wait(ctx context.Context) error {
+	for {
+		until, ok := b.Throttled()
+		if !ok {
+			return nil
+		}
+		t := time.NewTimer(until.Sub(b.clock()))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Backpressure) pause() time.Duration {
+	if b.Pause <= 0 {
+		return time.Second
+	}
+	return b.Pause
+}
+
+func (b *Backpressure) clock() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+	return b.now()
+}
+
+func (b *Backpressure) holdUntil(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t.After(b.until) {
+		b.until = t
+	}
+}
+
+// Backpressure reports throttling this client has run into.
+func (w *WhatsappClient) Backpressure() *Backpressure {
+	return w.pressure
+}
+
+// pressureOf returns p, or else the Backpressure of client if it has one.
+func pressureOf(p *Backpressure, client interface{}) *Backpressure {
+	if p != nil {
+		return p
+	}
+	if c, ok := client.(interface{ Backpressure() *Backpressure }); ok {
+		return c.Backpressure()
+	}
+	return nil
+}
+
+// pressureDoer feeds throttled API responses into a Backpressure, honoring
+// Retry-After.
+type pressureDoer struct {
+	next     HTTPDoer
+	pressure *Backpressure
+}
+
+func (d *pressureDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.next.Do(req)
+	if err != nil || resp.StatusCode < 300 {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		pause := d.pressure.pause()
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			pause = time.Duration(secs) * time.Second
+		}
+		d.pressure.holdUntil(d.pressure.clock().Add(pause))
+		return resp, nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	d.pressure.Observe(newAPIError(resp.StatusCode, body))
+	return resp, nil
+}
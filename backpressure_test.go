@@ -0,0 +1,72 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackpressureObserve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+		until     time.Time
+	}{
+		{"nil", nil, false, time.Time{}},
+		{"other error", fmt.Errorf("boom"), false, time.Time{}},
+		{"limit", &LimitError{RetryAt: now.Add(time.Minute)}, true, now.Add(time.Minute)},
+		{"wrapped limit", fmt.Errorf("send: %w", &LimitError{RetryAt: now.Add(time.Minute)}), true, now.Add(time.Minute)},
+		{"new recipient limit", &LimitError{RetryAt: now.Add(time.Hour), NewRecipients: true}, false, time.Time{}},
+		{"rate limited", &APIError{StatusCode: 400, Code: ErrCodeThroughputExceeded}, true, now.Add(time.Second)},
+		{"rejected", &APIError{StatusCode: 400, Code: ErrCodeReengagementRequired}, false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBackpressure()
+			b.now = func() time.Time { return now }
+			b.Observe(tt.err)
+			until, throttled := b.Throttled()
+			if throttled != tt.throttled || !until.Equal(tt.until) {
+				t.Errorf("Throttled() = %v, %v; want %v, %v", until, throttled, tt.until, tt.throttled)
+			}
+		})
+	}
+}
+
+func TestOutboxKeepsRepliesFlowingPastTierLimit(t *testing.T) {
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"messages":[{"id":"wamid.1"}]}`)
+	}))
+	defer srv.Close()
+	policy := RampPolicy{Start: time.Now(), Day1Limit: 1, Growth: 1}
+	client := NewWhatsappClient(context.Background(), srv.URL+"/v21.0/1/messages", "token", nil, WithLimiter(NewRampLimiter(policy)))
+
+	o := NewOutbox(client, nil)
+	o.OnSent = func(e OutboxEntry, _ *MessageResponse) { sent = append(sent, e.Recipient) }
+	ctx := context.Background()
+	for _, to := range []string{"15550000001", "15550000002", "15550000001"} {
+		if _, err := o.EnqueueText(ctx, to, "hi"); err != nil {
+			t.Fatal(err)
+		}
+		// Keep CreatedAt distinct so entries are sent in order.
+		time.Sleep(time.Millisecond)
+	}
+	if err := o.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[0] != "15550000001" || sent[1] != "15550000001" {
+		t.Errorf("sent %v, want both messages to the recipient already in the window", sent)
+	}
+	if _, throttled := client.(*WhatsappClient).Backpressure().Throttled(); throttled {
+		t.Error("a new-recipient limit throttled every send")
+	}
+	if n := o.Store.(*MemoryOutboxStore).Len(); n != 1 {
+		t.Errorf("%d entries queued, want the new recipient deferred", n)
+	}
+}
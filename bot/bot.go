@@ -0,0 +1,337 @@
+// Package bot runs conversations as state machines on top of a
+// whatsappdau.Dispatcher. Each user is in one State at a time; their input
+// (text, a reply button or a list row) selects a Transition to the next
+// state, whose prompt is sent on entry.
+//
+//	b := bot.New(client, "menu")
+//	b.Add(bot.State{
+//		Name:   "menu",
+//		Prompt: bot.Buttons("How can we help?", whatsappdau.ButtonItem{ID: "order", Text: "Order"}),
+//		Transitions: []bot.Transition{
+//			{On: bot.ButtonID("order"), To: "order"},
+//		},
+//	})
+//	b.Register(dispatcher)
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+// Sender is the part of the client a bot sends prompts and replies with.
+type Sender interface {
+	whatsappdau.MessageSender
+	whatsappdau.InteractiveSender
+}
+
+// Trigger matches user input against a transition.
+type Trigger func(in Input) bool
+
+// Input is what the user sent, reduced to what triggers look at.
+type Input struct {
+	Text string
+	// ReplyID is the ID of the tapped button or selected list row.
+	ReplyID string
+	Kind    string
+}
+
+func inputOf(msg *whatsappdau.InboundMessage) Input {
+	in := Input{Kind: msg.Type}
+	if msg.Text != nil {
+		in.Text = msg.Text.Body
+	}
+	if r, ok := msg.Reply(); ok {
+		in.ReplyID = r.ID
+		in.Text = r.Title
+		in.Kind = r.Kind
+	}
+	return in
+}
+
+// Text matches a text message equal to one of words, ignoring case and
+// surrounding space.
+func Text(words ...string) Trigger {
+	return func(in Input) bool {
+		if in.Kind != "text" {
+			return false
+		}
+		for _, w := range words {
+			if strings.EqualFold(strings.TrimSpace(in.Text), w) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ButtonID matches a tap on a reply or quick-reply button with one of ids.
+func ButtonID(ids ...string) Trigger {
+	return replyTrigger([]string{whatsappdau.ReplyKindButton, whatsappdau.ReplyKindQuickReply}, ids)
+}
+
+// ListID matches the selection of a list row with one of ids.
+func ListID(ids ...string) Trigger {
+	return replyTrigger([]string{whatsappdau.ReplyKindList}, ids)
+}
+
+func replyTrigger(kinds, ids []string) Trigger {
+	return func(in Input) bool {
+		kindOK := false
+		for _, k := range kinds {
+			kindOK = kindOK || in.Kind == k
+		}
+		if !kindOK {
+			return false
+		}
+		for _, id := range ids {
+			if in.ReplyID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Any matches every input.
+func Any() Trigger {
+	return func(Input) bool { return true }
+}
+
+// Transition moves to state To when On matches. Do, if set, runs first and
+// may return a different state name, or "" to keep To.
+type Transition struct {
+	On Trigger
+	To string
+	Do Step
+}
+
+// Step handles a message in a state. It returns the state to move to, or ""
+// to stay.
+type Step func(ctx context.Context, c *Context) (string, error)
+
+// Prompt is sent when a user enters a state. Exactly one of the forms is
+// used: a list when ListItems is set, buttons when Buttons is set, else text.
+type Prompt struct {
+	Text       string
+	Buttons    []whatsappdau.ButtonItem
+	ListButton string
+	ListItems  []whatsappdau.ListItem
+}
+
+func Say(text string) *Prompt {
+	return &Prompt{Text: text}
+}
+
+func Buttons(text string, buttons ...whatsappdau.ButtonItem) *Prompt {
+	return &Prompt{Text: text, Buttons: buttons}
+}
+
+func List(text, button string, items ...whatsappdau.ListItem) *Prompt {
+	return &Prompt{Text: text, ListButton: button, ListItems: items}
+}
+
+type State struct {
+	Name   string
+	Prompt *Prompt
+	// Transitions are tried in order; the first match wins.
+	Transitions []Transition
+	// Fallback handles input no transition matches. Without one the prompt
+	// is sent again.
+	Fallback Step
+}
+
+// Context is passed to steps.
+type Context struct {
+	Msg     *whatsappdau.InboundMessage
+	Input   Input
+	Session *Session
+	Sender  Sender
+}
+
+// Reply sends text to the user.
+func (c *Context) Reply(text string) error {
+	_, err := c.Sender.SendMessage(c.Msg.From, text)
+	return err
+}
+
+type Bot struct {
+	Sender Sender
+	Store  Store
+	// Start is the state of users without a session.
+	Start string
+
+	states map[string]*State
+
+	mu    sync.Mutex
+	users map[string]*userLock
+}
+
+// userLock serializes the messages of one user, so concurrent deliveries
+// don't both load a session and overwrite each other's update.
+type userLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func New(sender Sender, start string) *Bot {
+	return &Bot{Sender: sender, Store: NewMemoryStore(), Start: start, states: make(map[string]*State)}
+}
+
+// Add registers s, replacing a state of the same name.
+func (b *Bot) Add(states ...State) *Bot {
+	for i := range states {
+		s := states[i]
+		b.states[s.Name] = &s
+	}
+	return b
+}
+
+// Register makes the bot handle every message d dispatches.
+func (b *Bot) Register(d *whatsappdau.Dispatcher) {
+	d.OnMessage(b.Handle)
+}
+
+// Handle advances the sender's conversation by one message. Messages from
+// the same user are handled one at a time; with several processes sharing a
+// Store, route each user's webhooks to one of them.
+func (b *Bot) Handle(ctx context.Context, msg *whatsappdau.InboundMessage) error {
+	unlock := b.lock(msg.From)
+	defer unlock()
+
+	session, err := b.Store.Load(ctx, msg.From)
+	if err != nil {
+		return fmt.Errorf("bot: load session of %s: %w", msg.From, err)
+	}
+	if session == nil {
+		session = &Session{Data: make(map[string]string)}
+	}
+	c := &Context{Msg: msg, Input: inputOf(msg), Session: session, Sender: b.Sender}
+
+	state, ok := b.states[session.State]
+	if !ok {
+		if err := b.enter(ctx, c, b.Start); err != nil {
+			return err
+		}
+		return b.save(ctx, c)
+	}
+
+	next, err := b.step(ctx, c, state)
+	if err != nil {
+		return err
+	}
+	if next == "" {
+		if state.Fallback == nil && state.Prompt != nil {
+			if err := b.prompt(c, state.Prompt); err != nil {
+				return err
+			}
+		}
+	} else if err := b.enter(ctx, c, next); err != nil {
+		return err
+	}
+	return b.save(ctx, c)
+}
+
+func (b *Bot) lock(waID string) (unlock func()) {
+	b.mu.Lock()
+	if b.users == nil {
+		b.users = make(map[string]*userLock)
+	}
+	l := b.users[waID]
+	if l == nil {
+		l = &userLock{}
+		b.users[waID] = l
+	}
+	l.refs++
+	b.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		b.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(b.users, waID)
+		}
+		b.mu.Unlock()
+	}
+}
+
+func (b *Bot) step(ctx context.Context, c *Context, state *State) (string, error) {
+	for _, t := range state.Transitions {
+		if !t.On(c.Input) {
+			continue
+		}
+		next := t.To
+		if t.Do != nil {
+			to, err := t.Do(ctx, c)
+			if err != nil {
+				return "", fmt.Errorf("bot: state %s: %w", state.Name, err)
+			}
+			if to != "" {
+				next = to
+			}
+		}
+		return next, nil
+	}
+	if state.Fallback == nil {
+		return "", nil
+	}
+	next, err := state.Fallback(ctx, c)
+	if err != nil {
+		return "", fmt.Errorf("bot: state %s: %w", state.Name, err)
+	}
+	return next, nil
+}
+
+func (b *Bot) enter(ctx context.Context, c *Context, name string) error {
+	state, ok := b.states[name]
+	if !ok {
+		return fmt.Errorf("bot: unknown state %q", name)
+	}
+	c.Session.State = name
+	if state.Prompt == nil {
+		return nil
+	}
+	return b.prompt(c, state.Prompt)
+}
+
+func (b *Bot) prompt(c *Context, p *Prompt) error {
+	to := c.Msg.From
+	var err error
+	switch {
+	case len(p.ListItems) > 0:
+		_, err = b.Sender.SendInteractiveList(to, p.Text, p.ListButton, p.ListItems)
+	case len(p.Buttons) > 0:
+		_, err = b.Sender.SendInteractiveButtons(to, "button", p.Text, replyButtons(p.Buttons))
+	default:
+		_, err = b.Sender.SendMessage(to, p.Text)
+	}
+	if err != nil {
+		return fmt.Errorf("bot: send prompt: %w", err)
+	}
+	return nil
+}
+
+// replyButtons defaults the Type of buttons to "reply", which the API
+// requires, so prompts can list ButtonItem{ID, Text}.
+func replyButtons(buttons []whatsappdau.ButtonItem) []whatsappdau.ButtonItem {
+	out := make([]whatsappdau.ButtonItem, len(buttons))
+	for i, btn := range buttons {
+		if btn.Type == "" && btn.Link == "" {
+			btn.Type = "reply"
+		}
+		out[i] = btn
+	}
+	return out
+}
+
+func (b *Bot) save(ctx context.Context, c *Context) error {
+	if err := b.Store.Save(ctx, c.Msg.From, c.Session); err != nil {
+		return fmt.Errorf("bot: save session of %s: %w", c.Msg.From, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/daulet140/whatsappdau/whatsappdautest"
+)
+
+func orderBot(mock *whatsappdautest.MockClient) *Bot {
+	b := New(mock, "menu")
+	b.Add(
+		State{
+			Name:   "menu",
+			Prompt: Buttons("How can we help?", whatsappdau.ButtonItem{ID: "order", Text: "Order"}, whatsappdau.ButtonItem{ID: "help", Text: "Help"}),
+			Transitions: []Transition{
+				{On: ButtonID("order"), To: "size"},
+				{On: ButtonID("help"), To: "menu", Do: func(ctx context.Context, c *Context) (string, error) {
+					return "", c.Reply("Call us on 555-0100.")
+				}},
+			},
+		},
+		State{
+			Name:   "size",
+			Prompt: List("Pick a size", "Sizes", whatsappdau.ListItem{ID: "s", Title: "Small"}, whatsappdau.ListItem{ID: "l", Title: "Large"}),
+			Transitions: []Transition{
+				{On: ListID("s", "l"), To: "done", Do: func(ctx context.Context, c *Context) (string, error) {
+					c.Session.Data["size"] = c.Input.ReplyID
+					return "", nil
+				}},
+				{On: Text("back"), To: "menu"},
+			},
+		},
+		State{
+			Name:   "done",
+			Prompt: Say("Thanks!"),
+		},
+	)
+	return b
+}
+
+func TestBotFlow(t *testing.T) {
+	mock := whatsappdautest.NewMockClient()
+	b := orderBot(mock)
+	d := whatsappdau.NewDispatcher("", "")
+	b.Register(d)
+
+	whatsappdautest.NewScenario("order flow").
+		From("15551234567", "Ann").
+		UserSends("hi").
+		BotReplies(whatsappdautest.ButtonsWithIDs("order", "help")).
+		UserSends("what?").
+		BotReplies(whatsappdautest.ButtonsWithIDs("order", "help")).
+		UserTapsButton("help", "Help").
+		BotReplies(whatsappdautest.TextEquals("Call us on 555-0100."), whatsappdautest.ButtonsWithIDs("order", "help")).
+		UserTapsButton("order", "Order").
+		BotReplies(whatsappdautest.ListWithRows("s", "l")).
+		UserSends("Back").
+		BotReplies(whatsappdautest.ButtonsWithIDs("order", "help")).
+		UserTapsButton("order", "Order").
+		BotReplies(whatsappdautest.ListWithRows("s", "l")).
+		UserSelectsRow("l", "Large").
+		BotReplies(whatsappdautest.TextEquals("Thanks!")).
+		Run(t, d, mock)
+
+	session, err := b.Store.Load(context.Background(), "15551234567")
+	if err != nil || session == nil {
+		t.Fatalf("Load = %v, %v", session, err)
+	}
+	if session.State != "done" || session.Data["size"] != "l" {
+		t.Errorf("session = %+v, want state done with size l", session)
+	}
+}
+
+func TestBotPromptDefaultsReplyButtons(t *testing.T) {
+	mock := whatsappdautest.NewMockClient()
+	b := New(mock, "menu").Add(State{
+		Name: "menu",
+		Prompt: Buttons("Pick one",
+			whatsappdau.ButtonItem{ID: "a", Text: "A"},
+			whatsappdau.ButtonItem{ID: "b", Text: "B", Type: "reply"},
+		),
+	})
+
+	if err := b.Handle(context.Background(), inbound("15551234567", "hi")); err != nil {
+		t.Fatal(err)
+	}
+	call, ok := mock.LastCall("SendInteractiveButtons")
+	if !ok {
+		t.Fatal("no buttons sent")
+	}
+	buttons := call.Args[3].([]whatsappdau.ButtonItem)
+	for _, btn := range buttons {
+		if btn.Type != "reply" {
+			t.Errorf("button %s has type %q, want reply", btn.ID, btn.Type)
+		}
+	}
+	if b.states["menu"].Prompt.Buttons[0].Type != "" {
+		t.Error("prompt buttons were modified in place")
+	}
+}
+
+func TestBotSessionPersistence(t *testing.T) {
+	mock := whatsappdautest.NewMockClient()
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	first := orderBot(mock)
+	first.Store = store
+	for _, msg := range []*whatsappdau.InboundMessage{
+		inbound("15551234567", "hi"),
+		buttonTap("15551234567", "order"),
+	} {
+		if err := first.Handle(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new bot on the same store picks the conversation up where it was.
+	second := orderBot(mock)
+	second.Store = store
+	mock.Reset()
+	if err := second.Handle(ctx, inbound("15551234567", "back")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mock.LastCall("SendInteractiveButtons"); !ok {
+		t.Errorf("calls = %v, want the menu prompt", mock.Calls())
+	}
+	session, _ := store.Load(ctx, "15551234567")
+	if session.State != "menu" {
+		t.Errorf("state = %q, want menu", session.State)
+	}
+
+	other, _ := store.Load(ctx, "15557654321")
+	if other != nil {
+		t.Errorf("session of another user = %+v, want nil", other)
+	}
+}
+
+func TestBotSerializesUserSessions(t *testing.T) {
+	mock := whatsappdautest.NewMockClient()
+	b := New(mock, "count").Add(State{
+		Name: "count",
+		Transitions: []Transition{{On: Any(), Do: func(ctx context.Context, c *Context) (string, error) {
+			n, _ := strconv.Atoi(c.Session.Data["n"])
+			// Widen the window between load and save.
+			time.Sleep(time.Millisecond)
+			c.Session.Data["n"] = strconv.Itoa(n + 1)
+			return "count", nil
+		}}},
+	})
+	ctx := context.Background()
+	if err := b.Handle(ctx, inbound("15551234567", "start")); err != nil {
+		t.Fatal(err)
+	}
+
+	const messages = 20
+	var wg sync.WaitGroup
+	for i := 0; i < messages; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Handle(ctx, inbound("15551234567", "+1")); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	session, _ := b.Store.Load(ctx, "15551234567")
+	if session.Data["n"] != strconv.Itoa(messages) {
+		t.Errorf("n = %s, want %d", session.Data["n"], messages)
+	}
+	if len(b.users) != 0 {
+		t.Errorf("%d user locks left behind", len(b.users))
+	}
+}
+
+func inbound(from, text string) *whatsappdau.InboundMessage {
+	return &whatsappdau.InboundMessage{IncomingMessage: whatsappdau.IncomingMessage{
+		From: from,
+		Type: "text",
+		Text: &whatsappdau.IncomingText{Body: text},
+	}}
+}
+
+func buttonTap(from, id string) *whatsappdau.InboundMessage {
+	return &whatsappdau.InboundMessage{IncomingMessage: whatsappdau.IncomingMessage{
+		From: from,
+		Type: "interactive",
+		Interactive: &whatsappdau.IncomingInteractive{
+			Type:        "button_reply",
+			ButtonReply: &whatsappdau.InteractiveReply{ID: id},
+		},
+	}}
+}
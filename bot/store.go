@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+// Session is a user's place in the conversation plus whatever the steps
+// chose to remember.
+type Session struct {
+	State string            `json:"state"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// Store keeps sessions by wa_id. Load returns nil for a user without one.
+type Store interface {
+	Load(ctx context.Context, waID string) (*Session, error)
+	Save(ctx context.Context, waID string, s *Session) error
+}
+
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]Session)}
+}
+
+func (m *MemoryStore) Load(ctx context.Context, waID string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[waID]
+	if !ok {
+		return nil, nil
+	}
+	data := make(map[string]string, len(s.Data))
+	for k, v := range s.Data {
+		data[k] = v
+	}
+	s.Data = data
+	return &s, nil
+}
+
+func (m *MemoryStore) Save(ctx context.Context, waID string, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[waID] = *s
+	return nil
+}
+
+// SessionStore keeps sessions in a whatsappdau.SessionStore, e.g. Redis, so
+// conversations survive restarts. Sessions idle for longer than ttl start
+// over.
+type SessionStore struct {
+	sessions *whatsappdau.Sessions
+}
+
+func NewSessionStore(store whatsappdau.SessionStore, ttl time.Duration) *SessionStore {
+	return &SessionStore{sessions: whatsappdau.NewSessions(store, "bot.Session", ttl)}
+}
+
+func (s *SessionStore) Load(ctx context.Context, waID string) (*Session, error) {
+	var session Session
+	ok, err := s.sessions.Load(ctx, "bot:"+waID, &session)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if session.Data == nil {
+		session.Data = make(map[string]string)
+	}
+	return &session, nil
+}
+
+func (s *SessionStore) Save(ctx context.Context, waID string, session *Session) error {
+	return s.sessions.Save(ctx, "bot:"+waID, session)
+}
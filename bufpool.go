@@ -0,0 +1,105 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// bufferPool recycles the buffers request payloads are encoded into and
+// responses are read into, which are otherwise allocated afresh per send.
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuffer keeps the occasional huge payload from pinning memory.
+const maxPooledBuffer = 64 << 10
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// jsonBody is a JSON request body in a pooled buffer. The buffer goes back to
+// the pool once the sender has released it and the transport has closed
+// every reader it was given; a reader that is never closed leaves the buffer
+// to the garbage collector instead.
+type jsonBody struct {
+	buf  *bytes.Buffer
+	refs atomic.Int32
+}
+
+// encodeJSON encodes v as json.Marshal would. Call release when done.
+func encodeJSON(v interface{}) (*jsonBody, error) {
+	buf := getBuffer()
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	buf.Truncate(buf.Len() - 1) // Encode's trailing newline
+	b := &jsonBody{buf: buf}
+	b.refs.Store(1)
+	return b, nil
+}
+
+func (b *jsonBody) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *jsonBody) release() {
+	if b.refs.Add(-1) == 0 {
+		putBuffer(b.buf)
+	}
+}
+
+func (b *jsonBody) reader() io.ReadCloser {
+	b.refs.Add(1)
+	return &jsonBodyReader{Reader: bytes.NewReader(b.buf.Bytes()), body: b}
+}
+
+type jsonBodyReader struct {
+	*bytes.Reader
+	body   *jsonBody
+	closed atomic.Bool
+}
+
+func (r *jsonBodyReader) Close() error {
+	if r.closed.CompareAndSwap(false, true) {
+		r.body.release()
+	}
+	return nil
+}
+
+// newRequest builds a replayable request sending b.
+func (b *jsonBody) newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = b.reader()
+	req.ContentLength = int64(b.buf.Len())
+	req.GetBody = func() (io.ReadCloser, error) {
+		return b.reader(), nil
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// readBody reads r into a pooled buffer, to be returned with putBuffer.
+func readBody(r io.Reader) (*bytes.Buffer, error) {
+	buf := getBuffer()
+	if _, err := buf.ReadFrom(r); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	return buf, nil
+}
@@ -0,0 +1,207 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BulkMessage sends the message meant for recipient through s.
+type BulkMessage func(ctx context.Context, s Whatsapp, recipient string) (*MessageResponse, error)
+
+// TextMessage is a BulkMessage sending the text body returns for each
+// recipient.
+func TextMessage(body func(recipient string) string, opts ...SendOption) BulkMessage {
+	return func(ctx context.Context, s Whatsapp, recipient string) (*MessageResponse, error) {
+		return s.SendMessage(recipient, body(recipient), opts...)
+	}
+}
+
+type BulkResult struct {
+	Recipient string
+	Response  *MessageResponse
+	Err       error
+}
+
+// BulkReport holds one result per recipient, in the order they were given.
+type BulkReport struct {
+	Results []BulkResult
+	Sent    int
+	Failed  int
+}
+
+// Err joins the errors of all failed recipients, or returns nil.
+func (r BulkReport) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Recipient, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BulkSender sends a message to many recipients with at most Concurrency
+// sends in flight.
+type BulkSender struct {
+	Client      Whatsapp
+	Concurrency int
+	// OnResult, if set, is called as each recipient completes, from the
+	// worker goroutines.
+	OnResult func(BulkResult)
+	// Outcomes, if set, records every send.
+	Outcomes *OutcomeLog
+	// Pressure, if set, pauses the workers while sends are throttled. It
+	// defaults to the client's own Backpressure.
+	Pressure *Backpressure
+
+	mu       sync.Mutex
+	inFlight sync.WaitGroup
+	stopping bool
+}
+
+func NewBulkSender(client Whatsapp, concurrency int) *BulkSender {
+	return &BulkSender{Client: client, Concurrency: concurrency}
+}
+
+// Send sends msg to every recipient. Once ctx is done the remaining
+// recipients fail with its error, and once Shutdown is called with
+// ErrShutdown.
+func (b *BulkSender) Send(ctx context.Context, recipients []string, msg BulkMessage) BulkReport {
+	b.mu.Lock()
+	stopping := b.stopping
+	if !stopping {
+		b.inFlight.Add(1)
+		defer b.inFlight.Done()
+	}
+	b.mu.Unlock()
+	if stopping {
+		report := BulkReport{Results: make([]BulkResult, len(recipients)), Failed: len(recipients)}
+		for i, r := range recipients {
+			report.Results[i] = BulkResult{Recipient: r, Err: ErrShutdown}
+		}
+		return report
+	}
+
+	workers := b.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(recipients) {
+		workers = len(recipients)
+	}
+
+	results := make([]BulkResult, len(recipients))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = b.sendOne(ctx, recipients[idx], msg)
+			}
+		}()
+	}
+	for i := range recipients {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := BulkReport{Results: results}
+	for _, res := range results {
+		if res.Err != nil {
+			report.Failed++
+		} else {
+			report.Sent++
+		}
+	}
+	return report
+}
+
+func (b *BulkSender) sendOne(ctx context.Context, recipient string, msg BulkMessage) BulkResult {
+	res := BulkResult{Recipient: recipient}
+	pressure := pressureOf(b.Pressure, b.Client)
+	if pressure != nil {
+		res.Err = pressure.Wait(ctx)
+	}
+	if res.Err == nil {
+		res.Err = ctx.Err()
+	}
+	if res.Err == nil && b.shuttingDown() {
+		res.Err = ErrShutdown
+	}
+	if res.Err == nil {
+		res.Response, res.Err = msg(ctx, b.Client, recipient)
+		if pressure != nil {
+			pressure.Observe(res.Err)
+		}
+	}
+	if b.Outcomes != nil {
+		b.Outcomes.RecordSend(recipient, res.Response, res.Err)
+	}
+	if b.OnResult != nil {
+		b.OnResult(res)
+	}
+	return res
+}
+
+// Start does nothing; it makes BulkSender a Service.
+func (b *BulkSender) Start(ctx context.Context) error {
+	return nil
+}
+
+// Shutdown makes running and later Sends skip the recipients they have not
+// started on, and waits for the sends in flight.
+func (b *BulkSender) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.stopping = true
+	b.mu.Unlock()
+	return waitContext(ctx, &b.inFlight)
+}
+
+func (b *BulkSender) shuttingDown() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stopping
+}
+
+// FanOutConcurrency bounds the sends SendMessageToMany keeps in flight.
+const FanOutConcurrency = 10
+
+// SendMessageToMany sends body to every recipient concurrently and returns
+// the result for each, keyed by normalized wa_id; duplicates are sent once.
+// A recipient refused by a limiter with a LimitError is retried once the
+// limit resets, unless ctx is done first. Invalid numbers are not sent; their
+// result, keyed as given, holds a *ValidationError.
+func (w *WhatsappClient) SendMessageToMany(ctx context.Context, recipients []string, body string, opts ...SendOption) map[string]BulkResult {
+	msg := func(ctx context.Context, s Whatsapp, recipient string) (*MessageResponse, error) {
+		for {
+			resp, err := s.SendMessage(recipient, body, opts...)
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) {
+				return resp, err
+			}
+			t := time.NewTimer(time.Until(limitErr.RetryAt))
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return nil, err
+			}
+		}
+	}
+	unique, dedup := DedupRecipients(recipients)
+	report := NewBulkSender(w, FanOutConcurrency).Send(ctx, unique, msg)
+	results := make(map[string]BulkResult, len(report.Results)+len(dedup.Invalid))
+	for _, res := range report.Results {
+		results[res.Recipient] = res
+	}
+	for _, d := range dedup.Invalid {
+		results[d.Original] = BulkResult{Recipient: d.Original, Err: validateRecipient(normalizeWAID(d.Original))}
+	}
+	return results
+}
@@ -0,0 +1,141 @@
+package whatsappdau
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CampaignProgress counts recipients handled so far.
+type CampaignProgress struct {
+	Total   int
+	Sent    int
+	Failed  int
+	Skipped int
+}
+
+func (p CampaignProgress) Done() int {
+	return p.Sent + p.Failed + p.Skipped
+}
+
+// CampaignSummary is the result of Campaign.Run.
+type CampaignSummary struct {
+	CampaignProgress
+	Dedup    DedupReport
+	Started  time.Time
+	Duration time.Duration
+}
+
+// Campaign sends one template to many recipients, each with its own
+// variables. Duplicate and invalid numbers are skipped, and so is everyone
+// still waiting when ctx is cancelled.
+type Campaign struct {
+	Client     TemplateSender
+	Template   string
+	Language   string
+	Recipients []Recipient
+	// ID, if set, is sent as biz_opaque_callback_data so status webhooks can
+	// be matched to the campaign.
+	ID string
+	// Rate caps sends per second. 0 leaves pacing to the client's limiters.
+	Rate        float64
+	Concurrency int
+	// OnProgress, if set, is called after every recipient.
+	OnProgress func(CampaignProgress)
+	// Outcomes, if set, records every send and skip.
+	Outcomes *OutcomeLog
+	// Pressure, if set, pauses the campaign while sends are throttled. It
+	// defaults to the client's own Backpressure.
+	Pressure *Backpressure
+}
+
+func NewCampaign(client TemplateSender, template, language string, recipients []Recipient) *Campaign {
+	return &Campaign{Client: client, Template: template, Language: language, Recipients: recipients, Concurrency: 1}
+}
+
+func (c *Campaign) Run(ctx context.Context) (CampaignSummary, error) {
+	summary := CampaignSummary{Started: time.Now()}
+	summary.Total = len(c.Recipients)
+
+	var mu sync.Mutex
+	progress := &summary.CampaignProgress
+	report := func(update func()) {
+		mu.Lock()
+		update()
+		p := *progress
+		mu.Unlock()
+		if c.OnProgress != nil {
+			c.OnProgress(p)
+		}
+	}
+	skip := func(waID, reason string) {
+		if c.Outcomes != nil {
+			c.Outcomes.RecordSkip(waID, reason)
+		}
+		report(func() { progress.Skipped++ })
+	}
+
+	raw := make([]string, len(c.Recipients))
+	for i, r := range c.Recipients {
+		raw[i] = r.WaID
+	}
+	unique, dedup := DedupRecipients(raw)
+	summary.Dedup = dedup
+	for _, d := range dedup.Duplicates {
+		skip(d.WaID, "duplicate")
+	}
+	for _, d := range dedup.Invalid {
+		skip(d.Original, "invalid number")
+	}
+	vars := make(map[string]map[string]string, len(unique))
+	for _, r := range c.Recipients {
+		waID := normalizeWAID(r.WaID)
+		if _, ok := vars[waID]; !ok {
+			vars[waID] = r.Variables
+		}
+	}
+
+	var pace <-chan time.Time
+	if c.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / c.Rate))
+		defer ticker.Stop()
+		pace = ticker.C
+	}
+	var opts []SendOption
+	if c.ID != "" {
+		opts = append(opts, WithCallbackData(c.ID))
+	}
+
+	client, _ := c.Client.(Whatsapp)
+	bulk := NewBulkSender(client, c.Concurrency)
+	bulk.Pressure = pressureOf(c.Pressure, c.Client)
+	bulk.OnResult = func(res BulkResult) {
+		if ctx.Err() != nil && res.Response == nil {
+			skip(res.Recipient, "campaign stopped")
+			return
+		}
+		if c.Outcomes != nil {
+			c.Outcomes.RecordSend(res.Recipient, res.Response, res.Err)
+		}
+		report(func() {
+			if res.Err != nil {
+				progress.Failed++
+			} else {
+				progress.Sent++
+			}
+		})
+	}
+	bulk.Send(ctx, unique, func(ctx context.Context, _ Whatsapp, waID string) (*MessageResponse, error) {
+		if pace != nil {
+			select {
+			case <-pace:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return c.Client.SendTemplate(ctx, waID, NewTemplateMessage(c.Template, c.Language, vars[waID]), opts...)
+	})
+
+	summary.Duration = time.Since(summary.Started)
+	return summary, ctx.Err()
+}
@@ -0,0 +1,72 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// throttledTemplateSender counts sends and reports a Backpressure that is
+// held off for an hour.
+type throttledTemplateSender struct {
+	sent     atomic.Int32
+	pressure *Backpressure
+}
+
+func (s *throttledTemplateSender) SendTemplate(ctx context.Context, to string, t TemplateMessage, opts ...SendOption) (*MessageResponse, error) {
+	s.sent.Add(1)
+	return &MessageResponse{Messages: []Messages{{Id: "wamid.1"}}}, nil
+}
+
+func (s *throttledTemplateSender) Backpressure() *Backpressure {
+	return s.pressure
+}
+
+func TestCampaignWaitsOutClientPressure(t *testing.T) {
+	pressure := NewBackpressure()
+	pressure.Observe(&LimitError{Limit: 250, RetryAt: time.Now().Add(time.Hour)})
+	client := &throttledTemplateSender{pressure: pressure}
+	c := NewCampaign(client, "promo", "en", []Recipient{{WaID: "15551234567"}, {WaID: "15557654321"}})
+	c.Concurrency = 2
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	summary, err := c.Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() = %v, want DeadlineExceeded", err)
+	}
+	if n := client.sent.Load(); n != 0 {
+		t.Errorf("sent %d templates while throttled", n)
+	}
+	if summary.Skipped != 2 {
+		t.Errorf("skipped %d, want 2", summary.Skipped)
+	}
+}
+
+// deferringLimiter refuses every send until an hour from now.
+type deferringLimiter struct{}
+
+func (deferringLimiter) Allow(ctx context.Context, recipient string) error {
+	return &LimitError{Limit: 1, RetryAt: time.Now().Add(time.Hour), Reason: "test"}
+}
+
+func TestSendMessageToManyStopsWaitingWithContext(t *testing.T) {
+	w := NewWhatsappClient(nil, "http://127.0.0.1:0/v21.0/1/messages", "token", nil, WithLimiter(deferringLimiter{})).(*WhatsappClient)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan map[string]BulkResult)
+	go func() { done <- w.SendMessageToMany(ctx, []string{"15551234567", "15557654321"}, "hi") }()
+	select {
+	case results := <-done:
+		for waID, res := range results {
+			if res.Err == nil {
+				t.Errorf("%s: sent, want an error", waID)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SendMessageToMany kept waiting after ctx was done")
+	}
+}
@@ -0,0 +1,60 @@
+// Command whatsappdau is a developer tool for the whatsappdau package.
+//
+// Usage:
+//
+//	whatsappdau simulate -webhook http://localhost:8080/webhook -secret APP_SECRET
+//	whatsappdau send -to 15551234567 -text "hello"
+//	whatsappdau template -to 15551234567 -name order_update -var 1=Ann
+//	whatsappdau upload photo.jpg
+//	whatsappdau media-url MEDIA_ID
+//	whatsappdau download -o photo.jpg MEDIA_ID
+//
+// All but simulate read credentials from WHATSAPP_ACCESS_TOKEN and
+// WHATSAPP_PHONE_NUMBER_ID.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"simulate", "chat with a local bot as a simulated WhatsApp user", runSimulate},
+	{"send", "send a text message", runSend},
+	{"template", "send a message template", runTemplate},
+	{"upload", "upload a media file and print its ID", runUpload},
+	{"media-url", "print the download URL and details of a media ID", runMediaURL},
+	{"download", "download a media file", runDownload},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "whatsappdau %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: whatsappdau <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+const envHelp = `Settings are read from the environment:
+  WHATSAPP_ACCESS_TOKEN     access token (required)
+  WHATSAPP_PHONE_NUMBER_ID  sending phone number ID (required)
+  WHATSAPP_API_VERSION      Graph API version, default ` + whatsappdau.DefaultAPIVersion + `
+  WHATSAPP_BASE_URL         Graph API base URL, default ` + whatsappdau.DefaultBaseURL + `
+  WHATSAPP_TIMEOUT          per-call timeout, default 30s
+  WHATSAPP_MAX_ATTEMPTS     tries per call, default 4
+  WHATSAPP_DRY_RUN          true to print requests instead of sending`
+
+// clientFromEnv builds a client from the variables listed in envHelp.
+func clientFromEnv(dryRun bool) (*whatsappdau.WhatsappClient, error) {
+	var opts []whatsappdau.Option
+	if dryRun {
+		opts = append(opts, whatsappdau.WithDryRun())
+	}
+	client, err := whatsappdau.NewWhatsappClientFromEnv(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w\n\n%s", err, envHelp)
+	}
+	return client.(*whatsappdau.WhatsappClient), nil
+}
+
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: whatsappdau %s %s\n\n", name, usage)
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\n%s\n", envHelp)
+	}
+	return fs
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func runSend(args []string) error {
+	fs := newFlagSet("send", "-to WA_ID -text TEXT")
+	to := fs.String("to", "", "recipient wa_id")
+	text := fs.String("text", "", "message body")
+	dryRun := fs.Bool("dry-run", false, "validate and print without calling the API")
+	fs.Parse(args)
+	if *to == "" || *text == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, err := clientFromEnv(*dryRun)
+	if err != nil {
+		return err
+	}
+	resp, err := client.SendMessage(*to, *text)
+	if err != nil {
+		return err
+	}
+	return printJSON(resp)
+}
+
+// templateVars collects repeated -var NAME=VALUE flags.
+type templateVars map[string]string
+
+func (v templateVars) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v templateVars) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("want NAME=VALUE, got %q", s)
+	}
+	v[name] = value
+	return nil
+}
+
+func runTemplate(args []string) error {
+	fs := newFlagSet("template", "-to WA_ID -name TEMPLATE [-lang CODE] [-var 1=VALUE ...]")
+	to := fs.String("to", "", "recipient wa_id")
+	name := fs.String("name", "", "template name")
+	lang := fs.String("lang", "en_US", "template language code")
+	vars := templateVars{}
+	fs.Var(vars, "var", "body variable as NAME=VALUE, repeatable; use 1, 2, ... for positional parameters")
+	dryRun := fs.Bool("dry-run", false, "validate and print without calling the API")
+	fs.Parse(args)
+	if *to == "" || *name == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, err := clientFromEnv(*dryRun)
+	if err != nil {
+		return err
+	}
+	resp, err := client.SendTemplate(context.Background(), *to, whatsappdau.NewTemplateMessage(*name, *lang, vars))
+	if err != nil {
+		return err
+	}
+	return printJSON(resp)
+}
+
+func runUpload(args []string) error {
+	fs := newFlagSet("upload", "[-type MIME] FILE")
+	mimeType := fs.String("type", "", "MIME type, guessed from the extension when empty")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	if *mimeType == "" {
+		*mimeType = mime.TypeByExtension(filepath.Ext(path))
+		if *mimeType == "" {
+			return fmt.Errorf("cannot guess the MIME type of %s, pass -type", path)
+		}
+	}
+
+	client, err := clientFromEnv(false)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	id, err := client.UploadMedia(context.Background(), f, filepath.Base(path), *mimeType)
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+	return nil
+}
+
+func runMediaURL(args []string) error {
+	fs := newFlagSet("media-url", "MEDIA_ID")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, err := clientFromEnv(false)
+	if err != nil {
+		return err
+	}
+	info, err := client.GetMediaURL(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(info)
+}
+
+func runDownload(args []string) error {
+	fs := newFlagSet("download", "[-o FILE] MEDIA_ID")
+	out := fs.String("o", "", "output file, default the media ID with an extension for its type; - for stdout")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	mediaID := fs.Arg(0)
+
+	client, err := clientFromEnv(false)
+	if err != nil {
+		return err
+	}
+	info, err := client.GetMediaURL(mediaID)
+	if err != nil {
+		return err
+	}
+	if info.Url == "" {
+		return fmt.Errorf("no URL for media %s", mediaID)
+	}
+	data, err := client.DownloadMedia(info.Url)
+	if err != nil {
+		return err
+	}
+
+	switch *out {
+	case "-":
+		_, err = os.Stdout.Write(data)
+		return err
+	case "":
+		*out = mediaID
+		if exts, _ := mime.ExtensionsByType(info.MimeType); len(exts) > 0 {
+			*out += exts[0]
+		}
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d bytes to %s\n", len(data), *out)
+	return nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/daulet140/whatsappdau/whatsappdautest"
+)
+
+const simulateHelp = `Type a message and press enter to send it as the user. Commands:
+  /button ID [TITLE]       tap a reply button
+  /list ID [TITLE]         pick a list row
+  /image|/audio|/video|/document PATH [CAPTION]
+                           attach a file
+  /location LAT LNG [NAME] share a location
+  /help                    show this help
+  /quit                    exit`
+
+type simulator struct {
+	webhook string
+	secret  string
+	from    string
+	name    string
+	api     *whatsappdautest.FakeServer
+	client  *http.Client
+	seq     int
+}
+
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	webhook := fs.String("webhook", "http://localhost:8080/webhook", "URL of the bot's webhook handler")
+	secret := fs.String("secret", os.Getenv("WHATSAPP_APP_SECRET"), "app secret used to sign deliveries")
+	from := fs.String("from", "15551230000", "wa_id of the simulated user")
+	name := fs.String("name", "Test User", "profile name of the simulated user")
+	listen := fs.String("listen", "127.0.0.1:8090", "address of the fake Cloud API the bot should send to")
+	fs.Parse(args)
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	sim := &simulator{
+		webhook: *webhook,
+		secret:  *secret,
+		from:    *from,
+		name:    *name,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	sim.api = whatsappdautest.NewFakeServerListener(l)
+	sim.api.OnSend = sim.printReply
+	defer sim.api.Close()
+
+	fmt.Printf("Fake Cloud API listening; point the bot's apiURL at %s\n", sim.api.MessagesURL())
+	fmt.Printf("Posting webhooks to %s as %s (%s)\n\n", sim.webhook, sim.name, sim.from)
+	fmt.Println(simulateHelp)
+	return sim.loop(os.Stdin)
+}
+
+func (s *simulator) loop(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "/quit" {
+			return nil
+		}
+		if line == "/help" {
+			fmt.Println(simulateHelp)
+			continue
+		}
+
+		msg, err := s.parse(line)
+		if err != nil {
+			fmt.Println("!", err)
+			continue
+		}
+		if err := s.deliver(msg); err != nil {
+			fmt.Println("!", err)
+		}
+	}
+}
+
+func (s *simulator) parse(line string) (whatsappdau.IncomingMessage, error) {
+	s.seq++
+	msg := whatsappdau.IncomingMessage{
+		From:      s.from,
+		ID:        fmt.Sprintf("wamid.SIM%06d", s.seq),
+		Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	if !strings.HasPrefix(line, "/") {
+		msg.Type = "text"
+		msg.Text = &whatsappdau.IncomingText{Body: line}
+		return msg, nil
+	}
+
+	cmd, rest, _ := strings.Cut(line, " ")
+	fields := strings.Fields(rest)
+	switch cmd {
+	case "/button", "/list":
+		if len(fields) == 0 {
+			return msg, fmt.Errorf("usage: %s ID [TITLE]", cmd)
+		}
+		title := strings.Join(fields[1:], " ")
+		if title == "" {
+			title = fields[0]
+		}
+		reply := &whatsappdau.InteractiveReply{ID: fields[0], Title: title}
+		msg.Type = "interactive"
+		msg.Interactive = &whatsappdau.IncomingInteractive{}
+		if cmd == "/button" {
+			msg.Interactive.Type = "button_reply"
+			msg.Interactive.ButtonReply = reply
+		} else {
+			msg.Interactive.Type = "list_reply"
+			msg.Interactive.ListReply = reply
+		}
+	case "/image", "/audio", "/video", "/document":
+		if len(fields) == 0 {
+			return msg, fmt.Errorf("usage: %s PATH [CAPTION]", cmd)
+		}
+		media, err := s.attach(fields[0])
+		if err != nil {
+			return msg, err
+		}
+		media.Caption = strings.Join(fields[1:], " ")
+		msg.Type = strings.TrimPrefix(cmd, "/")
+		switch msg.Type {
+		case "image":
+			msg.Image = media
+		case "audio":
+			media.Caption = ""
+			msg.Audio = media
+		case "video":
+			msg.Video = media
+		case "document":
+			media.Filename = filepath.Base(fields[0])
+			msg.Document = media
+		}
+	case "/location":
+		if len(fields) < 2 {
+			return msg, fmt.Errorf("usage: /location LAT LNG [NAME]")
+		}
+		lat, err1 := strconv.ParseFloat(fields[0], 64)
+		lng, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 != nil || err2 != nil {
+			return msg, fmt.Errorf("invalid coordinates %q %q", fields[0], fields[1])
+		}
+		msg.Type = "location"
+		msg.Location = &whatsappdau.IncomingLocation{Latitude: lat, Longitude: lng, Name: strings.Join(fields[2:], " ")}
+	default:
+		return msg, fmt.Errorf("unknown command %s, try /help", cmd)
+	}
+	return msg, nil
+}
+
+// attach registers a local file with the fake API so the bot can download it.
+func (s *simulator) attach(path string) (*whatsappdau.IncomingMedia, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	id := fmt.Sprintf("%d", 800000000+s.seq)
+	s.api.AddMedia(id, mimeType, data)
+	return &whatsappdau.IncomingMedia{ID: id, MimeType: mimeType}, nil
+}
+
+func (s *simulator) deliver(msg whatsappdau.IncomingMessage) error {
+	contact := whatsappdau.WebhookContact{WaID: s.from}
+	contact.Profile.Name = s.name
+	notification := whatsappdau.WebhookNotification{
+		Object: "whatsapp_business_account",
+		Entry: []whatsappdau.WebhookEntry{{
+			ID: "0",
+			Changes: []whatsappdau.WebhookChange{{
+				Field: "messages",
+				Value: whatsappdau.WebhookValue{
+					MessagingProduct: "whatsapp",
+					Metadata: whatsappdau.WebhookMetadata{
+						DisplayPhoneNumber: "15550000000",
+						PhoneNumberID:      whatsappdautest.FakePhoneNumberID,
+					},
+					Contacts: []whatsappdau.WebhookContact{contact},
+					Messages: []whatsappdau.IncomingMessage{msg},
+				},
+			}},
+		}},
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req := whatsappdautest.NewWebhookRequest(s.webhook, body, s.secret)
+	req.RequestURI = ""
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(b))
+	}
+	return nil
+}
+
+// printReply shows an outbound bot message in a readable form.
+func (s *simulator) printReply(sent whatsappdautest.SentMessage) {
+	fmt.Printf("< [%s] ", sent.Type)
+	switch sent.Type {
+	case "text":
+		fmt.Println(lookup(sent.Payload, "text", "body"))
+	case "interactive":
+		fmt.Println(lookup(sent.Payload, "interactive", "body", "text"))
+		action, _ := lookup(sent.Payload, "interactive", "action").(map[string]interface{})
+		if buttons, ok := action["buttons"].([]interface{}); ok {
+			for _, b := range buttons {
+				fmt.Printf("    (button) %v  %v\n", lookup(b, "reply", "id"), lookup(b, "reply", "title"))
+			}
+		}
+		if sections, ok := action["sections"].([]interface{}); ok {
+			for _, sec := range sections {
+				rows, _ := lookup(sec, "rows").([]interface{})
+				for _, r := range rows {
+					fmt.Printf("    (row) %v  %v\n", lookup(r, "id"), lookup(r, "title"))
+				}
+			}
+		}
+		if params, ok := action["parameters"].(map[string]interface{}); ok {
+			fmt.Printf("    (link) %v  %v\n", params["display_text"], params["url"])
+		}
+	default:
+		fmt.Println(string(sent.Body))
+	}
+}
+
+func lookup(v interface{}, path ...string) interface{} {
+	for _, key := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
@@ -0,0 +1,182 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Codec turns stored values into bytes and back.
+type Codec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type funcCodec struct {
+	name      string
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+}
+
+func (c funcCodec) Name() string                               { return c.name }
+func (c funcCodec) Marshal(v interface{}) ([]byte, error)      { return c.marshal(v) }
+func (c funcCodec) Unmarshal(data []byte, v interface{}) error { return c.unmarshal(data, v) }
+
+// NewCodec builds a Codec from a pair of functions. A protobuf codec, for
+// example, is
+//
+//	NewCodec("proto",
+//		func(v interface{}) ([]byte, error) { return proto.Marshal(v.(proto.Message)) },
+//		func(b []byte, v interface{}) error { return proto.Unmarshal(b, v.(proto.Message)) })
+func NewCodec(name string, marshal func(v interface{}) ([]byte, error), unmarshal func(data []byte, v interface{}) error) Codec {
+	return funcCodec{name: name, marshal: marshal, unmarshal: unmarshal}
+}
+
+var JSONCodec = NewCodec("json", json.Marshal, json.Unmarshal)
+
+var GobCodec = NewCodec("gob",
+	func(v interface{}) ([]byte, error) {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	},
+	func(data []byte, v interface{}) error {
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+	},
+)
+
+// Envelope wraps a stored payload with what is needed to read it back after
+// the codec or the struct has changed.
+type Envelope struct {
+	Codec   string `json:"codec"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Data    []byte `json:"data"`
+}
+
+// Migration upgrades a payload of one type from version n to n+1. data is
+// encoded with the codec named in the envelope, and so must the result be.
+type Migration func(data []byte) ([]byte, error)
+
+var ErrNoMigration = errors.New("whatsappdau: no migration for stored version")
+
+// Serializer encodes long-lived state (sessions, queued messages) in
+// versioned envelopes. Values are written with Codec at the type's current
+// version; on read, older versions are upgraded through the registered
+// migrations, and envelopes written with any codec in Codecs can be read.
+type Serializer struct {
+	Codec  Codec
+	Codecs map[string]Codec
+
+	mu         sync.RWMutex
+	versions   map[string]int
+	migrations map[string]map[int]Migration
+}
+
+// NewSerializer returns a Serializer writing with codec, or JSON when codec is
+// nil. It can read JSON and gob envelopes in any case.
+func NewSerializer(codec Codec) *Serializer {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	s := &Serializer{
+		Codec: codec,
+		Codecs: map[string]Codec{
+			JSONCodec.Name(): JSONCodec,
+			GobCodec.Name():  GobCodec,
+		},
+		versions:   make(map[string]int),
+		migrations: make(map[string]map[int]Migration),
+	}
+	s.Codecs[codec.Name()] = codec
+	return s
+}
+
+// SetVersion declares the current schema version of typeName. Types default
+// to version 1.
+func (s *Serializer) SetVersion(typeName string, version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[typeName] = version
+}
+
+// Migrate registers m to upgrade typeName payloads from version from to
+// from+1.
+func (s *Serializer) Migrate(typeName string, from int, m Migration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.migrations[typeName] == nil {
+		s.migrations[typeName] = make(map[int]Migration)
+	}
+	s.migrations[typeName][from] = m
+}
+
+func (s *Serializer) version(typeName string) int {
+	if v, ok := s.versions[typeName]; ok {
+		return v
+	}
+	return 1
+}
+
+func (s *Serializer) Encode(typeName string, v interface{}) ([]byte, error) {
+	data, err := s.Codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", typeName, err)
+	}
+	s.mu.RLock()
+	version := s.version(typeName)
+	s.mu.RUnlock()
+	return json.Marshal(Envelope{
+		Codec:   s.Codec.Name(),
+		Type:    typeName,
+		Version: version,
+		Data:    data,
+	})
+}
+
+// Decode reads an envelope written by Encode into v, migrating it to the
+// current version of typeName first.
+func (s *Serializer) Decode(raw []byte, typeName string, v interface{}) error {
+	var env Envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return fmt.Errorf("decode %s: bad envelope: %w", typeName, err)
+	}
+	if env.Type != typeName {
+		return fmt.Errorf("decode %s: envelope holds %s", typeName, env.Type)
+	}
+	codec, ok := s.Codecs[env.Codec]
+	if !ok {
+		return fmt.Errorf("decode %s: unknown codec %q", typeName, env.Codec)
+	}
+
+	s.mu.RLock()
+	current := s.version(typeName)
+	migrations := s.migrations[typeName]
+	s.mu.RUnlock()
+
+	data := env.Data
+	for ver := env.Version; ver < current; ver++ {
+		m, ok := migrations[ver]
+		if !ok {
+			return fmt.Errorf("decode %s v%d: %w", typeName, ver, ErrNoMigration)
+		}
+		var err error
+		if data, err = m(data); err != nil {
+			return fmt.Errorf("decode %s: migrate v%d: %w", typeName, ver, err)
+		}
+	}
+	if env.Version > current {
+		return fmt.Errorf("decode %s: stored version %d is newer than %d", typeName, env.Version, current)
+	}
+
+	if err := codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s: %w", typeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+type CommerceSettings struct {
+	ID               string `json:"id,omitempty"`
+	IsCartEnabled    bool   `json:"is_cart_enabled"`
+	IsCatalogVisible bool   `json:"is_catalog_visible"`
+}
+
+func (w *WhatsappClient) GetCommerceSettings(ctx context.Context) (*CommerceSettings, error) {
+	var response struct {
+		Data []CommerceSettings `json:"data"`
+	}
+	if err := w.callGraph(ctx, "GET", w.phoneNumberURL()+"/whatsapp_commerce_settings", nil, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 {
+		return nil, errors.New("no commerce settings returned")
+	}
+	return &response.Data[0], nil
+}
+
+// UpdateCommerceSettings sets whether the cart is enabled and whether the
+// catalog is shown in chat.
+func (w *WhatsappClient) UpdateCommerceSettings(ctx context.Context, settings CommerceSettings) error {
+	q := url.Values{
+		"is_cart_enabled":    {strconv.FormatBool(settings.IsCartEnabled)},
+		"is_catalog_visible": {strconv.FormatBool(settings.IsCatalogVisible)},
+	}
+	var response struct {
+		Success bool `json:"success"`
+	}
+	if err := w.callGraph(ctx, "POST", w.phoneNumberURL()+"/whatsapp_commerce_settings?"+q.Encode(), nil, &response); err != nil {
+		return err
+	}
+	if !response.Success {
+		return fmt.Errorf("update commerce settings: API did not report success")
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Duration is a time.Duration written in config files as a string such as
+// "30s" or "2m".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config describes a client declaratively, so each tenant of a multi-tenant
+// deployment can be one file. Empty fields keep the library defaults.
+type Config struct {
+	AccessToken   string `json:"access_token" yaml:"access_token"`
+	PhoneNumberID string `json:"phone_number_id" yaml:"phone_number_id"`
+	APIVersion    string `json:"api_version,omitempty" yaml:"api_version,omitempty"`
+	BaseURL       string `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+
+	Timeouts       TimeoutsConfig   `json:"timeouts,omitempty" yaml:"timeouts,omitempty"`
+	Retry          *RetryConfig     `json:"retry,omitempty" yaml:"retry,omitempty"`
+	RateLimit      *RateLimitConfig `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	Transport      *TransportConfig `json:"transport,omitempty" yaml:"transport,omitempty"`
+	Webhook        WebhookConfig    `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	DryRun         bool             `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	SkipValidation bool             `json:"skip_validation,omitempty" yaml:"skip_validation,omitempty"`
+}
+
+type TimeoutsConfig struct {
+	Send     Duration `json:"send,omitempty" yaml:"send,omitempty"`
+	Upload   Duration `json:"upload,omitempty" yaml:"upload,omitempty"`
+	Download Duration `json:"download,omitempty" yaml:"download,omitempty"`
+	Default  Duration `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// RetryConfig adjusts DefaultRetryPolicy; zero fields keep its values.
+type RetryConfig struct {
+	MaxAttempts        int      `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+	MaxElapsed         Duration `json:"max_elapsed,omitempty" yaml:"max_elapsed,omitempty"`
+	Statuses           []int    `json:"statuses,omitempty" yaml:"statuses,omitempty"`
+	ErrorCodes         []int    `json:"error_codes,omitempty" yaml:"error_codes,omitempty"`
+	RetryNetworkErrors bool     `json:"retry_network_errors,omitempty" yaml:"retry_network_errors,omitempty"`
+	Backoff            Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	MaxBackoff         Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty"`
+}
+
+// RateLimitConfig selects the send limiters: Tier enforces the
+// number's messaging limit tier, Ramp warms up a new number and Adaptive
+// paces requests by the reported usage.
+type RateLimitConfig struct {
+	Tier     bool            `json:"tier,omitempty" yaml:"tier,omitempty"`
+	Ramp     *RampConfig     `json:"ramp,omitempty" yaml:"ramp,omitempty"`
+	Adaptive *AdaptiveConfig `json:"adaptive,omitempty" yaml:"adaptive,omitempty"`
+}
+
+// AdaptiveConfig is an AdaptiveThrottle; zero fields keep its defaults.
+type AdaptiveConfig struct {
+	Start    int      `json:"start,omitempty" yaml:"start,omitempty"`
+	MaxDelay Duration `json:"max_delay,omitempty" yaml:"max_delay,omitempty"`
+	Stale    Duration `json:"stale,omitempty" yaml:"stale,omitempty"`
+}
+
+// RampConfig is a RampPolicy; zero Day1Limit and Growth keep
+// DefaultRampPolicy's.
+type RampConfig struct {
+	Start     time.Time `json:"start" yaml:"start"`
+	Day1Limit int       `json:"day1_limit,omitempty" yaml:"day1_limit,omitempty"`
+	Growth    float64   `json:"growth,omitempty" yaml:"growth,omitempty"`
+}
+
+type TransportConfig struct {
+	MaxIdleConns        int      `json:"max_idle_conns,omitempty" yaml:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost int      `json:"max_idle_conns_per_host,omitempty" yaml:"max_idle_conns_per_host,omitempty"`
+	MaxConnsPerHost     int      `json:"max_conns_per_host,omitempty" yaml:"max_conns_per_host,omitempty"`
+	IdleConnTimeout     Duration `json:"idle_conn_timeout,omitempty" yaml:"idle_conn_timeout,omitempty"`
+	KeepAlive           Duration `json:"keep_alive,omitempty" yaml:"keep_alive,omitempty"`
+	DisableKeepAlives   bool     `json:"disable_keep_alives,omitempty" yaml:"disable_keep_alives,omitempty"`
+}
+
+type WebhookConfig struct {
+	Addr        string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	VerifyToken string `json:"verify_token,omitempty" yaml:"verify_token,omitempty"`
+	AppSecret   string `json:"app_secret,omitempty" yaml:"app_secret,omitempty"`
+}
+
+// LoadConfig reads a Config from path, decoded with codec, JSONCodec if nil.
+// For YAML pass a codec over a YAML package:
+//
+//	whatsappdau.LoadConfig("tenant.yaml", whatsappdau.NewCodec("yaml", yaml.Marshal, yaml.Unmarshal))
+func LoadConfig(path string, codec Codec) (*Config, error) {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var c Config
+	if err := codec.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode config %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Validate checks that c can build a client.
+func (c *Config) Validate() error {
+	if err := validateRequired("access_token", c.AccessToken); err != nil {
+		return err
+	}
+	return validateRequired("phone_number_id", c.PhoneNumberID)
+}
+
+// Options returns the options c describes.
+func (c *Config) Options() []Option {
+	var opts []Option
+	if t := c.Timeouts; t != (TimeoutsConfig{}) {
+		opts = append(opts, WithTimeouts(Timeouts{
+			Send:     time.Duration(t.Send),
+			Upload:   time.Duration(t.Upload),
+			Download: time.Duration(t.Download),
+			Default:  time.Duration(t.Default),
+		}))
+	}
+	if c.Retry != nil {
+		opts = append(opts, WithRetryPolicy(c.Retry.policy()))
+	}
+	if rl := c.RateLimit; rl != nil {
+		if rl.Tier {
+			opts = append(opts, WithLimiter(NewTierLimiter(nil)))
+		}
+		if rl.Ramp != nil {
+			policy := DefaultRampPolicy(rl.Ramp.Start)
+			if rl.Ramp.Day1Limit > 0 {
+				policy.Day1Limit = rl.Ramp.Day1Limit
+			}
+			if rl.Ramp.Growth > 0 {
+				policy.Growth = rl.Ramp.Growth
+			}
+			opts = append(opts, WithLimiter(NewRampLimiter(policy)))
+		}
+		if a := rl.Adaptive; a != nil {
+			opts = append(opts, WithAdaptiveThrottling(AdaptiveThrottle{Start: a.Start, MaxDelay: time.Duration(a.MaxDelay), Stale: time.Duration(a.Stale)}))
+		}
+	}
+	if t := c.Transport; t != nil {
+		opts = append(opts, WithTransport(TransportSettings{
+			MaxIdleConns:        t.MaxIdleConns,
+			MaxIdleConnsPerHost: t.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     t.MaxConnsPerHost,
+			IdleConnTimeout:     time.Duration(t.IdleConnTimeout),
+			KeepAlive:           time.Duration(t.KeepAlive),
+			DisableKeepAlives:   t.DisableKeepAlives,
+		}))
+	}
+	if c.DryRun {
+		opts = append(opts, WithDryRun())
+	}
+	if c.SkipValidation {
+		opts = append(opts, WithoutValidation())
+	}
+	return opts
+}
+
+func (r *RetryConfig) policy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	if r.MaxAttempts > 0 {
+		p.MaxAttempts = r.MaxAttempts
+	}
+	if r.MaxElapsed > 0 {
+		p.MaxElapsed = time.Duration(r.MaxElapsed)
+	}
+	if len(r.Statuses) > 0 {
+		p.Statuses = r.Statuses
+	}
+	if len(r.ErrorCodes) > 0 {
+		p.ErrorCodes = r.ErrorCodes
+	}
+	p.RetryNetworkErrors = r.RetryNetworkErrors
+	if r.Backoff > 0 {
+		p.Backoff = time.Duration(r.Backoff)
+	}
+	if r.MaxBackoff > 0 {
+		p.MaxBackoff = time.Duration(r.MaxBackoff)
+	}
+	return p
+}
+
+// NewClientFromConfig builds a client from c. opts are applied after the
+// options from c, so they win.
+func NewClientFromConfig(ctx context.Context, c *Config, opts ...Option) (Whatsapp, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	endpoint := Endpoint{BaseURL: c.BaseURL, Version: c.APIVersion, PhoneNumberID: c.PhoneNumberID}
+	return NewWhatsappClientWithEndpoint(ctx, endpoint, c.AccessToken, nil, append(c.Options(), opts...)...), nil
+}
+
+// NewDispatcher returns a Dispatcher with the webhook credentials of c.
+func (c *Config) NewDispatcher() *Dispatcher {
+	return NewDispatcher(c.Webhook.VerifyToken, c.Webhook.AppSecret)
+}
+
+// NewWebhookServer serves d on the webhook address of c, ":8080" if unset.
+func (c *Config) NewWebhookServer(d *Dispatcher) *WebhookServer {
+	addr := c.Webhook.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+	return NewWebhookServer(addr, d)
+}
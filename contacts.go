@@ -0,0 +1,142 @@
+package whatsappdau
+
+import (
+	"context"
+	"strings"
+)
+
+// Contact is a contact card sent with SendContacts. Name.FormattedName is
+// required.
+type Contact struct {
+	Addresses []ContactAddress `json:"addresses,omitempty"`
+	Birthday  string           `json:"birthday,omitempty"`
+	Emails    []ContactEmail   `json:"emails,omitempty"`
+	Name      ContactName      `json:"name"`
+	Org       *ContactOrg      `json:"org,omitempty"`
+	Phones    []ContactPhone   `json:"phones,omitempty"`
+	URLs      []ContactURL     `json:"urls,omitempty"`
+}
+
+type ContactAddress struct {
+	Street      string `json:"street,omitempty"`
+	City        string `json:"city,omitempty"`
+	State       string `json:"state,omitempty"`
+	Zip         string `json:"zip,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+type ContactEmail struct {
+	Email string `json:"email"`
+	Type  string `json:"type,omitempty"`
+}
+
+type ContactName struct {
+	FormattedName string `json:"formatted_name"`
+	FirstName     string `json:"first_name,omitempty"`
+	LastName      string `json:"last_name,omitempty"`
+	MiddleName    string `json:"middle_name,omitempty"`
+	Prefix        string `json:"prefix,omitempty"`
+	Suffix        string `json:"suffix,omitempty"`
+}
+
+type ContactOrg struct {
+	Company    string `json:"company,omitempty"`
+	Department string `json:"department,omitempty"`
+	Title      string `json:"title,omitempty"`
+}
+
+// ContactPhone is a phone number on a card. With WaID set, WhatsApp shows a
+// button to message the contact.
+type ContactPhone struct {
+	Phone string `json:"phone"`
+	Type  string `json:"type,omitempty"`
+	WaID  string `json:"wa_id,omitempty"`
+}
+
+type ContactURL struct {
+	URL  string `json:"url"`
+	Type string `json:"type,omitempty"`
+}
+
+// ContactBuilder assembles a Contact:
+//
+//	card := NewContactBuilder().WithName("Ada", "Lovelace").WithPhone("447700900123").Build()
+type ContactBuilder struct {
+	c Contact
+}
+
+func NewContactBuilder() *ContactBuilder {
+	return &ContactBuilder{}
+}
+
+// WithName sets the first and last name and the formatted name shown on the
+// card.
+func (b *ContactBuilder) WithName(first, last string) *ContactBuilder {
+	b.c.Name.FirstName = first
+	b.c.Name.LastName = last
+	b.c.Name.FormattedName = strings.TrimSpace(first + " " + last)
+	return b
+}
+
+// WithFormattedName overrides the name shown on the card.
+func (b *ContactBuilder) WithFormattedName(name string) *ContactBuilder {
+	b.c.Name.FormattedName = name
+	return b
+}
+
+// WithPhone adds a WhatsApp number, given as a wa_id, with a message button.
+func (b *ContactBuilder) WithPhone(waID string) *ContactBuilder {
+	waID = normalizeWAID(waID)
+	b.c.Phones = append(b.c.Phones, ContactPhone{Phone: "+" + waID, Type: "CELL", WaID: waID})
+	return b
+}
+
+func (b *ContactBuilder) WithEmail(email string) *ContactBuilder {
+	b.c.Emails = append(b.c.Emails, ContactEmail{Email: email, Type: "WORK"})
+	return b
+}
+
+func (b *ContactBuilder) WithOrg(company, title string) *ContactBuilder {
+	b.c.Org = &ContactOrg{Company: company, Title: title}
+	return b
+}
+
+func (b *ContactBuilder) WithURL(url string) *ContactBuilder {
+	b.c.URLs = append(b.c.URLs, ContactURL{URL: url, Type: "WORK"})
+	return b
+}
+
+func (b *ContactBuilder) Build() Contact {
+	return b.c
+}
+
+// SendContacts sends one or more contact cards.
+func (w *WhatsappClient) SendContacts(ctx context.Context, recipientWAID string, contacts []Contact, opts ...SendOption) (*MessageResponse, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), validateContacts(contacts), o.validate()); err != nil {
+		return nil, err
+	}
+	payload := struct {
+		MessagingProduct string    `json:"messaging_product"`
+		RecipientType    string    `json:"recipient_type"`
+		To               string    `json:"to"`
+		Type             string    `json:"type"`
+		CallbackData     string    `json:"biz_opaque_callback_data,omitempty"`
+		Contacts         []Contact `json:"contacts"`
+	}{"whatsapp", "individual", recipientWAID, "contacts", o.callbackData, contacts}
+	return w.postMessage(ctx, recipientWAID, payload)
+}
+
+func validateContacts(contacts []Contact) error {
+	if len(contacts) == 0 {
+		return &ValidationError{Field: "contacts", Reason: "at least one contact is required"}
+	}
+	for _, c := range contacts {
+		if err := validateRequired("contact formatted_name", c.Name.FormattedName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Coverage records which bot routes and which interactive reply IDs have
+// actually been exercised, by tests or live traffic, so unreachable or
+// untested branches show up in Report.
+//
+// Routes are handlers wrapped with Route. Interactive IDs are declared with
+// DeclareIDs, or automatically when messages go out through Sender, and
+// counted when a user taps them.
+type Coverage struct {
+	mu         sync.Mutex
+	routes     map[string]int
+	ids        map[string]int
+	undeclared map[string]int
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{
+		routes:     make(map[string]int),
+		ids:        make(map[string]int),
+		undeclared: make(map[string]int),
+	}
+}
+
+// Register makes c observe every message d dispatches.
+func (c *Coverage) Register(d *Dispatcher) {
+	d.OnMessage(c.Observe)
+}
+
+// Route registers a named route and returns h instrumented to count its hits.
+func (c *Coverage) Route(name string, h MessageHandler) MessageHandler {
+	c.mu.Lock()
+	if _, ok := c.routes[name]; !ok {
+		c.routes[name] = 0
+	}
+	c.mu.Unlock()
+
+	return func(ctx context.Context, msg *InboundMessage) error {
+		c.mu.Lock()
+		c.routes[name]++
+		c.mu.Unlock()
+		return h(ctx, msg)
+	}
+}
+
+// DeclareIDs registers button or list row IDs the bot can offer.
+func (c *Coverage) DeclareIDs(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := c.ids[id]; !ok {
+			c.ids[id] = 0
+		}
+	}
+}
+
+// Observe counts the interactive reply ID carried by msg, if any.
+func (c *Coverage) Observe(ctx context.Context, msg *InboundMessage) error {
+	id := replyID(&msg.IncomingMessage)
+	if id == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.ids[id]; ok {
+		c.ids[id]++
+	} else {
+		c.undeclared[id]++
+	}
+	return nil
+}
+
+func replyID(msg *IncomingMessage) string {
+	r, _ := msg.Reply()
+	return r.ID
+}
+
+// Sender wraps s so that every button and list row it sends is declared.
+func (c *Coverage) Sender(s InteractiveSender) InteractiveSender {
+	return &coverageSender{InteractiveSender: s, coverage: c}
+}
+
+type coverageSender struct {
+	InteractiveSender
+	coverage *Coverage
+}
+
+func (s *coverageSender) SendInteractiveList(recipientPhoneNumber string, bodyText string, buttonTitle string, items []ListItem, opts ...SendOption) (*MessageResponse, error) {
+	for _, item := range items {
+		s.coverage.DeclareIDs(item.ID)
+	}
+	return s.InteractiveSender.SendInteractiveList(recipientPhoneNumber, bodyText, buttonTitle, items, opts...)
+}
+
+func (s *coverageSender) SendInteractiveButtons(recipientPhoneNumber string, menuType, bodyText string, buttons []ButtonItem, opts ...SendOption) (*MessageResponse, error) {
+	for _, btn := range buttons {
+		if btn.ID != "" {
+			s.coverage.DeclareIDs(btn.ID)
+		}
+	}
+	return s.InteractiveSender.SendInteractiveButtons(recipientPhoneNumber, menuType, bodyText, buttons, opts...)
+}
+
+type CoverageItem struct {
+	Name string
+	Hits int
+}
+
+type CoverageReport struct {
+	Routes []CoverageItem
+	IDs    []CoverageItem
+	// Undeclared are IDs users sent that were never declared, e.g. stale
+	// buttons from an older version of the bot.
+	Undeclared []CoverageItem
+}
+
+func (c *Coverage) Report() CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CoverageReport{
+		Routes:     coverageItems(c.routes),
+		IDs:        coverageItems(c.ids),
+		Undeclared: coverageItems(c.undeclared),
+	}
+}
+
+func coverageItems(m map[string]int) []CoverageItem {
+	items := make([]CoverageItem, 0, len(m))
+	for name, hits := range m {
+		items = append(items, CoverageItem{Name: name, Hits: hits})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+// UnreachedRoutes returns the routes that were never hit.
+func (r CoverageReport) UnreachedRoutes() []string {
+	return unreached(r.Routes)
+}
+
+// UnreachedIDs returns the declared IDs no user ever sent.
+func (r CoverageReport) UnreachedIDs() []string {
+	return unreached(r.IDs)
+}
+
+func unreached(items []CoverageItem) []string {
+	var names []string
+	for _, item := range items {
+		if item.Hits == 0 {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+func (r CoverageReport) String() string {
+	var b strings.Builder
+	section := func(title string, items []CoverageItem) {
+		hit := len(items) - len(unreached(items))
+		fmt.Fprintf(&b, "%s: %d/%d reached\n", title, hit, len(items))
+		for _, item := range items {
+			mark := " "
+			if item.Hits == 0 {
+				mark = "!"
+			}
+			fmt.Fprintf(&b, "  %s %-30s %d\n", mark, item.Name, item.Hits)
+		}
+	}
+	section("routes", r.Routes)
+	section("interactive ids", r.IDs)
+	if len(r.Undeclared) > 0 {
+		fmt.Fprintf(&b, "undeclared ids received:\n")
+		for _, item := range r.Undeclared {
+			fmt.Fprintf(&b, "    %-30s %d\n", item.Name, item.Hits)
+		}
+	}
+	return b.String()
+}
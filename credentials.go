@@ -0,0 +1,137 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNoToken            = errors.New("whatsappdau: access token not found")
+	ErrKeyringUnsupported = errors.New("whatsappdau: no keyring available on this platform")
+)
+
+// TokenSource yields the Cloud API access token.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// EnvToken reads the token from an environment variable.
+type EnvToken string
+
+func (e EnvToken) Token(ctx context.Context) (string, error) {
+	if v := strings.TrimSpace(os.Getenv(string(e))); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: $%s is empty", ErrNoToken, string(e))
+}
+
+// KeyringToken reads the token from the OS keyring: the login keychain on
+// macOS and the Secret Service (via secret-tool) on Linux.
+type KeyringToken struct {
+	Service string
+	Account string
+}
+
+func (k KeyringToken) Token(ctx context.Context) (string, error) {
+	token, err := keyringGet(ctx, k.Service, k.Account)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", fmt.Errorf("%w: no keyring entry for %s/%s", ErrNoToken, k.Service, k.Account)
+	}
+	return token, nil
+}
+
+// Store saves token in the keyring, replacing any existing entry.
+func (k KeyringToken) Store(ctx context.Context, token string) error {
+	return keyringSet(ctx, k.Service, k.Account, token)
+}
+
+// Credentials tries each source in order and caches the first token found
+// in memory locked against swapping, where the platform allows it.
+//
+//	creds := NewCredentials(
+//		EnvToken("WHATSAPP_TOKEN"),
+//		KeyringToken{Service: "whatsappdau", Account: phoneID},
+//		filetokenwhatsappdau.Token{Path: tokenPath, Passphrase: pass},
+//	)
+//	defer creds.Close()
+type Credentials struct {
+	Sources []TokenSource
+
+	mu     sync.Mutex
+	cached *lockedBuffer
+}
+
+func NewCredentials(sources ...TokenSource) *Credentials {
+	return &Credentials{Sources: sources}
+}
+
+// Token returns the cached token as a string, which is a copy on the Go
+// heap. Use it where a string is required, such as NewWhatsappClient; Use
+// reads the token without copying it out of locked memory.
+func (c *Credentials) Token(ctx context.Context) (string, error) {
+	var token string
+	err := c.Use(ctx, func(b []byte) error {
+		token = string(b)
+		return nil
+	})
+	return token, err
+}
+
+// Use calls fn with the token in locked memory. fn must not keep b, which is
+// wiped by Close.
+func (c *Credentials) Use(ctx context.Context, fn func(b []byte) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached == nil {
+		if err := c.load(ctx); err != nil {
+			return err
+		}
+	}
+	return fn(c.cached.bytes())
+}
+
+func (c *Credentials) load(ctx context.Context) error {
+	var errs []error
+	for _, src := range c.Sources {
+		token, err := src.Token(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		buf, err := newLockedBuffer([]byte(token))
+		if err != nil {
+			return fmt.Errorf("failed to cache token: %w", err)
+		}
+		c.cached = buf
+		return nil
+	}
+	if len(errs) == 0 {
+		return ErrNoToken
+	}
+	return errors.Join(errs...)
+}
+
+// Close wipes and releases the cached token. The next Token call reloads it.
+func (c *Credentials) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached == nil {
+		return nil
+	}
+	err := c.cached.release()
+	c.cached = nil
+	return err
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
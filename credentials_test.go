@@ -0,0 +1,33 @@
+package whatsappdau
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCredentialsUse(t *testing.T) {
+	t.Setenv("WHATSAPPDAU_TEST_TOKEN", "EAAB-env")
+	creds := NewCredentials(EnvToken("WHATSAPPDAU_TEST_MISSING"), EnvToken("WHATSAPPDAU_TEST_TOKEN"))
+	defer creds.Close()
+
+	var first []byte
+	err := creds.Use(context.Background(), func(b []byte) error {
+		first = b
+		if string(b) != "EAAB-env" {
+			t.Errorf("got %q", b)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds.Use(context.Background(), func(b []byte) error {
+		if &b[0] != &first[0] {
+			t.Error("Use copied the cached token")
+		}
+		return nil
+	})
+	if token, err := creds.Token(context.Background()); err != nil || token != "EAAB-env" {
+		t.Errorf("Token() = %q, %v", token, err)
+	}
+}
@@ -0,0 +1,147 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CRMContact struct {
+	WaID       string
+	Name       string
+	Attributes map[string]string
+}
+
+const (
+	DirectionInbound  = "inbound"
+	DirectionOutbound = "outbound"
+)
+
+// CRMActivity is a timeline entry for a contact: an inbound message or the
+// outcome of an outbound one.
+type CRMActivity struct {
+	WaID      string
+	Direction string
+	Type      string
+	Text      string
+	MessageID string
+	Status    string
+	Timestamp time.Time
+}
+
+// CRMAdapter connects conversations to a CRM.
+type CRMAdapter interface {
+	UpsertContact(ctx context.Context, contact CRMContact) error
+	LogActivity(ctx context.Context, activity CRMActivity) error
+	FetchAttributes(ctx context.Context, waID string) (map[string]string, error)
+}
+
+// CRMSync feeds a CRMAdapter from the dispatcher and from campaign outcomes.
+// The first message seen from a wa_id upserts the contact; every inbound
+// message is logged as an activity. A SystemUserChangedNumber message
+// upserts the new wa_id with a previous_wa_id attribute.
+type CRMSync struct {
+	Adapter CRMAdapter
+
+	seen sync.Map
+}
+
+func NewCRMSync(adapter CRMAdapter) *CRMSync {
+	return &CRMSync{Adapter: adapter}
+}
+
+// Register hooks the sync into d.
+func (s *CRMSync) Register(d *Dispatcher) {
+	d.OnMessage(s.HandleMessage)
+	d.OnEcho(s.HandleEcho)
+}
+
+func (s *CRMSync) HandleMessage(ctx context.Context, msg *InboundMessage) error {
+	if _, loaded := s.seen.LoadOrStore(msg.From, true); !loaded {
+		err := s.Adapter.UpsertContact(ctx, CRMContact{WaID: msg.From, Name: msg.ContactName()})
+		if err != nil {
+			s.seen.Delete(msg.From)
+			return fmt.Errorf("crm: upsert contact %s: %w", msg.From, err)
+		}
+	}
+
+	activity := CRMActivity{
+		WaID:      msg.From,
+		Direction: DirectionInbound,
+		Type:      msg.Type,
+		MessageID: msg.ID,
+		Timestamp: parseWebhookTime(msg.Timestamp),
+	}
+	if msg.Text != nil {
+		activity.Text = msg.Text.Body
+	}
+	if msg.System != nil {
+		activity.Text = msg.System.Body
+	}
+	if err := s.Adapter.LogActivity(ctx, activity); err != nil {
+		return fmt.Errorf("crm: log activity for %s: %w", msg.From, err)
+	}
+
+	// A changed number arrives from the old wa_id; carry the contact over.
+	if msg.System != nil && msg.System.Type == SystemUserChangedNumber && msg.System.NewWaID != "" {
+		newID := msg.System.NewWaID
+		contact := CRMContact{WaID: newID, Name: msg.ContactName(), Attributes: map[string]string{"previous_wa_id": msg.From}}
+		if err := s.Adapter.UpsertContact(ctx, contact); err != nil {
+			return fmt.Errorf("crm: upsert contact %s: %w", newID, err)
+		}
+		s.seen.Store(newID, true)
+	}
+	return nil
+}
+
+// HandleEcho logs a message an agent sent from the WhatsApp Business app as
+// an outbound activity.
+func (s *CRMSync) HandleEcho(ctx context.Context, echo *MessageEcho) error {
+	activity := CRMActivity{
+		WaID:      echo.To,
+		Direction: DirectionOutbound,
+		Type:      echo.Type,
+		MessageID: echo.ID,
+		Timestamp: parseWebhookTime(echo.Timestamp),
+	}
+	if echo.Text != nil {
+		activity.Text = echo.Text.Body
+	}
+	if err := s.Adapter.LogActivity(ctx, activity); err != nil {
+		return fmt.Errorf("crm: log activity for %s: %w", echo.To, err)
+	}
+	return nil
+}
+
+// WatchOutcomes logs every change in outcomes to the CRM, after calling any
+// OnChange already set. Failures are logged since OnChange cannot return
+// them.
+func (s *CRMSync) WatchOutcomes(ctx context.Context, outcomes *OutcomeLog) {
+	prev := outcomes.OnChange
+	outcomes.OnChange = func(o MessageOutcome) {
+		if prev != nil {
+			prev(o)
+		}
+		if err := s.LogOutcome(ctx, o); err != nil {
+			logger().Error("crm: logging outcome failed", "recipient", o.Recipient, "error", err)
+		}
+	}
+}
+
+// LogOutcome records the result of a campaign send on the contact's timeline.
+func (s *CRMSync) LogOutcome(ctx context.Context, outcome MessageOutcome) error {
+	ts := outcome.UpdatedAt
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return s.Adapter.LogActivity(ctx, CRMActivity{
+		WaID:      outcome.Recipient,
+		Direction: DirectionOutbound,
+		Type:      "campaign",
+		Text:      outcome.Error,
+		MessageID: outcome.MessageID,
+		Status:    outcome.Status,
+		Timestamp: ts,
+	})
+}
@@ -0,0 +1,144 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DebugEntry is one request/response exchange captured by WithDebug. The
+// access token is redacted from the URL, headers and bodies.
+type DebugEntry struct {
+	Method         string
+	URL            string
+	RequestHeader  http.Header
+	RequestBody    []byte
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	Duration       time.Duration
+	Err            error
+}
+
+func (e DebugEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s\n", e.Method, e.URL)
+	keys := make([]string, 0, len(e.RequestHeader))
+	for k := range e.RequestHeader {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", k, strings.Join(e.RequestHeader[k], ", "))
+	}
+	if len(e.RequestBody) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", e.RequestBody)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, "<-- error after %s: %v\n", e.Duration, e.Err)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "<-- %d (%s)\n", e.StatusCode, e.Duration)
+	if len(e.ResponseBody) > 0 {
+		fmt.Fprintf(&b, "%s\n", e.ResponseBody)
+	}
+	return b.String()
+}
+
+// WithDebug writes every request and response the client exchanges with the
+// API to out.
+func WithDebug(out io.Writer) Option {
+	var mu sync.Mutex
+	return WithDebugFunc(func(e DebugEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		io.WriteString(out, e.String())
+	})
+}
+
+// WithDebugFunc passes every request and response the client exchanges with
+// the API to fn.
+func WithDebugFunc(fn func(DebugEntry)) Option {
+	return func(w *WhatsappClient) {
+		w.debug = fn
+		w.debugOn.Store(true)
+	}
+}
+
+// SetDebug pauses or resumes the capture installed with WithDebug.
+func (w *WhatsappClient) SetDebug(on bool) {
+	w.debugOn.Store(on && w.debug != nil)
+}
+
+func (w *WhatsappClient) Debug() bool {
+	return w.debugOn.Load()
+}
+
+type debugDoer struct {
+	next  HTTPDoer
+	fn    func(DebugEntry)
+	on    *atomic.Bool
+	token string
+}
+
+func (d *debugDoer) Do(req *http.Request) (*http.Response, error) {
+	if !d.on.Load() {
+		return d.next.Do(req)
+	}
+
+	entry := DebugEntry{
+		Method:        req.Method,
+		URL:           d.redact(req.URL.String()),
+		RequestHeader: req.Header.Clone(),
+	}
+	if entry.RequestHeader.Get("Authorization") != "" {
+		entry.RequestHeader.Set("Authorization", "Bearer "+redactToken(d.token))
+	}
+	if req.GetBody == nil && req.Body != nil && strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		entry.RequestBody = []byte("<streamed multipart body>")
+		if req.ContentLength >= 0 {
+			entry.RequestBody = []byte(fmt.Sprintf("<streamed multipart body, %d bytes>", req.ContentLength))
+		}
+	} else if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			if strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+				data = []byte(fmt.Sprintf("<multipart body, %d bytes>", len(data)))
+			}
+			entry.RequestBody = []byte(d.redact(string(data)))
+		}
+	}
+
+	start := time.Now()
+	resp, err := d.next.Do(req)
+	entry.Duration = time.Since(start)
+	entry.Err = err
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+		entry.ResponseHeader = resp.Header.Clone()
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if strings.Contains(resp.Header.Get("Content-Type"), "json") || len(data) < 4096 {
+			entry.ResponseBody = []byte(d.redact(string(data)))
+		} else {
+			entry.ResponseBody = []byte(fmt.Sprintf("<%s body, %d bytes>", resp.Header.Get("Content-Type"), len(data)))
+		}
+	}
+	d.fn(entry)
+	return resp, err
+}
+
+func (d *debugDoer) redact(s string) string {
+	if d.token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, d.token, redactToken(d.token))
+}
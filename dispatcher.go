@@ -0,0 +1,293 @@
+package whatsappdau
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// InboundMessage is an incoming message together with the sender's profile
+// and the business number it was sent to.
+type InboundMessage struct {
+	IncomingMessage
+	Contact  *WebhookContact `json:"contact,omitempty"`
+	Metadata WebhookMetadata `json:"metadata"`
+}
+
+// ContactName returns the sender's profile name, if the webhook carried one.
+func (m *InboundMessage) ContactName() string {
+	if m.Contact == nil {
+		return ""
+	}
+	return m.Contact.Profile.Name
+}
+
+type MessageHandler func(ctx context.Context, msg *InboundMessage) error
+
+type StatusHandler func(ctx context.Context, status StatusUpdate) error
+
+type EchoHandler func(ctx context.Context, echo *MessageEcho) error
+
+// WebhookFailure is an error a webhook reported. Message or Status is set
+// when the error belongs to one, otherwise it came with the value itself.
+type WebhookFailure struct {
+	Err      WebhookError
+	Message  *InboundMessage
+	Status   *StatusUpdate
+	Metadata WebhookMetadata
+}
+
+type ErrorHandler func(ctx context.Context, f WebhookFailure) error
+
+// Dispatcher turns webhook notifications into handler calls. It is also an
+// http.Handler serving both the verification handshake and deliveries.
+type Dispatcher struct {
+	// VerifyToken answers Meta's GET subscription challenge.
+	VerifyToken string
+	// AppSecret, when set, is used to check X-Hub-Signature-256 on deliveries.
+	AppSecret string
+	// Replay, when set, drops deliveries that were already processed or are
+	// too old.
+	Replay *ReplayGuard
+	// MaxBodySize caps the size of a delivery; larger ones are refused with
+	// 413. Zero means DefaultMaxWebhookBody.
+	MaxBodySize int64
+
+	messageHandlers []MessageHandler
+	statusHandlers  []StatusHandler
+	errorHandlers   []ErrorHandler
+	echoHandlers    []EchoHandler
+	middleware      []WebhookMiddleware
+}
+
+// DefaultMaxWebhookBody is the delivery size limit of a Dispatcher without
+// MaxBodySize. Meta batches notifications well below it.
+const DefaultMaxWebhookBody = 1 << 20
+
+func NewDispatcher(verifyToken, appSecret string) *Dispatcher {
+	return &Dispatcher{VerifyToken: verifyToken, AppSecret: appSecret}
+}
+
+// OnMessage registers h for every incoming message. Handlers run in
+// registration order.
+func (d *Dispatcher) OnMessage(h MessageHandler) {
+	d.messageHandlers = append(d.messageHandlers, h)
+}
+
+// OnStatus registers h for every outgoing message status update.
+func (d *Dispatcher) OnStatus(h StatusHandler) {
+	d.statusHandlers = append(d.statusHandlers, h)
+}
+
+// OnEcho registers h for messages sent from the WhatsApp Business app on the
+// same number, so they can join the conversation history. The app must be
+// subscribed to the smb_message_echoes webhook field.
+func (d *Dispatcher) OnEcho(h EchoHandler) {
+	d.echoHandlers = append(d.echoHandlers, h)
+}
+
+// OnError registers h for every error a webhook reports: on the value, on
+// an unsupported incoming message or on a failed status. Messages and
+// statuses still reach their own handlers too. Without error handlers the
+// errors are logged.
+func (d *Dispatcher) OnError(h ErrorHandler) {
+	d.errorHandlers = append(d.errorHandlers, h)
+}
+
+// OnReferral registers h for incoming messages that came from an ad or post,
+// i.e. carry a Referral. They reach the OnMessage handlers as well.
+func (d *Dispatcher) OnReferral(h MessageHandler) {
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		if msg.Referral == nil {
+			return nil
+		}
+		return h(ctx, msg)
+	})
+}
+
+// OnUnknown registers h for messages of a type the library doesn't model
+// yet, e.g. one Meta added recently. Their content is in msg.Raw. They reach
+// the OnMessage handlers as well.
+func (d *Dispatcher) OnUnknown(h MessageHandler) {
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		if msg.KnownType() {
+			return nil
+		}
+		return h(ctx, msg)
+	})
+}
+
+// OnSystem registers h for system messages, such as a user changing their
+// number (SystemUserChangedNumber) or their identity (SystemIdentityChanged).
+// They reach the OnMessage handlers as well.
+func (d *Dispatcher) OnSystem(h MessageHandler) {
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		if msg.System == nil {
+			return nil
+		}
+		return h(ctx, msg)
+	})
+}
+
+// Dispatch runs the registered handlers for everything in n, through any
+// middleware, and returns the handler errors joined together.
+func (d *Dispatcher) Dispatch(ctx context.Context, n *WebhookNotification) error {
+	return d.chain()(ctx, n)
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, n *WebhookNotification) error {
+	var errs []error
+	for _, entry := range n.Entry {
+		for _, change := range entry.Changes {
+			value := change.Value
+			for _, e := range value.Errors {
+				errs = append(errs, d.dispatchError(ctx, WebhookFailure{Err: e, Metadata: value.Metadata})...)
+			}
+			for i := range value.Messages {
+				msg := &InboundMessage{
+					IncomingMessage: value.Messages[i],
+					Contact:         findContact(value.Contacts, value.Messages[i].From),
+					Metadata:        value.Metadata,
+				}
+				for _, h := range d.messageHandlers {
+					if err := h(ctx, msg); err != nil {
+						errs = append(errs, err)
+					}
+				}
+				for _, e := range msg.Errors {
+					errs = append(errs, d.dispatchError(ctx, WebhookFailure{Err: e, Message: msg, Metadata: value.Metadata})...)
+				}
+			}
+			for i := range value.MessageEchoes {
+				for _, h := range d.echoHandlers {
+					if err := h(ctx, &value.MessageEchoes[i]); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			}
+			for i, status := range value.Statuses {
+				for _, h := range d.statusHandlers {
+					if err := h(ctx, status); err != nil {
+						errs = append(errs, err)
+					}
+				}
+				for _, e := range status.Errors {
+					errs = append(errs, d.dispatchError(ctx, WebhookFailure{Err: e, Status: &value.Statuses[i], Metadata: value.Metadata})...)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) dispatchError(ctx context.Context, f WebhookFailure) []error {
+	if len(d.errorHandlers) == 0 {
+		logger().Warn("webhook reported error", "code", f.Err.Code, "title", f.Err.Title, "message", f.Err.Message)
+		return nil
+	}
+	var errs []error
+	for _, h := range d.errorHandlers {
+		if err := h(ctx, f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func findContact(contacts []WebhookContact, waID string) *WebhookContact {
+	for i := range contacts {
+		if contacts[i].WaID == waID {
+			return &contacts[i]
+		}
+	}
+	if len(contacts) == 1 {
+		return &contacts[0]
+	}
+	return nil
+}
+
+func (d *Dispatcher) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		d.serveVerification(rw, r)
+	case http.MethodPost:
+		d.serveDelivery(rw, r)
+	default:
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (d *Dispatcher) serveVerification(rw http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("hub.mode") != "subscribe" || d.VerifyToken == "" || q.Get("hub.verify_token") != d.VerifyToken {
+		http.Error(rw, "forbidden", http.StatusForbidden)
+		return
+	}
+	io.WriteString(rw, q.Get("hub.challenge"))
+}
+
+func (d *Dispatcher) serveDelivery(rw http.ResponseWriter, r *http.Request) {
+	limit := d.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxWebhookBody
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(rw, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(rw, "bad request", http.StatusBadRequest)
+		return
+	}
+	if d.AppSecret != "" && !ValidSignature(body, r.Header.Get("X-Hub-Signature-256"), d.AppSecret) {
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	notification, err := ParseWebhook(body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if d.Replay != nil {
+		err := d.Replay.Check(r.Context(), body, notification)
+		switch {
+		case errors.Is(err, ErrReplayedDelivery):
+			// Already handled; acknowledge so a genuine redelivery stops.
+			rw.WriteHeader(http.StatusOK)
+			return
+		case err != nil:
+			http.Error(rw, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	// Meta retries anything but a 200, so handler failures are logged rather
+	// than reported back.
+	ctx := context.WithValue(r.Context(), webhookRequestKey{}, r)
+	if err := d.Dispatch(ctx, notification); err != nil {
+		logger().Error("webhook handler failed", "error", err)
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// ValidSignature checks an X-Hub-Signature-256 header against body.
+func ValidSignature(body []byte, header, appSecret string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
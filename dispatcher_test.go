@@ -0,0 +1,102 @@
+package whatsappdau
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testDelivery = `{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{
+	"messaging_product":"whatsapp","metadata":{"phone_number_id":"100"},
+	"messages":[{"from":"15551234567","id":"wamid.1","timestamp":"1700000000","type":"text","text":{"body":"hi"}}]}}]}]}`
+
+func TestDispatcherServeDelivery(t *testing.T) {
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		maxBody   int64
+		want      int
+		handled   bool
+	}{
+		{name: "signed", body: testDelivery, signature: sign(testDelivery), want: http.StatusOK, handled: true},
+		{name: "bad signature", body: testDelivery, signature: sign("other"), want: http.StatusUnauthorized},
+		{name: "not json", body: "nope", signature: sign("nope"), want: http.StatusBadRequest},
+		{name: "over the limit", body: testDelivery, signature: sign(testDelivery), maxBody: 64, want: http.StatusRequestEntityTooLarge},
+		{name: "over the default limit", body: strings.Repeat(" ", DefaultMaxWebhookBody) + testDelivery, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDispatcher("", "secret")
+			d.MaxBodySize = tt.maxBody
+			handled := false
+			d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+				handled = msg.From == "15551234567"
+				return nil
+			})
+
+			req := httptest.NewRequest("POST", "/webhook", strings.NewReader(tt.body))
+			req.Header.Set("X-Hub-Signature-256", tt.signature)
+			rw := httptest.NewRecorder()
+			d.ServeHTTP(rw, req)
+
+			if rw.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rw.Code, tt.want, rw.Body)
+			}
+			if handled != tt.handled {
+				t.Errorf("handled = %v, want %v", handled, tt.handled)
+			}
+		})
+	}
+}
+
+func TestDispatcherContactsAndOrders(t *testing.T) {
+	const delivery = `{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{
+	"messaging_product":"whatsapp","metadata":{"phone_number_id":"100"},
+	"messages":[
+		{"from":"15551234567","id":"wamid.1","timestamp":"1700000000","type":"contacts","contacts":[{"name":{"formatted_name":"John Smith"},"phones":[{"phone":"+1 940 555 1234","wa_id":"19405551234"}]}]},
+		{"from":"15551234567","id":"wamid.2","timestamp":"1700000001","type":"order","order":{"catalog_id":"42","product_items":[{"product_retailer_id":"sku-1","quantity":3,"item_price":9.99,"currency":"USD"}]}}
+	]}}]}]}`
+	notification, err := ParseWebhook([]byte(delivery))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher("", "")
+	var messages []*InboundMessage
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	d.OnUnknown(func(ctx context.Context, msg *InboundMessage) error {
+		t.Errorf("%s message reached OnUnknown", msg.Type)
+		return nil
+	})
+	if err := d.Dispatch(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("dispatched %d messages, want 2", len(messages))
+	}
+	if c := messages[0].Contacts; len(c) != 1 || c[0].Name.FormattedName != "John Smith" || c[0].Phones[0].WaID != "19405551234" {
+		t.Errorf("contacts = %+v", c)
+	}
+	order := messages[1].Order
+	if order == nil || order.CatalogID != "42" || len(order.ProductItems) != 1 {
+		t.Fatalf("order = %+v", order)
+	}
+	if item := order.ProductItems[0]; item.Quantity != "3" || item.ItemPrice != "9.99" {
+		t.Errorf("item = %+v, want quantity 3 at 9.99", item)
+	}
+}
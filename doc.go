@@ -0,0 +1,27 @@
+// Package whatsappdau is a client for the WhatsApp Cloud API.
+//
+// The package itself only depends on the standard library. Building with
+//
+//	go build -tags whatsappdau_minimal
+//
+// leaves out the optional pieces a send-only service does not need: CSV and
+// XLSX recipient import, outcome exporters, the HubSpot CRM adapter and the
+// OS keyring lookup. Adapters that need third-party clients (Redis, SQL,
+// message brokers, object storage, OpenTelemetry) or libraries (scrypt for
+// the encrypted token file in filetokenwhatsappdau) live in their own modules
+// under this repository, so importing the core never pulls those
+// dependencies in. They require a published version of the core; to change
+// both at once, work in an uncommitted workspace that points the version the
+// adapters require at this checkout:
+//
+//	go work init . ./rediswhatsappdau ./kafkawhatsappdau ./natswhatsappdau \
+//		./otelwhatsappdau ./promwhatsappdau ./s3whatsappdau ./filetokenwhatsappdau
+//	go work edit -replace github.com/daulet140/whatsappdau@<version>=.
+//
+// The adapter modules all declare go 1.25.0, the oldest release their
+// dependencies (OpenTelemetry, the Prometheus client, minio-go) support.
+//
+// The send path needs no file system: use UploadMedia, SendAudioFrom and
+// SendImageFrom with an io.Reader, and WithHTTPDoer to plug in the runtime's
+// HTTP client, to run under WASM or TinyGo.
+package whatsappdau
@@ -0,0 +1,71 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// WithDryRun makes the client validate and marshal every request as usual but
+// answer it locally instead of calling the API. Sends succeed with synthetic
+// "wamid.DRYRUN..." message IDs, uploads with "dryrun-media-..." IDs, and
+// other calls with an empty JSON object. Combine with WithDebug to see what
+// would have been sent.
+func WithDryRun() Option {
+	return func(w *WhatsappClient) {
+		w.dryRun = true
+	}
+}
+
+type dryRunDoer struct {
+	seq atomic.Int64
+}
+
+func (d *dryRunDoer) Do(req *http.Request) (*http.Response, error) {
+	var body interface{} = struct{}{}
+	path := strings.TrimRight(req.URL.Path, "/")
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/messages"):
+		var payload struct {
+			To     string `json:"to"`
+			Status string `json:"status"`
+		}
+		if req.Body != nil {
+			json.NewDecoder(req.Body).Decode(&payload)
+		}
+		if payload.Status == "read" {
+			body = map[string]bool{"success": true}
+			break
+		}
+		body = MessageResponse{
+			MessagingProduct: "whatsapp",
+			Contacts:         []Contacts{{Input: payload.To, WaId: normalizeWAID(payload.To)}},
+			Messages:         []Messages{{Id: fmt.Sprintf("wamid.DRYRUN%06d", d.seq.Add(1))}},
+		}
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/media"):
+		body = map[string]string{"id": fmt.Sprintf("dryrun-media-%d", d.seq.Add(1))}
+	case req.Method != http.MethodGet:
+		body = map[string]bool{"success": true}
+	}
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	data, _ := json.Marshal(body)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
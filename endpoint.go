@@ -0,0 +1,72 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	DefaultBaseURL    = "https://graph.facebook.com"
+	DefaultAPIVersion = "v24.0"
+)
+
+// Endpoint locates the Cloud API and the phone number a client sends from.
+// Empty BaseURL and Version fall back to the defaults above.
+type Endpoint struct {
+	BaseURL       string
+	Version       string
+	PhoneNumberID string
+}
+
+// ParseEndpoint splits a messages URL of the form
+// {base}/{version}/{phone-number-id}/messages.
+func ParseEndpoint(apiURL string) (Endpoint, error) {
+	trimmed := strings.TrimSuffix(strings.TrimRight(apiURL, "/"), "/messages")
+	parts := strings.Split(trimmed, "/")
+	// scheme, "", host, version, phone number id
+	if len(parts) < 5 || parts[len(parts)-1] == "" || parts[len(parts)-2] == "" {
+		return Endpoint{}, fmt.Errorf("cannot parse API URL %q, want {base}/{version}/{phone-number-id}/messages", apiURL)
+	}
+	return Endpoint{
+		BaseURL:       strings.Join(parts[:len(parts)-2], "/"),
+		Version:       parts[len(parts)-2],
+		PhoneNumberID: parts[len(parts)-1],
+	}, nil
+}
+
+// GraphURL is the versioned API root, e.g. https://graph.facebook.com/v24.0.
+func (e Endpoint) GraphURL() string {
+	base, version := strings.TrimRight(e.BaseURL, "/"), e.Version
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+	return base + "/" + version
+}
+
+// NodeURL is the URL of a Graph object such as a media ID.
+func (e Endpoint) NodeURL(id string) string {
+	return e.GraphURL() + "/" + id
+}
+
+func (e Endpoint) PhoneNumberURL() string {
+	return e.NodeURL(e.PhoneNumberID)
+}
+
+func (e Endpoint) MessagesURL() string {
+	return e.PhoneNumberURL() + "/messages"
+}
+
+func (e Endpoint) MediaURL() string {
+	return e.PhoneNumberURL() + "/media"
+}
+
+// NewWhatsappClientWithEndpoint is NewWhatsappClient taking the endpoint in
+// parts rather than as a messages URL.
+func NewWhatsappClientWithEndpoint(ctx context.Context, endpoint Endpoint, accessToken string, client *http.Client, opts ...Option) Whatsapp {
+	return NewWhatsappClient(ctx, endpoint.MessagesURL(), accessToken, client, opts...)
+}
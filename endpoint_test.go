@@ -0,0 +1,36 @@
+package whatsappdau
+
+import "testing"
+
+func TestEndpointURLs(t *testing.T) {
+	tests := []struct {
+		endpoint Endpoint
+		want     string
+	}{
+		{Endpoint{PhoneNumberID: "123"}, "https://graph.facebook.com/" + DefaultAPIVersion + "/123/messages"},
+		{Endpoint{BaseURL: "http://localhost:8080/", Version: "v23.0", PhoneNumberID: "123"}, "http://localhost:8080/v23.0/123/messages"},
+	}
+	for _, tt := range tests {
+		if got := tt.endpoint.MessagesURL(); got != tt.want {
+			t.Errorf("MessagesURL() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    Endpoint
+		wantErr bool
+	}{
+		{"https://graph.facebook.com/v24.0/123/messages", Endpoint{BaseURL: "https://graph.facebook.com", Version: "v24.0", PhoneNumberID: "123"}, false},
+		{"http://localhost:8080/prefix/v23.0/123/messages/", Endpoint{BaseURL: "http://localhost:8080/prefix", Version: "v23.0", PhoneNumberID: "123"}, false},
+		{"https://graph.facebook.com/messages", Endpoint{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEndpoint(tt.url)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEndpoint(%q) = %+v, %v; want %+v", tt.url, got, err, tt.want)
+		}
+	}
+}
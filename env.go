@@ -0,0 +1,86 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewWhatsappClientFromEnv.
+const (
+	EnvAccessToken   = "WHATSAPP_ACCESS_TOKEN"
+	EnvPhoneNumberID = "WHATSAPP_PHONE_NUMBER_ID"
+	EnvAPIVersion    = "WHATSAPP_API_VERSION"
+	EnvBaseURL       = "WHATSAPP_BASE_URL"
+	EnvTimeout       = "WHATSAPP_TIMEOUT"
+	EnvMaxAttempts   = "WHATSAPP_MAX_ATTEMPTS"
+	EnvDryRun        = "WHATSAPP_DRY_RUN"
+)
+
+// DefaultEnvTimeout is the per-call timeout of clients from
+// NewWhatsappClientFromEnv when WHATSAPP_TIMEOUT is unset.
+const DefaultEnvTimeout = 30 * time.Second
+
+// NewWhatsappClientFromEnv builds a client from the environment:
+//
+//	WHATSAPP_ACCESS_TOKEN     access token (required)
+//	WHATSAPP_PHONE_NUMBER_ID  sending phone number ID (required)
+//	WHATSAPP_API_VERSION      Graph API version, default DefaultAPIVersion
+//	WHATSAPP_BASE_URL         Graph API base URL, default DefaultBaseURL
+//	WHATSAPP_TIMEOUT          per-call timeout such as "10s", default 30s
+//	WHATSAPP_MAX_ATTEMPTS     tries per call under DefaultRetryPolicy, default 4; 1 disables retries
+//	WHATSAPP_DRY_RUN          "true" logs sends instead of making them
+//
+// opts are applied after the settings from the environment, so they win.
+func NewWhatsappClientFromEnv(ctx context.Context, opts ...Option) (Whatsapp, error) {
+	token := os.Getenv(EnvAccessToken)
+	phoneID := os.Getenv(EnvPhoneNumberID)
+	var missing []string
+	if token == "" {
+		missing = append(missing, EnvAccessToken)
+	}
+	if phoneID == "" {
+		missing = append(missing, EnvPhoneNumberID)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s must be set", strings.Join(missing, " and "))
+	}
+
+	timeout := DefaultEnvTimeout
+	if v := os.Getenv(EnvTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: %q is not a duration", EnvTimeout, v)
+		}
+		timeout = d
+	}
+	retry := DefaultRetryPolicy()
+	if v := os.Getenv(EnvMaxAttempts); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%s: %q is not a positive number", EnvMaxAttempts, v)
+		}
+		retry.MaxAttempts = n
+	}
+	envOpts := []Option{WithTimeouts(Timeouts{Default: timeout}), WithRetryPolicy(retry)}
+	if v := os.Getenv(EnvDryRun); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New(EnvDryRun + ": want true or false")
+		}
+		if dryRun {
+			envOpts = append(envOpts, WithDryRun())
+		}
+	}
+
+	endpoint := Endpoint{
+		BaseURL:       os.Getenv(EnvBaseURL),
+		Version:       os.Getenv(EnvAPIVersion),
+		PhoneNumberID: phoneID,
+	}
+	return NewWhatsappClientWithEndpoint(ctx, endpoint, token, nil, append(envOpts, opts...)...), nil
+}
@@ -0,0 +1,129 @@
+package whatsappdau
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Cloud API error codes, as found in APIError.Code and WebhookError.Code.
+const (
+	ErrCodeInvalidParameter     = 100
+	ErrCodeTokenExpired         = 190
+	ErrCodeAppRateLimit         = 4
+	ErrCodeAccountRateLimit     = 80007
+	ErrCodeThroughputExceeded   = 130429
+	ErrCodeUndeliverable        = 131026
+	ErrCodeReengagementRequired = 131047
+	ErrCodeSpamRateLimit        = 131048
+	ErrCodePairRateLimit        = 131056
+	ErrCodeUnsupportedMessage   = 131051
+	ErrCodeMediaDownload        = 131052
+	ErrCodeTemplateNotFound     = 132001
+)
+
+// Sentinel errors an *APIError matches with errors.Is, e.g.
+// errors.Is(err, ErrReengagementRequired).
+var (
+	ErrInvalidParameter     = errors.New("whatsappdau: invalid parameter")
+	ErrTokenExpired         = errors.New("whatsappdau: access token expired or invalid")
+	ErrUndeliverable        = errors.New("whatsappdau: message undeliverable")
+	ErrReengagementRequired = errors.New("whatsappdau: more than 24 hours since the customer last replied")
+	ErrTemplateNotFound     = errors.New("whatsappdau: template does not exist")
+	// ErrRateLimited matches every throttling code; retry later.
+	ErrRateLimited = errors.New("whatsappdau: rate limited")
+	// ErrPairRateLimited is sending to the same recipient too fast.
+	ErrPairRateLimited = errors.New("whatsappdau: too many messages to this recipient")
+)
+
+var errorCodes = map[int][]error{
+	ErrCodeInvalidParameter:     {ErrInvalidParameter},
+	ErrCodeTokenExpired:         {ErrTokenExpired},
+	ErrCodeUndeliverable:        {ErrUndeliverable},
+	ErrCodeReengagementRequired: {ErrReengagementRequired},
+	ErrCodeTemplateNotFound:     {ErrTemplateNotFound},
+	ErrCodeAppRateLimit:         {ErrRateLimited},
+	ErrCodeAccountRateLimit:     {ErrRateLimited},
+	ErrCodeThroughputExceeded:   {ErrRateLimited},
+	ErrCodeSpamRateLimit:        {ErrRateLimited},
+	ErrCodePairRateLimit:        {ErrRateLimited, ErrPairRateLimited},
+}
+
+// APIError is the error envelope the Graph API returns with non-2xx responses.
+type APIError struct {
+	StatusCode   int    `json:"-"`
+	Message      string `json:"message"`
+	Type         string `json:"type"`
+	Code         int    `json:"code"`
+	ErrorSubcode int    `json:"error_subcode,omitempty"`
+	ErrorData    struct {
+		MessagingProduct string `json:"messaging_product,omitempty"`
+		Details          string `json:"details,omitempty"`
+	} `json:"error_data"`
+	FBTraceID string `json:"fbtrace_id,omitempty"`
+	Body      string `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("whatsapp api: status %d: %s", e.StatusCode, e.Body)
+	}
+	msg := fmt.Sprintf("whatsapp api: status %d: code %d: %s", e.StatusCode, e.Code, e.Message)
+	if e.ErrorData.Details != "" {
+		msg += ": " + e.ErrorData.Details
+	}
+	return msg
+}
+
+// Is matches the sentinel errors above by error code.
+func (e *APIError) Is(target error) bool {
+	for _, err := range errorCodes[e.Code] {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+	var envelope struct {
+		Error *APIError `json:"error"`
+	}
+	apiErr := &APIError{}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		apiErr = envelope.Error
+	}
+	apiErr.StatusCode = statusCode
+	apiErr.Body = string(body)
+	return apiErr
+}
+
+// WebhookError is an entry of the errors array a webhook carries on a value,
+// an incoming message or a failed status, e.g. ErrCodeUnsupportedMessage or
+// ErrCodeMediaDownload. It matches the sentinel errors like APIError does.
+type WebhookError struct {
+	Code      int    `json:"code"`
+	Title     string `json:"title"`
+	Message   string `json:"message,omitempty"`
+	ErrorData struct {
+		Details string `json:"details,omitempty"`
+	} `json:"error_data"`
+	Href string `json:"href,omitempty"`
+}
+
+func (e *WebhookError) Error() string {
+	msg := fmt.Sprintf("whatsapp webhook: code %d: %s", e.Code, e.Title)
+	if e.ErrorData.Details != "" {
+		msg += ": " + e.ErrorData.Details
+	}
+	return msg
+}
+
+func (e *WebhookError) Is(target error) bool {
+	for _, err := range errorCodes[e.Code] {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package whatsappdau
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		code    int
+		message string
+	}{
+		{"graph envelope", http.StatusBadRequest, `{"error":{"message":"Re-engagement message","type":"OAuthException","code":131047,"fbtrace_id":"A1"}}`, ErrCodeReengagementRequired, "Re-engagement message"},
+		{"not json", http.StatusBadGateway, `<html>bad gateway</html>`, 0, ""},
+		{"no error field", http.StatusInternalServerError, `{"success":false}`, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(tt.status, []byte(tt.body))
+			if err.StatusCode != tt.status || err.Code != tt.code || err.Message != tt.message || err.Body != tt.body {
+				t.Errorf("got %+v", err)
+			}
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	sentinels := []error{
+		ErrInvalidParameter, ErrTokenExpired, ErrUndeliverable, ErrReengagementRequired,
+		ErrTemplateNotFound, ErrRateLimited, ErrPairRateLimited, ErrSendLimitReached, ErrValidation,
+	}
+	tests := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{"invalid parameter", &APIError{Code: ErrCodeInvalidParameter}, []error{ErrInvalidParameter}},
+		{"token expired", &APIError{Code: ErrCodeTokenExpired}, []error{ErrTokenExpired}},
+		{"undeliverable", &APIError{Code: ErrCodeUndeliverable}, []error{ErrUndeliverable}},
+		{"re-engagement", &APIError{Code: ErrCodeReengagementRequired}, []error{ErrReengagementRequired}},
+		{"template not found", &APIError{Code: ErrCodeTemplateNotFound}, []error{ErrTemplateNotFound}},
+		{"app rate limit", &APIError{Code: ErrCodeAppRateLimit}, []error{ErrRateLimited}},
+		{"account rate limit", &APIError{Code: ErrCodeAccountRateLimit}, []error{ErrRateLimited}},
+		{"throughput", &APIError{Code: ErrCodeThroughputExceeded}, []error{ErrRateLimited}},
+		{"spam rate limit", &APIError{Code: ErrCodeSpamRateLimit}, []error{ErrRateLimited}},
+		{"pair rate limit", &APIError{Code: ErrCodePairRateLimit}, []error{ErrRateLimited, ErrPairRateLimited}},
+		{"unknown code", &APIError{Code: 1}, nil},
+		{"wrapped", fmt.Errorf("send: %w", &APIError{Code: ErrCodeReengagementRequired}), []error{ErrReengagementRequired}},
+		{"webhook", &WebhookError{Code: ErrCodeReengagementRequired}, []error{ErrReengagementRequired}},
+		{"webhook pair rate limit", &WebhookError{Code: ErrCodePairRateLimit}, []error{ErrRateLimited, ErrPairRateLimited}},
+		{"webhook unsupported", &WebhookError{Code: ErrCodeUnsupportedMessage}, nil},
+		{"limit", &LimitError{Limit: 250, RetryAt: time.Now()}, []error{ErrSendLimitReached}},
+		{"validation", &ValidationError{Field: "to", Reason: "empty"}, []error{ErrValidation}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, sentinel := range sentinels {
+				want := false
+				for _, w := range tt.want {
+					want = want || w == sentinel
+				}
+				if got := errors.Is(tt.err, sentinel); got != want {
+					t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, sentinel, got, want)
+				}
+			}
+		})
+	}
+}
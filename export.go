@@ -0,0 +1,49 @@
+//go:build !whatsappdau_minimal
+
+package whatsappdau
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+var outcomeCSVHeader = []string{"recipient", "message_id", "status", "error", "sent_at", "updated_at"}
+
+func ExportOutcomesCSV(w io.Writer, outcomes []MessageOutcome) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(outcomeCSVHeader); err != nil {
+		return err
+	}
+	for _, o := range outcomes {
+		record := []string{o.Recipient, o.MessageID, o.Status, o.Error, formatOutcomeTime(o.SentAt), formatOutcomeTime(o.UpdatedAt)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func ExportOutcomesJSONL(w io.Writer, outcomes []MessageOutcome) error {
+	enc := json.NewEncoder(w)
+	for _, o := range outcomes {
+		line := struct {
+			MessageOutcome
+			SentAt    string `json:"sent_at,omitempty"`
+			UpdatedAt string `json:"updated_at,omitempty"`
+		}{o, formatOutcomeTime(o.SentAt), formatOutcomeTime(o.UpdatedAt)}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatOutcomeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
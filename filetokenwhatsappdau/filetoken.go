@@ -0,0 +1,123 @@
+// Package filetokenwhatsappdau keeps the Cloud API access token in a
+// passphrase-encrypted file. It is a separate module so the core package
+// stays free of the golang.org/x/crypto dependency.
+//
+//	creds := whatsappdau.NewCredentials(
+//		whatsappdau.EnvToken("WHATSAPP_TOKEN"),
+//		filetokenwhatsappdau.Token{Path: tokenPath, Passphrase: pass},
+//	)
+package filetokenwhatsappdau
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/daulet140/whatsappdau"
+	"golang.org/x/crypto/scrypt"
+)
+
+// fileMagic starts a token file, followed by the scrypt salt, the GCM nonce
+// and the sealed token.
+const fileMagic = "WDT1"
+
+const saltSize = 16
+
+// scrypt parameters for token files, the RFC 7914 recommendation for
+// interactive use: 32MB and around 100ms per derivation.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Token reads a token file written by Write. The file is AES-256-GCM
+// encrypted with a key derived from Passphrase by scrypt, with a random salt
+// stored in the file.
+type Token struct {
+	Path       string
+	Passphrase []byte
+}
+
+func (t Token) Token(ctx context.Context) (string, error) {
+	data, err := os.ReadFile(t.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s does not exist", whatsappdau.ErrNoToken, t.Path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	if !bytes.HasPrefix(data, []byte(fileMagic)) {
+		return "", errors.New("token file has an unsupported format; write it again with Write")
+	}
+	data = data[len(fileMagic):]
+	if len(data) < saltSize {
+		return "", errors.New("token file is truncated")
+	}
+	gcm, err := newCipher(t.Passphrase, data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("token file is truncated")
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt token file: %w", err)
+	}
+	defer wipe(plain)
+	return string(plain), nil
+}
+
+// Write stores token at path, readable back with Token and the same
+// passphrase. The file is created with mode 0600.
+func Write(path string, passphrase []byte, token string) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := newCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out := append([]byte(fileMagic), salt...)
+	out = append(out, nonce...)
+	out = gcm.Seal(out, nonce, []byte(token), nil)
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+func newCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("token passphrase is empty")
+	}
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,70 @@
+package filetokenwhatsappdau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+func TestTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := Write(path, []byte("secret"), "EAAB-token"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(fileMagic)) || bytes.Contains(data, []byte("EAAB")) {
+		t.Fatalf("unexpected file contents %x", data)
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		mutate     func([]byte) []byte
+		want       string
+		wantErr    string
+	}{
+		{"round trip", "secret", nil, "EAAB-token", ""},
+		{"wrong passphrase", "guess", nil, "", "decrypt"},
+		{"tampered salt", "secret", func(b []byte) []byte { b[len(fileMagic)] ^= 1; return b }, "", "decrypt"},
+		{"truncated", "secret", func(b []byte) []byte { return b[:len(fileMagic)+4] }, "", "truncated"},
+		{"no header", "secret", func(b []byte) []byte { return b[len(fileMagic):] }, "", "unsupported format"},
+		{"empty passphrase", "", nil, "", "passphrase is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := path
+			if tt.mutate != nil {
+				p = filepath.Join(t.TempDir(), "token")
+				if err := os.WriteFile(p, tt.mutate(append([]byte(nil), data...)), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := Token{Path: p, Passphrase: []byte(tt.passphrase)}.Token(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %q, %v; want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenMissingFile(t *testing.T) {
+	_, err := Token{Path: filepath.Join(t.TempDir(), "missing"), Passphrase: []byte("secret")}.Token(context.Background())
+	if !errors.Is(err, whatsappdau.ErrNoToken) {
+		t.Errorf("got %v, want ErrNoToken", err)
+	}
+}
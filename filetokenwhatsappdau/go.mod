@@ -0,0 +1,8 @@
+module github.com/daulet140/whatsappdau/filetokenwhatsappdau
+
+go 1.25.0
+
+require (
+	github.com/daulet140/whatsappdau v0.0.0-20261015114746-210ef37890e8
+	golang.org/x/crypto v0.55.0
+)
@@ -0,0 +1,56 @@
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// callGraph sends payload, if any, as JSON to url and decodes the response
+// into out, which may be nil.
+func (w *WhatsappClient) callGraph(ctx context.Context, method, url string, payload, out interface{}) error {
+	var req *http.Request
+	var err error
+	if payload == nil {
+		req, err = http.NewRequestWithContext(ctx, method, url, nil)
+	} else {
+		body, encErr := encodeJSON(payload)
+		if encErr != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", encErr)
+		}
+		defer body.release()
+		req, err = body.newRequest(ctx, method, url)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.accessToken))
+	return w.doJSON(req, out)
+}
+
+// doJSON performs req and decodes a successful JSON response into out, which
+// may be nil. Non-2xx responses are returned as *APIError.
+func (w *WhatsappClient) doJSON(req *http.Request, out interface{}) error {
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	defer putBuffer(body)
+	if resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, body.Bytes())
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body.Bytes(), out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoHandoff     = errors.New("whatsappdau: conversation is not in handoff")
+	ErrUnknownAgent  = errors.New("whatsappdau: unknown agent")
+	ErrAgentAtLimit  = errors.New("whatsappdau: agent is at capacity")
+	ErrNothingQueued = errors.New("whatsappdau: no queued conversation matches")
+)
+
+// Agent is a human who can take over conversations. MaxActive of 0 means no
+// limit.
+type Agent struct {
+	ID        string
+	Skills    []string
+	MaxActive int
+}
+
+func (a Agent) hasSkills(skills []string) bool {
+	for _, s := range skills {
+		if !containsString(a.Skills, s) {
+			return false
+		}
+	}
+	return true
+}
+
+type AssignmentStrategy int
+
+const (
+	RoundRobin AssignmentStrategy = iota
+	LeastLoaded
+)
+
+// Assignment is a conversation in handoff. AgentID is empty while it waits
+// in the queue.
+type Assignment struct {
+	WaID    string
+	AgentID string
+	Skills  []string
+	Since   time.Time
+}
+
+// HandoffManager tracks conversations handed from the bot to human agents.
+// New handoffs go to an agent having every required skill, picked by
+// Strategy, or wait in a queue until one frees up or picks it up.
+type HandoffManager struct {
+	Strategy AssignmentStrategy
+	// OnMessage receives inbound messages of conversations in handoff, for
+	// relaying to the agent console. agentID is empty for queued ones.
+	OnMessage func(ctx context.Context, agentID string, msg *InboundMessage) error
+
+	mu     sync.Mutex
+	agents []Agent
+	active map[string]*Assignment
+	queue  []*Assignment
+	next   int
+}
+
+func NewHandoffManager(strategy AssignmentStrategy, agents ...Agent) *HandoffManager {
+	return &HandoffManager{
+		Strategy: strategy,
+		agents:   agents,
+		active:   make(map[string]*Assignment),
+	}
+}
+
+// Wrap returns a handler that passes messages to bot unless the conversation
+// is in handoff, in which case they go to OnMessage.
+func (h *HandoffManager) Wrap(bot MessageHandler) MessageHandler {
+	return func(ctx context.Context, msg *InboundMessage) error {
+		h.mu.Lock()
+		a, ok := h.active[msg.From]
+		var agentID string
+		if ok {
+			agentID = a.AgentID
+		}
+		h.mu.Unlock()
+
+		if !ok {
+			return bot(ctx, msg)
+		}
+		if h.OnMessage != nil {
+			return h.OnMessage(ctx, agentID, msg)
+		}
+		return nil
+	}
+}
+
+func (h *HandoffManager) AddAgent(agent Agent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.agents = append(h.agents, agent)
+	h.drainQueue()
+}
+
+// RemoveAgent takes the agent out of rotation and requeues their
+// conversations.
+func (h *HandoffManager) RemoveAgent(agentID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, a := range h.agents {
+		if a.ID == agentID {
+			h.agents = append(h.agents[:i], h.agents[i+1:]...)
+			break
+		}
+	}
+	for _, a := range h.active {
+		if a.AgentID == agentID {
+			a.AgentID = ""
+			h.queue = append(h.queue, a)
+		}
+	}
+	h.drainQueue()
+}
+
+// Start puts the conversation into handoff and assigns it if an agent is
+// available. Starting an active handoff returns its current assignment.
+func (h *HandoffManager) Start(waID string, skills ...string) Assignment {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if a, ok := h.active[waID]; ok {
+		return *a
+	}
+
+	a := &Assignment{WaID: waID, Skills: skills, Since: time.Now()}
+	h.active[waID] = a
+	if agent := h.pick(skills); agent != nil {
+		a.AgentID = agent.ID
+	} else {
+		h.queue = append(h.queue, a)
+	}
+	return *a
+}
+
+// End hands the conversation back to the bot.
+func (h *HandoffManager) End(waID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.active[waID]; !ok {
+		return
+	}
+	delete(h.active, waID)
+	h.removeQueued(waID)
+	h.drainQueue()
+}
+
+func (h *HandoffManager) Active(waID string) (Assignment, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	a, ok := h.active[waID]
+	if !ok {
+		return Assignment{}, false
+	}
+	return *a, true
+}
+
+// Reassign moves a conversation to agentID regardless of strategy, skills or
+// capacity. The agent it leaves may then take a queued conversation.
+// Reassigning to the current agent changes nothing.
+func (h *HandoffManager) Reassign(waID, agentID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	a, ok := h.active[waID]
+	if !ok {
+		return ErrNoHandoff
+	}
+	if h.agent(agentID) == nil {
+		return ErrUnknownAgent
+	}
+	if a.AgentID == agentID {
+		return nil
+	}
+	if a.AgentID == "" {
+		h.removeQueued(waID)
+	}
+	a.AgentID = agentID
+	a.Since = time.Now()
+	h.drainQueue()
+	return nil
+}
+
+// PickUp lets an agent claim a queued conversation: waID, or when empty the
+// longest waiting one whose skills the agent has.
+func (h *HandoffManager) PickUp(agentID, waID string) (Assignment, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	agent := h.agent(agentID)
+	if agent == nil {
+		return Assignment{}, ErrUnknownAgent
+	}
+	if !h.hasCapacity(*agent) {
+		return Assignment{}, ErrAgentAtLimit
+	}
+
+	for _, a := range h.queue {
+		if (waID == "" && agent.hasSkills(a.Skills)) || a.WaID == waID {
+			h.removeQueued(a.WaID)
+			a.AgentID = agentID
+			a.Since = time.Now()
+			return *a, nil
+		}
+	}
+	if waID != "" {
+		return Assignment{}, fmt.Errorf("%w: %s", ErrNothingQueued, waID)
+	}
+	return Assignment{}, ErrNothingQueued
+}
+
+func (h *HandoffManager) Queue() []Assignment {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	queue := make([]Assignment, len(h.queue))
+	for i, a := range h.queue {
+		queue[i] = *a
+	}
+	return queue
+}
+
+// Assignments returns the conversations currently held by agents.
+func (h *HandoffManager) Assignments() []Assignment {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var assigned []Assignment
+	for _, a := range h.active {
+		if a.AgentID != "" {
+			assigned = append(assigned, *a)
+		}
+	}
+	return assigned
+}
+
+// Load returns the number of conversations assigned to each agent.
+func (h *HandoffManager) Load() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	load := make(map[string]int, len(h.agents))
+	for _, agent := range h.agents {
+		load[agent.ID] = h.load(agent.ID)
+	}
+	return load
+}
+
+func (h *HandoffManager) agent(id string) *Agent {
+	for i := range h.agents {
+		if h.agents[i].ID == id {
+			return &h.agents[i]
+		}
+	}
+	return nil
+}
+
+func (h *HandoffManager) load(agentID string) int {
+	n := 0
+	for _, a := range h.active {
+		if a.AgentID == agentID {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *HandoffManager) hasCapacity(agent Agent) bool {
+	return agent.MaxActive == 0 || h.load(agent.ID) < agent.MaxActive
+}
+
+// pick chooses an agent for a conversation needing skills, or nil.
+func (h *HandoffManager) pick(skills []string) *Agent {
+	var best *Agent
+	bestLoad := 0
+	for i := 0; i < len(h.agents); i++ {
+		idx := i
+		if h.Strategy == RoundRobin {
+			idx = (h.next + i) % len(h.agents)
+		}
+		agent := &h.agents[idx]
+		if !agent.hasSkills(skills) || !h.hasCapacity(*agent) {
+			continue
+		}
+		if h.Strategy == RoundRobin {
+			h.next = idx + 1
+			return agent
+		}
+		if load := h.load(agent.ID); best == nil || load < bestLoad {
+			best, bestLoad = agent, load
+		}
+	}
+	return best
+}
+
+func (h *HandoffManager) removeQueued(waID string) {
+	for i, a := range h.queue {
+		if a.WaID == waID {
+			h.queue = append(h.queue[:i], h.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// drainQueue assigns queued conversations while agents have room.
+func (h *HandoffManager) drainQueue() {
+	remaining := h.queue[:0]
+	for _, a := range h.queue {
+		if agent := h.pick(a.Skills); agent != nil {
+			a.AgentID = agent.ID
+			a.Since = time.Now()
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+	h.queue = remaining
+}
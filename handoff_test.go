@@ -0,0 +1,52 @@
+package whatsappdau
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHandoffReassign(t *testing.T) {
+	h := NewHandoffManager(LeastLoaded, Agent{ID: "ann", MaxActive: 1}, Agent{ID: "bob"})
+	first := h.Start("15550000001")
+	if first.AgentID != "ann" {
+		t.Fatalf("first handoff went to %q, want ann", first.AgentID)
+	}
+	// Neither agent has the skill, so the next conversation waits.
+	queued := h.Start("15550000002", "billing")
+	if queued.AgentID != "" {
+		t.Fatalf("second handoff went to %q, want the queue", queued.AgentID)
+	}
+
+	// Reassigning to the current agent keeps the assignment as it was.
+	if err := h.Reassign("15550000001", "ann"); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := h.Active("15550000001"); a.AgentID != first.AgentID || !a.Since.Equal(first.Since) {
+		t.Errorf("same-agent reassign changed %+v to %+v", first, a)
+	}
+
+	// Once ann has the skill, moving her conversation away frees her to take
+	// the queued one.
+	h.mu.Lock()
+	h.agents[0].Skills = []string{"billing"}
+	h.mu.Unlock()
+	if err := h.Reassign("15550000001", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := h.Active("15550000001"); a.AgentID != "bob" {
+		t.Errorf("reassigned to %q, want bob", a.AgentID)
+	}
+	if a, _ := h.Active("15550000002"); a.AgentID != "ann" {
+		t.Errorf("queued conversation went to %q, want ann", a.AgentID)
+	}
+	if q := h.Queue(); len(q) != 0 {
+		t.Errorf("queue = %+v, want empty", q)
+	}
+
+	if err := h.Reassign("15550000003", "bob"); !errors.Is(err, ErrNoHandoff) {
+		t.Errorf("Reassign(unknown conversation) = %v, want ErrNoHandoff", err)
+	}
+	if err := h.Reassign("15550000001", "eve"); !errors.Is(err, ErrUnknownAgent) {
+		t.Errorf("Reassign(unknown agent) = %v, want ErrUnknownAgent", err)
+	}
+}
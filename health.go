@@ -0,0 +1,83 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var ErrTokenInvalid = errors.New("whatsappdau: access token is not valid")
+
+// ScopeMessaging is the permission needed to send messages.
+const ScopeMessaging = "whatsapp_business_messaging"
+
+type TokenHealth struct {
+	Valid       bool
+	AppID       string
+	Application string
+	Type        string
+	// ExpiresAt is zero for tokens that never expire.
+	ExpiresAt time.Time
+	Scopes    []string
+}
+
+// MissingScopes returns the scopes the token was not granted.
+func (h *TokenHealth) MissingScopes(scopes ...string) []string {
+	var missing []string
+	for _, s := range scopes {
+		if !containsString(h.Scopes, s) {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// HealthCheck inspects the access token with debug_token. It returns the
+// token details together with ErrTokenInvalid when the token is invalid,
+// expired or lacks ScopeMessaging, so it can back a readiness probe.
+func (w *WhatsappClient) HealthCheck(ctx context.Context) (*TokenHealth, error) {
+	var response struct {
+		Data struct {
+			AppID       string   `json:"app_id"`
+			Application string   `json:"application"`
+			Type        string   `json:"type"`
+			ExpiresAt   int64    `json:"expires_at"`
+			IsValid     bool     `json:"is_valid"`
+			Scopes      []string `json:"scopes"`
+			Error       struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"data"`
+	}
+	endpoint := w.graphURL() + "/debug_token?" + url.Values{"input_token": {w.accessToken}}.Encode()
+	if err := w.callGraph(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	d := response.Data
+	health := &TokenHealth{
+		Valid:       d.IsValid,
+		AppID:       d.AppID,
+		Application: d.Application,
+		Type:        d.Type,
+		Scopes:      d.Scopes,
+	}
+	if d.ExpiresAt > 0 {
+		health.ExpiresAt = time.Unix(d.ExpiresAt, 0)
+	}
+
+	switch {
+	case !d.IsValid && d.Error.Message != "":
+		return health, fmt.Errorf("%w: %s", ErrTokenInvalid, d.Error.Message)
+	case !d.IsValid:
+		return health, ErrTokenInvalid
+	case !health.ExpiresAt.IsZero() && time.Now().After(health.ExpiresAt):
+		return health, fmt.Errorf("%w: expired at %s", ErrTokenInvalid, health.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if missing := health.MissingScopes(ScopeMessaging); len(missing) > 0 {
+		return health, fmt.Errorf("%w: missing scope %s", ErrTokenInvalid, missing[0])
+	}
+	return health, nil
+}
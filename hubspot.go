@@ -0,0 +1,173 @@
+//go:build !whatsappdau_minimal
+
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HubSpotCRM is a reference CRMAdapter for HubSpot's CRM v3 API. Contacts are
+// keyed by the IDProperty contact property and activities are stored as notes
+// associated with the contact.
+//
+// IDProperty must be a property HubSpot enforces as unique, as batch upsert
+// and lookups by property require; the built-in "phone" is not. Create a
+// custom contact property, e.g. "whatsapp_id", with "Require unique values"
+// set, and use its internal name.
+type HubSpotCRM struct {
+	BaseURL    string
+	Token      string
+	IDProperty string
+	Properties []string
+	Client     *http.Client
+}
+
+// NewHubSpotCRM returns an adapter keying contacts by idProperty, see
+// HubSpotCRM. A nil client means http.DefaultClient.
+func NewHubSpotCRM(token, idProperty string, client *http.Client) *HubSpotCRM {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HubSpotCRM{
+		BaseURL:    "https://api.hubapi.com",
+		Token:      token,
+		IDProperty: idProperty,
+		Client:     client,
+	}
+}
+
+func (h *HubSpotCRM) UpsertContact(ctx context.Context, contact CRMContact) error {
+	properties := map[string]string{h.IDProperty: contact.WaID}
+	if contact.Name != "" {
+		first, last, _ := strings.Cut(contact.Name, " ")
+		properties["firstname"] = first
+		if last != "" {
+			properties["lastname"] = last
+		}
+	}
+	for k, v := range contact.Attributes {
+		properties[k] = v
+	}
+
+	body := map[string]interface{}{
+		"inputs": []map[string]interface{}{{
+			"idProperty": h.IDProperty,
+			"id":         contact.WaID,
+			"properties": properties,
+		}},
+	}
+	return h.do(ctx, "POST", "/crm/v3/objects/contacts/batch/upsert", body, nil)
+}
+
+func (h *HubSpotCRM) LogActivity(ctx context.Context, activity CRMActivity) error {
+	contact, err := h.fetchContact(ctx, activity.WaID, nil)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("WhatsApp %s %s", activity.Direction, activity.Type)
+	if activity.Status != "" {
+		text += " (" + activity.Status + ")"
+	}
+	if activity.Text != "" {
+		text += ": " + activity.Text
+	}
+	body := map[string]interface{}{
+		"properties": map[string]string{
+			"hs_note_body": text,
+			"hs_timestamp": activity.Timestamp.UTC().Format(time.RFC3339),
+		},
+		"associations": []map[string]interface{}{{
+			"to": map[string]string{"id": contact.ID},
+			"types": []map[string]interface{}{{
+				"associationCategory": "HUBSPOT_DEFINED",
+				"associationTypeId":   202,
+			}},
+		}},
+	}
+	return h.do(ctx, "POST", "/crm/v3/objects/notes", body, nil)
+}
+
+func (h *HubSpotCRM) FetchAttributes(ctx context.Context, waID string) (map[string]string, error) {
+	contact, err := h.fetchContact(ctx, waID, h.Properties)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make(map[string]string, len(contact.Properties))
+	for k, v := range contact.Properties {
+		if v != nil {
+			attrs[k] = *v
+		}
+	}
+	return attrs, nil
+}
+
+type hubSpotContact struct {
+	ID         string             `json:"id"`
+	Properties map[string]*string `json:"properties"`
+}
+
+func (h *HubSpotCRM) fetchContact(ctx context.Context, waID string, properties []string) (*hubSpotContact, error) {
+	q := url.Values{"idProperty": {h.IDProperty}}
+	if len(properties) > 0 {
+		q.Set("properties", strings.Join(properties, ","))
+	}
+	var contact hubSpotContact
+	if err := h.do(ctx, "GET", "/crm/v3/objects/contacts/"+url.PathEscape(waID)+"?"+q.Encode(), nil, &contact); err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (h *HubSpotCRM) do(ctx context.Context, method, path string, body, out interface{}) error {
+	if h.IDProperty == "" {
+		return errors.New("hubspot: IDProperty must name a unique contact property")
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.BaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+h.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hubspot: received status code %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+//go:build !whatsappdau_minimal
+
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHubSpotUpsertContact(t *testing.T) {
+	var got struct {
+		Inputs []struct {
+			IDProperty string            `json:"idProperty"`
+			ID         string            `json:"id"`
+			Properties map[string]string `json:"properties"`
+		} `json:"inputs"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crm/v3/objects/contacts/batch/upsert" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		rw.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	h := NewHubSpotCRM("token", "whatsapp_id", nil)
+	h.BaseURL = srv.URL
+	if err := h.UpsertContact(context.Background(), CRMContact{WaID: "15551234567", Name: "Ann Lee"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Inputs) != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	in := got.Inputs[0]
+	if in.IDProperty != "whatsapp_id" || in.ID != "15551234567" || in.Properties["whatsapp_id"] != "15551234567" || in.Properties["lastname"] != "Lee" {
+		t.Errorf("got %+v", in)
+	}
+}
+
+func TestHubSpotRequiresIDProperty(t *testing.T) {
+	h := &HubSpotCRM{BaseURL: "http://127.0.0.1:0", Token: "token"}
+	if err := h.UpsertContact(context.Background(), CRMContact{WaID: "15551234567"}); err == nil {
+		t.Fatal("want an error without IDProperty")
+	}
+}
+
+type recordingCRM struct {
+	CRMAdapter
+	activities []CRMActivity
+}
+
+func (r *recordingCRM) LogActivity(ctx context.Context, a CRMActivity) error {
+	r.activities = append(r.activities, a)
+	return nil
+}
+
+func TestCRMSyncWatchOutcomesChains(t *testing.T) {
+	crm := &recordingCRM{}
+	log := NewOutcomeLog()
+	var earlier []MessageOutcome
+	log.OnChange = func(o MessageOutcome) { earlier = append(earlier, o) }
+
+	NewCRMSync(crm).WatchOutcomes(context.Background(), log)
+	log.RecordSend("15551234567", &MessageResponse{Messages: []Messages{{Id: "wamid.1"}}}, nil)
+
+	if len(earlier) != 1 || len(crm.activities) != 1 {
+		t.Fatalf("earlier OnChange saw %d outcomes, CRM %d; want 1 each", len(earlier), len(crm.activities))
+	}
+	if a := crm.activities[0]; a.WaID != "15551234567" || a.MessageID != "wamid.1" {
+		t.Errorf("got %+v", a)
+	}
+}
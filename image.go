@@ -0,0 +1,129 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// MaxImageSize is the largest image the Cloud API accepts.
+const MaxImageSize = 5 << 20
+
+// ImageProcessor turns data into an image the Cloud API accepts and returns
+// it with its MIME type.
+type ImageProcessor func(data []byte) ([]byte, string, error)
+
+// WithImagePreprocessing runs every image sent with SendImageToWhatsApp or
+// SendImageFrom through p before upload. A nil p uses FitImage.
+func WithImagePreprocessing(p ImageProcessor) Option {
+	return func(w *WhatsappClient) {
+		if p == nil {
+			p = FitImage
+		}
+		w.imageProcessor = p
+	}
+}
+
+// FitImage returns JPEG and PNG images within MaxImageSize unchanged.
+// Anything else image.Decode understands is re-encoded as JPEG, downscaled
+// until it fits. HEIC and WebP need a decoder registered with
+// image.RegisterFormat, which the standard library does not include.
+func FitImage(data []byte) ([]byte, string, error) {
+	mimeType := http.DetectContentType(data)
+	if (mimeType == "image/jpeg" || mimeType == "image/png") && len(data) <= MaxImageSize {
+		return data, mimeType, nil
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", &ValidationError{Field: "image", Reason: fmt.Sprintf("cannot convert %s to JPEG: %v", imageFormat(data, mimeType), err)}
+	}
+	for quality := 85; ; {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode %s image as JPEG: %w", format, err)
+		}
+		if buf.Len() <= MaxImageSize {
+			return buf.Bytes(), "image/jpeg", nil
+		}
+		// Shrink the area in proportion to the overshoot, with some margin
+		// since JPEG size is not linear in the pixel count.
+		scale := math.Sqrt(float64(MaxImageSize)/float64(buf.Len())) * 0.9
+		b := img.Bounds()
+		width, height := int(float64(b.Dx())*scale), int(float64(b.Dy())*scale)
+		if width < 1 || height < 1 {
+			return nil, "", &ValidationError{Field: "image", Reason: "cannot be reduced below the 5MB limit"}
+		}
+		img = downscale(img, width, height)
+	}
+}
+
+// imageFormat names the format of data for error messages.
+func imageFormat(data []byte, mimeType string) string {
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		switch string(data[8:12]) {
+		case "heic", "heix", "hevc", "heim", "heis", "mif1", "msf1":
+			return "HEIC"
+		case "avif", "avis":
+			return "AVIF"
+		}
+	}
+	if mimeType == "image/webp" {
+		return "WebP"
+	}
+	return mimeType
+}
+
+// downscale resizes img to width x height by averaging the source pixels
+// covering each destination pixel.
+func downscale(img image.Image, width, height int) image.Image {
+	src := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := src.Min.Y + y*src.Dy()/height
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := src.Min.X + x*src.Dx()/width
+			x1 := max(src.Min.X+(x+1)*src.Dx()/width, x0+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// prepareImage applies the configured ImageProcessor to r. Without one the
+// image is streamed as JPEG, as before.
+func (w *WhatsappClient) prepareImage(r io.Reader, filename string) (io.Reader, string, string, error) {
+	if w.imageProcessor == nil {
+		return r, filename, "image/jpeg", nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read image: %w", err)
+	}
+	data, mimeType, err := w.imageProcessor(data)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if mimeType == "image/jpeg" && !strings.EqualFold(filepath.Ext(filename), ".jpg") && !strings.EqualFold(filepath.Ext(filename), ".jpeg") {
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".jpg"
+	}
+	return bytes.NewReader(data), filename, mimeType, nil
+}
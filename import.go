@@ -0,0 +1,351 @@
+//go:build !whatsappdau_minimal
+
+package whatsappdau
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RowError reports a row that could not be imported. Row is 1-based and
+// counts the header, so it matches what a spreadsheet shows.
+type RowError struct {
+	Row   int
+	Value string
+	Err   error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %q: %v", e.Row, e.Value, e.Err)
+}
+
+type ImportResult struct {
+	Recipients []Recipient
+	Errors     []RowError
+	Dedup      DedupReport
+}
+
+type ImportOptions struct {
+	// PhoneColumn is the header of the column holding the number. When empty
+	// the first of phone, wa_id, to or number is used.
+	PhoneColumn string
+	// KeepDuplicates disables dropping rows whose number was already seen.
+	KeepDuplicates bool
+	// DefaultCountry is the ISO country of numbers in national format, see
+	// NormalizePhone. When empty they are reported as invalid.
+	DefaultCountry string
+}
+
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+var defaultPhoneColumns = []string{"phone", "wa_id", "to", "number"}
+
+// ImportRecipientsCSV reads recipients from CSV with a header row. Every
+// column other than the phone column becomes a template variable keyed by its
+// header.
+func ImportRecipientsCSV(r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return importRows(rows, nil, opts)
+}
+
+// ImportRecipientsXLSX reads recipients from the first worksheet of an XLSX
+// workbook, with the same layout rules as ImportRecipientsCSV. Row numbers
+// are the worksheet's own, so they stay right when empty rows were left out
+// of the file.
+func ImportRecipientsXLSX(r io.ReaderAt, size int64, opts ImportOptions) (*ImportResult, error) {
+	rows, nums, err := readXLSXRows(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX: %w", err)
+	}
+	return importRows(rows, nums, opts)
+}
+
+// importRows imports rows, the first being the header. nums holds the
+// 1-based number of each row; when nil the rows are numbered in order.
+func importRows(rows [][]string, nums []int, opts ImportOptions) (*ImportResult, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("no header row")
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, h := range rows[0] {
+		header[i] = strings.TrimSpace(h)
+	}
+	phoneCol := findPhoneColumn(header, opts.PhoneColumn)
+	if phoneCol < 0 {
+		return nil, fmt.Errorf("no phone column in header %v", header)
+	}
+
+	result := &ImportResult{}
+	var numbers []string
+	for i, row := range rows[1:] {
+		rowNum := i + 2
+		if nums != nil {
+			rowNum = nums[i+1]
+		}
+		if isBlankRow(row) {
+			continue
+		}
+		value := ""
+		if phoneCol < len(row) {
+			value = strings.TrimSpace(row[phoneCol])
+		}
+		waID, err := NormalizePhone(value, opts.DefaultCountry)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: rowNum, Value: value, Err: fmt.Errorf("%w: %w", ErrInvalidPhoneNumber, err)})
+			continue
+		}
+
+		vars := make(map[string]string, len(header)-1)
+		for col, name := range header {
+			if col == phoneCol || name == "" {
+				continue
+			}
+			if col < len(row) {
+				vars[name] = strings.TrimSpace(row[col])
+			} else {
+				vars[name] = ""
+			}
+		}
+		result.Recipients = append(result.Recipients, Recipient{WaID: waID, Variables: vars, Row: rowNum})
+		numbers = append(numbers, waID)
+	}
+
+	_, result.Dedup = DedupRecipients(numbers)
+	if !opts.KeepDuplicates && len(result.Dedup.Duplicates) > 0 {
+		drop := make(map[int]bool, len(result.Dedup.Duplicates))
+		for _, d := range result.Dedup.Duplicates {
+			drop[d.Index] = true
+		}
+		kept := result.Recipients[:0]
+		for i, rcpt := range result.Recipients {
+			if !drop[i] {
+				kept = append(kept, rcpt)
+			}
+		}
+		result.Recipients = kept
+	}
+	return result, nil
+}
+
+func findPhoneColumn(header []string, name string) int {
+	candidates := defaultPhoneColumns
+	if name != "" {
+		candidates = []string{name}
+	}
+	for _, c := range candidates {
+		for i, h := range header {
+			if strings.EqualFold(h, c) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxInlineString `xml:"si"`
+}
+
+type xlsxInlineString struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxInlineString) text() string {
+	if len(s.R) == 0 {
+		return s.T
+	}
+	var b strings.Builder
+	for _, r := range s.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Num   int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string           `xml:"r,attr"`
+			Type   string           `xml:"t,attr"`
+			Value  string           `xml:"v"`
+			Inline xlsxInlineString `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRows returns the rows of the first worksheet and their 1-based
+// numbers, which skip the empty rows a writer may leave out.
+func readXLSXRows(r io.ReaderAt, size int64) ([][]string, []int, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, nil, fmt.Errorf("worksheet %s not found", sheetPath)
+	}
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	nums := make([]int, 0, len(sheet.Rows))
+	prev := 0
+	for _, row := range sheet.Rows {
+		num := row.Num
+		if num == 0 {
+			num = prev + 1
+		}
+		if num <= prev || num > xlsxMaxRows {
+			return nil, nil, fmt.Errorf("invalid row number %d after %d", num, prev)
+		}
+		prev = num
+
+		var values []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			if col < 0 || col >= xlsxMaxColumns {
+				return nil, nil, fmt.Errorf("invalid cell reference %q", c.Ref)
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err == nil && idx < len(shared.Items) {
+					values[col] = shared.Items[idx].text()
+				}
+			case "inlineStr":
+				values[col] = c.Inline.text()
+			default:
+				values[col] = c.Value
+			}
+		}
+		rows = append(rows, values)
+		nums = append(nums, num)
+	}
+	return rows, nums, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("xl/workbook.xml not found")
+	}
+	var wb xlsxWorkbook
+	if err := decodeZipXML(wbFile, &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	if relFile, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		var rels xlsxRelationships
+		if err := decodeZipXML(relFile, &rels); err != nil {
+			return "", err
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ID == wb.Sheets[0].RelID {
+				if strings.HasPrefix(rel.Target, "/") {
+					return strings.TrimPrefix(rel.Target, "/"), nil
+				}
+				return path.Join("xl", rel.Target), nil
+			}
+		}
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// xlsxMaxColumns and xlsxMaxRows are the sheet dimensions Excel supports,
+// columns A to XFD.
+const (
+	xlsxMaxColumns = 16384
+	xlsxMaxRows    = 1048576
+)
+
+// columnIndex converts a cell reference such as "C7" to a 0-based column. It
+// returns -1 when ref has no column letters or is past xlsxMaxColumns.
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if col > xlsxMaxColumns {
+			return -1
+		}
+	}
+	return col - 1
+}
@@ -0,0 +1,202 @@
+//go:build !whatsappdau_minimal
+
+package whatsappdau
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestImportRecipientsCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		opts    ImportOptions
+		waIDs   []string
+		errRows []int
+	}{
+		{
+			name:  "international",
+			csv:   "phone,name\n+1 (555) 123-4567,Ann\n0044 20 7946 0958,Bob\n",
+			waIDs: []string{"15551234567", "442079460958"},
+		},
+		{
+			name:  "national with default country",
+			csv:   "phone,name\n020 7946 0958,Ann\n",
+			opts:  ImportOptions{DefaultCountry: "GB"},
+			waIDs: []string{"442079460958"},
+		},
+		{
+			name:    "national without default country",
+			csv:     "phone,name\n020 7946 0958,Ann\n",
+			errRows: []int{2},
+		},
+		{
+			name:    "invalid and blank rows",
+			csv:     "phone,name\nabc,Ann\n,\n+15551234567,Bob\n12,Cy\n",
+			waIDs:   []string{"15551234567"},
+			errRows: []int{2, 5},
+		},
+		{
+			name:  "duplicates after normalizing",
+			csv:   "wa_id\n+1 555 123 4567\n15551234567\n",
+			waIDs: []string{"15551234567"},
+		},
+		{
+			name:  "keep duplicates",
+			csv:   "wa_id\n+1 555 123 4567\n15551234567\n",
+			opts:  ImportOptions{KeepDuplicates: true},
+			waIDs: []string{"15551234567", "15551234567"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ImportRecipientsCSV(strings.NewReader(tt.csv), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var waIDs []string
+			for _, r := range res.Recipients {
+				waIDs = append(waIDs, r.WaID)
+			}
+			if strings.Join(waIDs, ",") != strings.Join(tt.waIDs, ",") {
+				t.Errorf("recipients %v, want %v", waIDs, tt.waIDs)
+			}
+			var rows []int
+			for _, e := range res.Errors {
+				rows = append(rows, e.Row)
+				if !errors.Is(e.Err, ErrInvalidPhoneNumber) {
+					t.Errorf("row %d: %v does not match ErrInvalidPhoneNumber", e.Row, e.Err)
+				}
+			}
+			if len(rows) != len(tt.errRows) {
+				t.Fatalf("error rows %v, want %v", rows, tt.errRows)
+			}
+			for i := range rows {
+				if rows[i] != tt.errRows[i] {
+					t.Errorf("error rows %v, want %v", rows, tt.errRows)
+				}
+			}
+		})
+	}
+}
+
+func TestImportRecipientsXLSX(t *testing.T) {
+	tests := []struct {
+		name    string
+		cells   string
+		waIDs   []string
+		rows    []int
+		errRows []int
+		wantErr bool
+	}{
+		{
+			name: "refs",
+			cells: `<row><c r="A1" t="inlineStr"><is><t>phone</t></is></c><c r="C1" t="inlineStr"><is><t>name</t></is></c></row>` +
+				`<row><c r="A2"><v>15551234567</v></c><c r="C2" t="inlineStr"><is><t>Ann</t></is></c></row>`,
+			waIDs: []string{"15551234567"},
+		},
+		{
+			name:  "no refs",
+			cells: `<row><c t="inlineStr"><is><t>phone</t></is></c></row><row><c><v>15551234567</v></c></row>`,
+			waIDs: []string{"15551234567"},
+		},
+		{
+			name: "row numbers with gaps",
+			cells: `<row r="1"><c r="A1" t="inlineStr"><is><t>phone</t></is></c><c r="C1" t="inlineStr"><is><t>name</t></is></c></row>` +
+				`<row r="3"><c r="C3" t="inlineStr"><is><t>Ann</t></is></c><c r="A3"><v>15551234567</v></c></row>` +
+				`<row r="6"><c r="A6" t="inlineStr"><is><t>not a number</t></is></c></row>` +
+				`<row><c r="A7"><v>15557654321</v></c></row>`,
+			waIDs:   []string{"15551234567", "15557654321"},
+			rows:    []int{3, 7},
+			errRows: []int{6},
+		},
+		{
+			name:    "rows out of order",
+			cells:   `<row r="2"><c t="inlineStr"><is><t>phone</t></is></c></row><row r="2"><c><v>15551234567</v></c></row>`,
+			wantErr: true,
+		},
+		{
+			name:    "row past the last row",
+			cells:   `<row r="1048577"><c t="inlineStr"><is><t>phone</t></is></c></row>`,
+			wantErr: true,
+		},
+		{
+			name:    "ref without column",
+			cells:   `<row><c r="7" t="inlineStr"><is><t>phone</t></is></c></row>`,
+			wantErr: true,
+		},
+		{
+			name:    "ref past the last column",
+			cells:   `<row><c r="ZZZZZZ1" t="inlineStr"><is><t>phone</t></is></c></row>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildXLSX(t, tt.cells)
+			res, err := ImportRecipientsXLSX(bytes.NewReader(data), int64(len(data)), ImportOptions{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Recipients) != len(tt.waIDs) {
+				t.Fatalf("recipients %+v, want %v", res.Recipients, tt.waIDs)
+			}
+			for i, r := range res.Recipients {
+				if r.WaID != tt.waIDs[i] {
+					t.Errorf("recipient %d is %s, want %s", i, r.WaID, tt.waIDs[i])
+				}
+				if tt.rows != nil && r.Row != tt.rows[i] {
+					t.Errorf("recipient %s on row %d, want %d", r.WaID, r.Row, tt.rows[i])
+				}
+			}
+			if len(res.Errors) != len(tt.errRows) {
+				t.Fatalf("errors %+v, want rows %v", res.Errors, tt.errRows)
+			}
+			for i, e := range res.Errors {
+				if e.Row != tt.errRows[i] {
+					t.Errorf("error on row %d, want %d", e.Row, tt.errRows[i])
+				}
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := map[string]int{"A1": 0, "C7": 2, "Z1": 25, "AA1": 26, "XFD1": 16383, "XFE1": -1, "7": -1, "": -1}
+	for ref, want := range tests {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func buildXLSX(t *testing.T, rows string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml":          `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet r:id="rId1"/></sheets></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` + rows + `</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,13 @@
+module github.com/daulet140/whatsappdau/kafkawhatsappdau
+
+go 1.25.0
+
+require (
+	github.com/daulet140/whatsappdau v0.0.0-20261015114746-210ef37890e8
+	github.com/segmentio/kafka-go v0.4.51
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
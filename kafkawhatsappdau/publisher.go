@@ -0,0 +1,32 @@
+// Package kafkawhatsappdau publishes whatsappdau webhook events to Kafka. It
+// is a separate module so the core package stays free of the Kafka client
+// dependency.
+package kafkawhatsappdau
+
+import (
+	"context"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher implements whatsappdau.Publisher. Messages are keyed by wa_id,
+// so each conversation stays in order within its partition:
+//
+//	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Balancer: &kafka.Hash{}}
+//	dispatcher.PublishTo(kafkawhatsappdau.NewPublisher(w))
+//
+// The writer must not set Topic, since every event names its own.
+type Publisher struct {
+	Writer *kafka.Writer
+}
+
+var _ whatsappdau.Publisher = (*Publisher)(nil)
+
+func NewPublisher(w *kafka.Writer) *Publisher {
+	return &Publisher{Writer: w}
+}
+
+func (p *Publisher) Publish(ctx context.Context, topic, key string, data []byte) error {
+	return p.Writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(key), Value: data})
+}
@@ -0,0 +1,76 @@
+//go:build !whatsappdau_minimal && !tinygo && !js && !wasip1
+
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func keyringGet(ctx context.Context, service, account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", ErrKeyringUnsupported
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && keyringNotFound(exitErr.ExitCode(), stderr.String()) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read keyring: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// keyringNotFound reports whether a failed lookup means there is no entry:
+// security exits with errSecItemNotFound (44), secret-tool with 1 and
+// nothing on stderr.
+func keyringNotFound(code int, stderr string) bool {
+	if runtime.GOOS == "darwin" {
+		return code == 44
+	}
+	return code == 1 && strings.TrimSpace(stderr) == ""
+}
+
+func keyringSet(ctx context.Context, service, account, secret string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// Commands read by security -i never show up in the process list,
+		// unlike arguments.
+		cmd = exec.CommandContext(ctx, "security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityQuote(service), securityQuote(account), securityQuote(secret)))
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "store", "--label", service+" "+account, "service", service, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+	default:
+		return ErrKeyringUnsupported
+	}
+	out, err := cmd.CombinedOutput()
+	if err == nil && runtime.GOOS == "darwin" && bytes.Contains(out, []byte("security: ")) {
+		// security -i reports a failed command but still exits 0.
+		err = errors.New("add-generic-password failed")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write keyring: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// securityQuote quotes s for the command line security -i reads.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
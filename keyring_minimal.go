@@ -0,0 +1,13 @@
+//go:build whatsappdau_minimal || tinygo || js || wasip1
+
+package whatsappdau
+
+import "context"
+
+func keyringGet(ctx context.Context, service, account string) (string, error) {
+	return "", ErrKeyringUnsupported
+}
+
+func keyringSet(ctx context.Context, service, account, secret string) error {
+	return ErrKeyringUnsupported
+}
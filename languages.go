@@ -0,0 +1,125 @@
+package whatsappdau
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Template language codes supported by WhatsApp.
+const (
+	LanguageAfrikaans          = "af"
+	LanguageAlbanian           = "sq"
+	LanguageArabic             = "ar"
+	LanguageAzerbaijani        = "az"
+	LanguageBengali            = "bn"
+	LanguageBulgarian          = "bg"
+	LanguageCatalan            = "ca"
+	LanguageChineseCHN         = "zh_CN"
+	LanguageChineseHKG         = "zh_HK"
+	LanguageChineseTAI         = "zh_TW"
+	LanguageCroatian           = "hr"
+	LanguageCzech              = "cs"
+	LanguageDanish             = "da"
+	LanguageDutch              = "nl"
+	LanguageEnglish            = "en"
+	LanguageEnglishUK          = "en_GB"
+	LanguageEnglishUS          = "en_US"
+	LanguageEstonian           = "et"
+	LanguageFilipino           = "fil"
+	LanguageFinnish            = "fi"
+	LanguageFrench             = "fr"
+	LanguageGeorgian           = "ka"
+	LanguageGerman             = "de"
+	LanguageGreek              = "el"
+	LanguageGujarati           = "gu"
+	LanguageHausa              = "ha"
+	LanguageHebrew             = "he"
+	LanguageHindi              = "hi"
+	LanguageHungarian          = "hu"
+	LanguageIndonesian         = "id"
+	LanguageIrish              = "ga"
+	LanguageItalian            = "it"
+	LanguageJapanese           = "ja"
+	LanguageKannada            = "kn"
+	LanguageKazakh             = "kk"
+	LanguageKinyarwanda        = "rw_RW"
+	LanguageKorean             = "ko"
+	LanguageKyrgyz             = "ky_KG"
+	LanguageLao                = "lo"
+	LanguageLatvian            = "lv"
+	LanguageLithuanian         = "lt"
+	LanguageMacedonian         = "mk"
+	LanguageMalay              = "ms"
+	LanguageMalayalam          = "ml"
+	LanguageMarathi            = "mr"
+	LanguageNorwegian          = "nb"
+	LanguagePersian            = "fa"
+	LanguagePolish             = "pl"
+	LanguagePortugueseBrazil   = "pt_BR"
+	LanguagePortuguesePortugal = "pt_PT"
+	LanguagePunjabi            = "pa"
+	LanguageRomanian           = "ro"
+	LanguageRussian            = "ru"
+	LanguageSerbian            = "sr"
+	LanguageSlovak             = "sk"
+	LanguageSlovenian          = "sl"
+	LanguageSpanish            = "es"
+	LanguageSpanishArgentina   = "es_AR"
+	LanguageSpanishSpain       = "es_ES"
+	LanguageSpanishMexico      = "es_MX"
+	LanguageSwahili            = "sw"
+	LanguageSwedish            = "sv"
+	LanguageTamil              = "ta"
+	LanguageTelugu             = "te"
+	LanguageThai               = "th"
+	LanguageTurkish            = "tr"
+	LanguageUkrainian          = "uk"
+	LanguageUrdu               = "ur"
+	LanguageUzbek              = "uz"
+	LanguageVietnamese         = "vi"
+	LanguageZulu               = "zu"
+)
+
+// TemplateLanguages is the set of language codes SendTemplate accepts. Add
+// codes WhatsApp supports that are missing here, or use WithoutValidation.
+var TemplateLanguages = map[string]bool{
+	LanguageAfrikaans: true, LanguageAlbanian: true, LanguageArabic: true, LanguageAzerbaijani: true,
+	LanguageBengali: true, LanguageBulgarian: true, LanguageCatalan: true, LanguageChineseCHN: true,
+	LanguageChineseHKG: true, LanguageChineseTAI: true, LanguageCroatian: true, LanguageCzech: true,
+	LanguageDanish: true, LanguageDutch: true, LanguageEnglish: true, LanguageEnglishUK: true,
+	LanguageEnglishUS: true, LanguageEstonian: true, LanguageFilipino: true, LanguageFinnish: true,
+	LanguageFrench: true, LanguageGeorgian: true, LanguageGerman: true, LanguageGreek: true,
+	LanguageGujarati: true, LanguageHausa: true, LanguageHebrew: true, LanguageHindi: true,
+	LanguageHungarian: true, LanguageIndonesian: true, LanguageIrish: true, LanguageItalian: true,
+	LanguageJapanese: true, LanguageKannada: true, LanguageKazakh: true, LanguageKinyarwanda: true,
+	LanguageKorean: true, LanguageKyrgyz: true, LanguageLao: true, LanguageLatvian: true,
+	LanguageLithuanian: true, LanguageMacedonian: true, LanguageMalay: true, LanguageMalayalam: true,
+	LanguageMarathi: true, LanguageNorwegian: true, LanguagePersian: true, LanguagePolish: true,
+	LanguagePortugueseBrazil: true, LanguagePortuguesePortugal: true, LanguagePunjabi: true, LanguageRomanian: true,
+	LanguageRussian: true, LanguageSerbian: true, LanguageSlovak: true, LanguageSlovenian: true,
+	LanguageSpanish: true, LanguageSpanishArgentina: true, LanguageSpanishSpain: true, LanguageSpanishMexico: true,
+	LanguageSwahili: true, LanguageSwedish: true, LanguageTamil: true, LanguageTelugu: true,
+	LanguageThai: true, LanguageTurkish: true, LanguageUkrainian: true, LanguageUrdu: true,
+	LanguageUzbek: true, LanguageVietnamese: true, LanguageZulu: true,
+}
+
+// ValidateLanguage reports whether code is in TemplateLanguages, suggesting
+// the nearest supported code for near misses like "en-US" or "ru_RU".
+func ValidateLanguage(code string) error {
+	if err := validateRequired("template language", code); err != nil {
+		return err
+	}
+	if TemplateLanguages[code] {
+		return nil
+	}
+	supported := make([]string, 0, len(TemplateLanguages))
+	for lang := range TemplateLanguages {
+		supported = append(supported, lang)
+	}
+	sort.Strings(supported)
+	reason := fmt.Sprintf("%q is not a WhatsApp language code", code)
+	if lang, ok := matchLanguage(supported, code); ok {
+		reason += fmt.Sprintf(", did you mean %q?", lang)
+	}
+	return &ValidationError{Field: "template language", Reason: reason}
+}
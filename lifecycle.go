@@ -0,0 +1,124 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ErrShutdown is the error of sends refused because their component is
+// shutting down.
+var ErrShutdown = errors.New("whatsappdau: shutting down")
+
+// Service is a background component that can be started and shut down
+// gracefully. Shutdown drains in-flight work and returns early with ctx's
+// error if ctx is done first.
+type Service interface {
+	Start(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// Services starts and stops components together. Start them producers last,
+// so Shutdown, which runs in reverse, stops intake before draining queues:
+//
+//	services := whatsappdau.Services{outbox, async, whatsappdau.NewWebhookServer(":8080", dispatcher)}
+//	services.Start(ctx)
+//	<-stop
+//	services.Shutdown(shutdownCtx)
+type Services []Service
+
+// Start starts every service in order. If one fails, those already started
+// are shut down again.
+func (s Services) Start(ctx context.Context) error {
+	for i, svc := range s {
+		if err := svc.Start(ctx); err != nil {
+			return errors.Join(err, s[:i].Shutdown(ctx))
+		}
+	}
+	return nil
+}
+
+// Shutdown shuts every service down in reverse order, even after one fails,
+// and returns the errors joined together.
+func (s Services) Shutdown(ctx context.Context) error {
+	var errs []error
+	for i := len(s) - 1; i >= 0; i-- {
+		if err := s[i].Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// waitContext waits for wg or until ctx is done.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WebhookServer serves a Dispatcher over HTTP. Shutdown stops accepting
+// deliveries and waits for the ones being handled.
+type WebhookServer struct {
+	Addr       string
+	Dispatcher *Dispatcher
+
+	server   *http.Server
+	listener net.Listener
+	done     chan struct{}
+}
+
+func NewWebhookServer(addr string, d *Dispatcher) *WebhookServer {
+	return &WebhookServer{Addr: addr, Dispatcher: d}
+}
+
+// Start listens on Addr and serves in the background. Listen errors are
+// returned; later serve errors are logged by net/http.
+func (s *WebhookServer) Start(ctx context.Context) error {
+	if s.server != nil {
+		return errors.New("webhook server already started")
+	}
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for webhooks: %w", err)
+	}
+	s.listener = ln
+	s.server = &http.Server{Handler: s.Dispatcher}
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		s.server.Serve(ln)
+	}()
+	return nil
+}
+
+// ListenAddr returns the address the server listens on once started, e.g.
+// to find the port picked for Addr ":0".
+func (s *WebhookServer) ListenAddr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *WebhookServer) Shutdown(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	if err := s.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	<-s.done
+	return nil
+}
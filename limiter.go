@@ -0,0 +1,99 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSendLimitReached = errors.New("whatsappdau: send limit reached")
+	ErrMaintenance      = errors.New("whatsappdau: sending is paused for maintenance")
+)
+
+// Limiter decides whether a business-initiated send to recipient may go out now.
+type Limiter interface {
+	Allow(ctx context.Context, recipient string) error
+}
+
+// LimitError is returned by a Limiter when the current budget is exhausted.
+// RetryAt is the earliest time the send is expected to be allowed again.
+// NewRecipients is set when only recipients new to the limiter's window are
+// refused, as with TierLimiter and RampLimiter; sends to the others still go
+// out.
+type LimitError struct {
+	Limit         int
+	RetryAt       time.Time
+	Reason        string
+	NewRecipients bool
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: limit %d reached, retry at %s", e.Reason, e.Limit, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrSendLimitReached
+}
+
+// clientBinder is implemented by limiters that need the client they are
+// installed on, e.g. to query the phone number.
+type clientBinder interface {
+	bindClient(w *WhatsappClient)
+}
+
+// SetMaintenance pauses or resumes sending. While paused every send fails
+// with ErrMaintenance before reaching the API.
+func (w *WhatsappClient) SetMaintenance(on bool) {
+	w.maintenance.Store(on)
+}
+
+func (w *WhatsappClient) Maintenance() bool {
+	return w.maintenance.Load()
+}
+
+// reserver is implemented by limiters that spend budget per recipient, such
+// as TierLimiter and RampLimiter. reserve is Allow, returning a func that
+// gives the budget back.
+type reserver interface {
+	reserve(ctx context.Context, recipient string) (release func(), err error)
+}
+
+// allow checks recipient against every limiter before anything is spent for
+// good: limiters implementing reserver only hold the budget, and release,
+// which the caller must call unless the API accepted the message, hands it
+// back. A refusal releases what the earlier limiters reserved.
+func (w *WhatsappClient) allow(ctx context.Context, recipient string) (release func(), err error) {
+	if w.maintenance.Load() {
+		return nil, ErrMaintenance
+	}
+	if w.serviceWindows != nil && w.serviceWindows.Open(recipient) {
+		return func() {}, nil
+	}
+	var releases []func()
+	release = func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for _, l := range w.limiters {
+		var err error
+		if r, ok := l.(reserver); ok {
+			var undo func()
+			if undo, err = r.reserve(ctx, recipient); err == nil {
+				releases = append(releases, undo)
+			}
+		} else {
+			err = l.Allow(ctx, recipient)
+		}
+		if err != nil {
+			release()
+			if w.pressure != nil {
+				w.pressure.Observe(err)
+			}
+			return nil, err
+		}
+	}
+	return release, nil
+}
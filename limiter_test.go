@@ -0,0 +1,82 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// countingLimiter reserves one slot per send and records the context it was
+// called with.
+type countingLimiter struct {
+	reserved int
+	ctx      context.Context
+}
+
+func (c *countingLimiter) Allow(ctx context.Context, recipient string) error {
+	_, err := c.reserve(ctx, recipient)
+	return err
+}
+
+func (c *countingLimiter) reserve(ctx context.Context, recipient string) (func(), error) {
+	c.ctx = ctx
+	c.reserved++
+	return func() { c.reserved-- }, nil
+}
+
+type refusingLimiter struct{}
+
+func (refusingLimiter) Allow(ctx context.Context, recipient string) error {
+	return &LimitError{Limit: 1, Reason: "test"}
+}
+
+type ctxKey struct{}
+
+func TestAllowReleasesUnsentBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		refuse   bool
+		reserved int
+		wantErr  error
+	}{
+		{"accepted", http.StatusOK, false, 1, nil},
+		{"later limiter refuses", http.StatusOK, true, 0, ErrSendLimitReached},
+		{"API rejects", http.StatusBadRequest, false, 0, ErrReengagementRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(tt.status)
+				if tt.status != http.StatusOK {
+					fmt.Fprintf(rw, `{"error":{"message":"failed","code":%d}}`, ErrCodeReengagementRequired)
+					return
+				}
+				fmt.Fprint(rw, `{"messages":[{"id":"wamid.1"}]}`)
+			}))
+			defer srv.Close()
+
+			counting := &countingLimiter{}
+			opts := []Option{WithLimiter(counting)}
+			if tt.refuse {
+				opts = append(opts, WithLimiter(refusingLimiter{}))
+			}
+			w := NewWhatsappClient(nil, srv.URL+"/v21.0/1/messages", "token", nil, opts...).(*WhatsappClient)
+
+			ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+			_, err := w.SendRaw(ctx, map[string]interface{}{"to": "15551234567", "type": "text", "text": map[string]string{"body": "hi"}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendRaw() = %v, want %v", err, tt.wantErr)
+			}
+			if counting.reserved != tt.reserved {
+				t.Errorf("reserved %d, want %d", counting.reserved, tt.reserved)
+			}
+			if counting.ctx == nil || counting.ctx.Value(ctxKey{}) != "request" {
+				t.Error("limiter did not get the request context")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package whatsappdau
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNoTemplateLanguage = errors.New("whatsappdau: no approved language for template")
+
+// TemplateCatalog knows which languages each template is approved in.
+type TemplateCatalog interface {
+	Languages(name string) []string
+}
+
+// TemplateList is a TemplateCatalog and TemplateLookup over templates fetched
+// with ListTemplates.
+type TemplateList []Template
+
+func (l TemplateList) Languages(name string) []string {
+	var languages []string
+	for _, t := range l {
+		if t.Name == name && t.Status == TemplateApproved {
+			languages = append(languages, t.Language)
+		}
+	}
+	return languages
+}
+
+// Lookup returns the template name in language.
+func (l TemplateList) Lookup(name, language string) (Template, bool) {
+	for _, t := range l {
+		if t.Name == name && t.Language == language {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// SelectLanguage picks the language of template name that best serves
+// locale, e.g. "pt-BR" or "pt_BR": the exact code, then the bare language
+// ("pt"), then another variant of it ("pt_PT"), then the first available of
+// fallbacks.
+func SelectLanguage(c TemplateCatalog, name, locale string, fallbacks ...string) (string, error) {
+	available := c.Languages(name)
+	if lang, ok := matchLanguage(available, locale); ok {
+		return lang, nil
+	}
+	for _, fb := range fallbacks {
+		if lang, ok := matchLanguage(available, fb); ok {
+			return lang, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s for locale %q", ErrNoTemplateLanguage, name, locale)
+}
+
+func matchLanguage(available []string, locale string) (string, bool) {
+	want := strings.ToLower(strings.ReplaceAll(locale, "-", "_"))
+	base, _, _ := strings.Cut(want, "_")
+	if want == "" {
+		return "", false
+	}
+	var sameBase string
+	for _, lang := range available {
+		l := strings.ToLower(lang)
+		switch {
+		case l == want:
+			return lang, true
+		case l == base:
+			sameBase = lang
+		case sameBase == "" && strings.HasPrefix(l, base+"_"):
+			sameBase = lang
+		}
+	}
+	return sameBase, sameBase != ""
+}
+
+// Localize sets the language of t to the best match for locale, see
+// SelectLanguage.
+func (t *TemplateMessage) Localize(c TemplateCatalog, locale string, fallbacks ...string) error {
+	lang, err := SelectLanguage(c, t.Name, locale, fallbacks...)
+	if err != nil {
+		return err
+	}
+	t.Language.Code = lang
+	return nil
+}
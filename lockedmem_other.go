@@ -0,0 +1,22 @@
+//go:build !(linux || darwin || freebsd || openbsd || netbsd)
+
+package whatsappdau
+
+// lockedBuffer falls back to ordinary memory where mlock is unavailable.
+type lockedBuffer struct {
+	mem []byte
+}
+
+func newLockedBuffer(secret []byte) (*lockedBuffer, error) {
+	mem := make([]byte, len(secret))
+	copy(mem, secret)
+	wipe(secret)
+	return &lockedBuffer{mem: mem}, nil
+}
+
+func (b *lockedBuffer) bytes() []byte { return b.mem }
+
+func (b *lockedBuffer) release() error {
+	wipe(b.mem)
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd
+
+package whatsappdau
+
+import "syscall"
+
+// lockedBuffer holds a secret in an mmap'd region pinned with mlock so it is
+// never written to swap.
+type lockedBuffer struct {
+	mem []byte
+	n   int
+}
+
+func newLockedBuffer(secret []byte) (*lockedBuffer, error) {
+	size := syscall.Getpagesize()
+	for size < len(secret) {
+		size += syscall.Getpagesize()
+	}
+	mem, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	// RLIMIT_MEMLOCK can be tiny in containers; if locking fails the secret
+	// still stays off the Go heap.
+	_ = syscall.Mlock(mem)
+	n := copy(mem, secret)
+	wipe(secret)
+	return &lockedBuffer{mem: mem, n: n}, nil
+}
+
+func (b *lockedBuffer) bytes() []byte { return b.mem[:b.n] }
+
+func (b *lockedBuffer) release() error {
+	wipe(b.mem)
+	_ = syscall.Munlock(b.mem)
+	return syscall.Munmap(b.mem)
+}
@@ -0,0 +1,76 @@
+package whatsappdau
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// SetLogger sets where the library logs what it cannot return to a caller,
+// such as webhook handler errors and API deprecation warnings. Until it is
+// called slog.Default() is used; nil restores that.
+func SetLogger(l *slog.Logger) {
+	defaultLogger.Store(l)
+}
+
+var debugLogging atomic.Bool
+
+// SetDebugLogging makes the package log at slog.LevelDebug and above even if
+// the handler in use is set to a higher level, e.g. to trace a live issue
+// without redeploying. The admin debug toggle calls it.
+func SetDebugLogging(on bool) {
+	debugLogging.Store(on)
+}
+
+func DebugLogging() bool {
+	return debugLogging.Load()
+}
+
+// debugHandler lets debug records through to a handler that would otherwise
+// drop them. The stdlib handlers only check the level in Enabled.
+type debugHandler struct {
+	slog.Handler
+}
+
+func (h debugHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelDebug || h.Handler.Enabled(ctx, level)
+}
+
+func (h debugHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return debugHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h debugHandler) WithGroup(name string) slog.Handler {
+	return debugHandler{h.Handler.WithGroup(name)}
+}
+
+func withLevel(l *slog.Logger) *slog.Logger {
+	if !debugLogging.Load() {
+		return l
+	}
+	return slog.New(debugHandler{l.Handler()})
+}
+
+func logger() *slog.Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return withLevel(l)
+	}
+	return withLevel(slog.Default())
+}
+
+// WithLogger makes the client log to l instead of the package logger, e.g.
+// to tag every entry with a tenant.
+func WithLogger(l *slog.Logger) Option {
+	return func(w *WhatsappClient) {
+		w.logger = l
+	}
+}
+
+func (w *WhatsappClient) log() *slog.Logger {
+	if w.logger != nil {
+		return withLevel(w.logger)
+	}
+	return logger()
+}
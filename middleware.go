@@ -0,0 +1,82 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// WebhookHandler handles a parsed webhook notification.
+type WebhookHandler func(ctx context.Context, n *WebhookNotification) error
+
+// WebhookMiddleware wraps a WebhookHandler, like HTTP middleware wraps an
+// http.Handler: it may inspect or change the notification, skip next, or
+// act on its error.
+type WebhookMiddleware func(next WebhookHandler) WebhookHandler
+
+// Use adds middleware around Dispatch. The first middleware added is the
+// outermost. Deliveries reach middleware after the signature and replay
+// checks; WebhookRequest returns the delivery request.
+func (d *Dispatcher) Use(mw ...WebhookMiddleware) {
+	d.middleware = append(d.middleware, mw...)
+}
+
+func (d *Dispatcher) chain() WebhookHandler {
+	h := d.dispatch
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		h = d.middleware[i](h)
+	}
+	return h
+}
+
+type webhookRequestKey struct{}
+
+// WebhookRequest returns the HTTP request a notification was delivered in,
+// for middleware that checks headers. It is nil when Dispatch was called
+// directly.
+func WebhookRequest(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(webhookRequestKey{}).(*http.Request)
+	return r
+}
+
+// RecoverPanics turns a panic in a later handler into an error, so one bad
+// notification is logged instead of taking the request down.
+func RecoverPanics() WebhookMiddleware {
+	return func(next WebhookHandler) WebhookHandler {
+		return func(ctx context.Context, n *WebhookNotification) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					logger().Error("webhook handler panicked", "panic", p, "stack", string(debug.Stack()))
+					err = fmt.Errorf("webhook handler panic: %v", p)
+				}
+			}()
+			return next(ctx, n)
+		}
+	}
+}
+
+// LogWebhooks logs every notification with its message, status and error
+// counts and how long handling it took.
+func LogWebhooks() WebhookMiddleware {
+	return func(next WebhookHandler) WebhookHandler {
+		return func(ctx context.Context, n *WebhookNotification) error {
+			start := time.Now()
+			err := next(ctx, n)
+			var messages, statuses int
+			for _, entry := range n.Entry {
+				for _, change := range entry.Changes {
+					messages += len(change.Value.Messages)
+					statuses += len(change.Value.Statuses)
+				}
+			}
+			attrs := []any{"messages", messages, "statuses", statuses, "duration", time.Since(start)}
+			if err != nil {
+				attrs = append(attrs, "error", err)
+			}
+			logger().Info("webhook handled", attrs...)
+			return err
+		}
+	}
+}
@@ -4,15 +4,27 @@ type AudioMessage struct {
 	MessagingProduct string `json:"messaging_product"`
 	To               string `json:"to"`
 	Type             string `json:"type"`
+	CallbackData     string `json:"biz_opaque_callback_data,omitempty"`
 	Audio            struct {
 		ID string `json:"id"` // Media ID from /media upload
 	} `json:"audio"`
 }
 
+type VideoMessage struct {
+	MessagingProduct string `json:"messaging_product"`
+	To               string `json:"to"`
+	Type             string `json:"type"`
+	CallbackData     string `json:"biz_opaque_callback_data,omitempty"`
+	Video            struct {
+		ID string `json:"id"`
+	} `json:"video"`
+}
+
 type ImageMessage struct {
 	MessagingProduct string `json:"messaging_product"`
 	To               string `json:"to"`
 	Type             string `json:"type"`
+	CallbackData     string `json:"biz_opaque_callback_data,omitempty"`
 	Image            struct {
 		ID string `json:"id"`
 	} `json:"image"`
@@ -22,6 +34,7 @@ type LocationMessage struct {
 	MessagingProduct string `json:"messaging_product"`
 	To               string `json:"to"`
 	Type             string `json:"type"`
+	CallbackData     string `json:"biz_opaque_callback_data,omitempty"`
 	Location         struct {
 		Latitude  float64 `json:"latitude"`
 		Longitude float64 `json:"longitude"`
@@ -36,6 +49,7 @@ type WhatsAppMessage struct {
 	To               string      `json:"to"`
 	Type             string      `json:"type"`
 	Interactive      interface{} `json:"interactive"` // Can be ListInteractive or ButtonsInteractive
+	CallbackData     string      `json:"biz_opaque_callback_data,omitempty"`
 }
 
 type ListInteractive struct {
@@ -99,7 +113,12 @@ type ButtonsInteractive struct {
 	Action ButtonAction `json:"action,omitempty"`
 }
 type MessageStatus struct {
-	MessagingProduct string `json:"messaging_product"`
-	Status           string `json:"status"`
-	MessageId        string `json:"message_id"`
+	MessagingProduct string           `json:"messaging_product"`
+	Status           string           `json:"status"`
+	MessageId        string           `json:"message_id"`
+	TypingIndicator  *TypingIndicator `json:"typing_indicator,omitempty"`
+}
+
+type TypingIndicator struct {
+	Type string `json:"type"`
 }
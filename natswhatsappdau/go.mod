@@ -0,0 +1,16 @@
+module github.com/daulet140/whatsappdau/natswhatsappdau
+
+go 1.25.0
+
+require (
+	github.com/daulet140/whatsappdau v0.0.0-20261015114746-210ef37890e8
+	github.com/nats-io/nats.go v1.53.0
+)
+
+require (
+	github.com/klauspost/compress v1.18.5 // indirect
+	github.com/nats-io/nkeys v0.4.15 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.49.0 // indirect
+	golang.org/x/sys v0.42.0 // indirect
+)
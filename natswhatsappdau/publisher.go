@@ -0,0 +1,40 @@
+// Package natswhatsappdau publishes whatsappdau webhook events to NATS. It
+// is a separate module so the core package stays free of the NATS client
+// dependency.
+package natswhatsappdau
+
+import (
+	"context"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/nats-io/nats.go"
+)
+
+// KeyHeader carries the wa_id of the conversation an event belongs to.
+const KeyHeader = "Wa-Id"
+
+// Publisher implements whatsappdau.Publisher, using topics as subjects:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	dispatcher.PublishTo(natswhatsappdau.NewPublisher(nc))
+type Publisher struct {
+	Conn *nats.Conn
+}
+
+var _ whatsappdau.Publisher = (*Publisher)(nil)
+
+func NewPublisher(nc *nats.Conn) *Publisher {
+	return &Publisher{Conn: nc}
+}
+
+func (p *Publisher) Publish(ctx context.Context, topic, key string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg := nats.NewMsg(topic)
+	msg.Data = data
+	if key != "" {
+		msg.Header.Set(KeyHeader, key)
+	}
+	return p.Conn.PublishMsg(msg)
+}
@@ -0,0 +1,75 @@
+package whatsappdau
+
+import (
+	"fmt"
+	"strings"
+)
+
+// trunkZeroKept lists calling codes whose national numbers keep their leading
+// zero after the country code.
+var trunkZeroKept = map[string]bool{"39": true}
+
+// CallingCode returns the international calling code of an ISO country, or
+// "" when it is not known.
+func CallingCode(country string) string {
+	country = strings.ToUpper(country)
+	switch country {
+	case "US", "CA":
+		return "1"
+	case "RU", "KZ":
+		return "7"
+	}
+	for code, c := range callingCodes {
+		if c == country {
+			return code
+		}
+	}
+	return ""
+}
+
+// NormalizePhone turns a user-entered phone number into a wa_id: digits only,
+// starting with the country code. Spaces, punctuation, "+" and "00" prefixes
+// are removed. Numbers in national format, starting with a trunk "0" (or "8"
+// in Russia and Kazakhstan) or of at most 10 digits, get the calling code of
+// defaultCountry; pass "" to require international format. A "(0)" written
+// after the country code is dropped.
+func NormalizePhone(input, defaultCountry string) (string, error) {
+	s := strings.TrimSpace(input)
+	if !strings.HasPrefix(s, "+39") {
+		s = strings.Replace(s, "(0)", "", 1)
+	}
+	international := strings.HasPrefix(s, "+")
+	digits := normalizeWAID(s)
+	if strings.HasPrefix(digits, "00") && !international {
+		digits, international = digits[2:], true
+	}
+
+	if !international {
+		code := CallingCode(defaultCountry)
+		switch {
+		case code == "7" && len(digits) == 11 && digits[0] == '8':
+			digits = code + digits[1:]
+		case strings.HasPrefix(digits, "0"):
+			if code == "" {
+				return "", &ValidationError{Field: "phone number", Reason: fmt.Sprintf("%q is in national format and no default country is set", input)}
+			}
+			if !trunkZeroKept[code] {
+				digits = digits[1:]
+			}
+			digits = code + digits
+		case code != "" && len(digits) <= 10:
+			digits = code + digits
+		}
+	}
+
+	if err := validateRecipient(digits); err != nil {
+		return "", &ValidationError{Field: "phone number", Reason: fmt.Sprintf("%q does not normalize to a valid number", input)}
+	}
+	return digits, nil
+}
+
+// ValidWAID reports whether s is a wa_id as the API expects it: 8-15 digits
+// with the country code and no "+".
+func ValidWAID(s string) bool {
+	return isWAID(s)
+}
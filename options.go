@@ -0,0 +1,83 @@
+package whatsappdau
+
+import "net/http"
+
+type Option func(*WhatsappClient)
+
+// WithLimiter adds l to the limiters consulted before every send. Limiters
+// run in the order they were added and the first refusal wins.
+func WithLimiter(l Limiter) Option {
+	return func(w *WhatsappClient) {
+		if b, ok := l.(clientBinder); ok {
+			b.bindClient(w)
+		}
+		w.limiters = append(w.limiters, l)
+	}
+}
+
+// WithoutValidation turns off the client-side checks done before sending,
+// leaving it to the Cloud API to reject bad payloads.
+func WithoutValidation() Option {
+	return func(w *WhatsappClient) {
+		w.skipValidation = true
+	}
+}
+
+// WithMessageSplitting makes SendMessage split bodies longer than
+// MaxTextBodyLength on word boundaries and send them as consecutive messages.
+// The response then carries one entry in Messages per part.
+func WithMessageSplitting() Option {
+	return func(w *WhatsappClient) {
+		w.splitLongText = true
+	}
+}
+
+// WithHTTPDoer sends requests through d instead of the *http.Client given to
+// NewWhatsappClient, e.g. a fetch-based doer under WASM.
+func WithHTTPDoer(d HTTPDoer) Option {
+	return func(w *WhatsappClient) {
+		w.client = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request. The last one
+// given wins.
+func WithUserAgent(ua string) Option {
+	return func(w *WhatsappClient) {
+		if w.headers == nil {
+			w.headers = make(http.Header)
+		}
+		w.headers.Set("User-Agent", ua)
+	}
+}
+
+// WithHeader adds a static header to every request, e.g. for routing through
+// an API gateway. It does not replace headers the client sets itself, such as
+// Authorization and Content-Type.
+func WithHeader(key, value string) Option {
+	return func(w *WhatsappClient) {
+		if w.headers == nil {
+			w.headers = make(http.Header)
+		}
+		w.headers.Add(key, value)
+	}
+}
+
+type headerDoer struct {
+	next    HTTPDoer
+	headers http.Header
+}
+
+func (d *headerDoer) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range d.headers {
+		if key == "User-Agent" {
+			req.Header[key] = values
+			continue
+		}
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = values
+		}
+	}
+	return d.next.Do(req)
+}
@@ -0,0 +1,68 @@
+// Package otelwhatsappdau reports whatsappdau API calls as OpenTelemetry
+// spans. It is a separate module so the core package stays free of the
+// OpenTelemetry dependency.
+package otelwhatsappdau
+
+import (
+	"context"
+
+	"github.com/daulet140/whatsappdau"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/daulet140/whatsappdau"
+
+// WithTracerProvider makes the client open a span per API call using tp, or
+// the global provider when tp is nil.
+func WithTracerProvider(tp trace.TracerProvider) whatsappdau.Option {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return whatsappdau.WithTracer(&tracer{tracer: tp.Tracer(instrumentationName)})
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) Start(ctx context.Context, operation string) (context.Context, whatsappdau.Span) {
+	ctx, span := t.tracer.Start(ctx, "whatsapp "+operation, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &callSpan{span: span}
+}
+
+type callSpan struct {
+	span trace.Span
+}
+
+func (s *callSpan) End(info whatsappdau.CallInfo) {
+	attrs := []attribute.KeyValue{
+		attribute.String("whatsapp.operation", info.Operation),
+		attribute.String("http.request.method", info.Method),
+		attribute.Int("http.request.resend_count", info.Attempt-1),
+	}
+	if info.StatusCode != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", info.StatusCode))
+	}
+	if info.MessageType != "" {
+		attrs = append(attrs, attribute.String("whatsapp.message.type", info.MessageType))
+	}
+	if info.RecipientHash != "" {
+		attrs = append(attrs, attribute.String("whatsapp.recipient.hash", info.RecipientHash))
+	}
+	if info.ErrorCode != 0 {
+		attrs = append(attrs, attribute.Int("whatsapp.error.code", info.ErrorCode))
+	}
+	s.span.SetAttributes(attrs...)
+
+	switch {
+	case info.Err != nil:
+		s.span.RecordError(info.Err)
+		s.span.SetStatus(codes.Error, info.Err.Error())
+	case info.StatusCode >= 400:
+		s.span.SetStatus(codes.Error, "API error")
+	}
+	s.span.End()
+}
@@ -0,0 +1,114 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxOTPLength is the longest code an authentication template accepts.
+const MaxOTPLength = 15
+
+// OTP button types of authentication templates.
+const (
+	OTPCopyCode = "COPY_CODE"
+	OTPOneTap   = "ONE_TAP"
+	OTPZeroTap  = "ZERO_TAP"
+)
+
+// NewAuthenticationMessage fills an authentication template with code. The
+// code goes in the body and in the copy-code or one-tap button, which both
+// expect it.
+func NewAuthenticationMessage(name, language, code string) TemplateMessage {
+	param := []TemplateParameter{{Type: "text", Text: code}}
+	return TemplateMessage{
+		Name:     name,
+		Language: TemplateLanguage{Code: language},
+		Components: []TemplateComponent{
+			{Type: "body", Parameters: param},
+			{Type: "button", SubType: "url", Index: "0", Parameters: param},
+		},
+	}
+}
+
+// SendOTP sends code with the authentication template name.
+func (w *WhatsappClient) SendOTP(ctx context.Context, recipientWAID, name, language, code string, opts ...SendOption) (*MessageResponse, error) {
+	if err := w.validate(validateRequired("code", code), validateLength("code", code, MaxOTPLength)); err != nil {
+		return nil, err
+	}
+	return w.SendTemplate(ctx, recipientWAID, NewAuthenticationMessage(name, language, code), opts...)
+}
+
+// AuthenticationTemplate describes an authentication template to create.
+// Meta supplies the body text; only its options are configurable.
+type AuthenticationTemplate struct {
+	Name     string
+	Language string
+	// OTPType is OTPCopyCode, OTPOneTap or OTPZeroTap.
+	OTPType string
+	// ButtonText is the copy-code button label, or the fallback label of a
+	// one-tap button.
+	ButtonText string
+	// AutofillText labels the one-tap button.
+	AutofillText string
+	// PackageName and SignatureHash identify the Android app receiving
+	// one-tap and zero-tap codes.
+	PackageName   string
+	SignatureHash string
+	// AddSecurityRecommendation appends "For your security, do not share
+	// this code." to the body.
+	AddSecurityRecommendation bool
+	// CodeExpirationMinutes, 1-90, adds an expiry notice footer. 0 omits it.
+	CodeExpirationMinutes int
+}
+
+// CreatedTemplate is the API's answer to a template creation.
+type CreatedTemplate struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Category string `json:"category"`
+}
+
+// CreateAuthenticationTemplate submits t for review in the business account
+// wabaID.
+func (w *WhatsappClient) CreateAuthenticationTemplate(ctx context.Context, wabaID string, t AuthenticationTemplate) (*CreatedTemplate, error) {
+	if t.OTPType == "" {
+		t.OTPType = OTPCopyCode
+	}
+	if t.CodeExpirationMinutes < 0 || t.CodeExpirationMinutes > 90 {
+		return nil, &ValidationError{Field: "code expiration", Reason: fmt.Sprintf("%d minutes is outside 1-90", t.CodeExpirationMinutes)}
+	}
+	if t.OTPType != OTPCopyCode && (t.PackageName == "" || t.SignatureHash == "") {
+		return nil, &ValidationError{Field: "otp button", Reason: t.OTPType + " needs PackageName and SignatureHash"}
+	}
+
+	button := map[string]interface{}{"type": "OTP", "otp_type": t.OTPType}
+	if t.ButtonText != "" {
+		button["text"] = t.ButtonText
+	}
+	if t.OTPType != OTPCopyCode {
+		button["package_name"] = t.PackageName
+		button["signature_hash"] = t.SignatureHash
+		if t.AutofillText != "" {
+			button["autofill_text"] = t.AutofillText
+		}
+	}
+	components := []map[string]interface{}{
+		{"type": "BODY", "add_security_recommendation": t.AddSecurityRecommendation},
+	}
+	if t.CodeExpirationMinutes > 0 {
+		components = append(components, map[string]interface{}{"type": "FOOTER", "code_expiration_minutes": t.CodeExpirationMinutes})
+	}
+	components = append(components, map[string]interface{}{"type": "BUTTONS", "buttons": []interface{}{button}})
+
+	payload := map[string]interface{}{
+		"name":       t.Name,
+		"language":   t.Language,
+		"category":   CategoryAuthentication,
+		"components": components,
+	}
+	var created CreatedTemplate
+	if err := w.callGraph(ctx, "POST", w.graphURL()+"/"+wabaID+"/message_templates", payload, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
@@ -0,0 +1,577 @@
+package whatsappdau
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OutboxEntry is a queued message. Payload is the JSON body posted with
+// SendRaw, so any message type survives a restart.
+type OutboxEntry struct {
+	ID          string          `json:"id"`
+	Recipient   string          `json:"recipient"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// OutboxStore persists queued messages. Put inserts or replaces an entry by
+// ID; Due returns up to limit entries whose NextAttempt is not after now,
+// oldest first.
+type OutboxStore interface {
+	Put(ctx context.Context, e OutboxEntry) error
+	Due(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+}
+
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{entries: make(map[string]OutboxEntry)}
+}
+
+func (s *MemoryOutboxStore) Put(ctx context.Context, e OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[e.ID] = e
+	return nil
+}
+
+func (s *MemoryOutboxStore) Due(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dueEntries(s.entries, now, limit), nil
+}
+
+func (s *MemoryOutboxStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *MemoryOutboxStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func dueEntries(entries map[string]OutboxEntry, now time.Time, limit int) []OutboxEntry {
+	var due []OutboxEntry
+	for _, e := range entries {
+		if !e.NextAttempt.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
+
+// FileOutboxStore keeps the queue in a single file, rewritten atomically on
+// every change. It suits a single process with a modest queue; use a
+// database-backed store otherwise.
+type FileOutboxStore struct {
+	Path       string
+	Serializer *Serializer
+
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+}
+
+const outboxFileType = "whatsappdau.outbox"
+
+// NewFileOutboxStore loads the queue at path, which need not exist yet.
+func NewFileOutboxStore(path string, s *Serializer) (*FileOutboxStore, error) {
+	if s == nil {
+		s = NewSerializer(nil)
+	}
+	store := &FileOutboxStore{Path: path, Serializer: s, entries: make(map[string]OutboxEntry)}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	var list []OutboxEntry
+	if err := s.Decode(raw, outboxFileType, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode outbox: %w", err)
+	}
+	for _, e := range list {
+		store.entries[e.ID] = e
+	}
+	return store, nil
+}
+
+func (s *FileOutboxStore) Put(ctx context.Context, e OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, had := s.entries[e.ID]
+	s.entries[e.ID] = e
+	if err := s.flush(); err != nil {
+		if had {
+			s.entries[e.ID] = prev
+		} else {
+			delete(s.entries, e.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileOutboxStore) Due(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dueEntries(s.entries, now, limit), nil
+}
+
+func (s *FileOutboxStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, had := s.entries[id]
+	if !had {
+		return nil
+	}
+	delete(s.entries, id)
+	if err := s.flush(); err != nil {
+		s.entries[id] = prev
+		return err
+	}
+	return nil
+}
+
+func (s *FileOutboxStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func (s *FileOutboxStore) flush() error {
+	list := make([]OutboxEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, e)
+	}
+	raw, err := s.Serializer.Encode(outboxFileType, list)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
+
+// Outbox queues messages in a store and sends them from a background worker,
+// retrying failures with exponential backoff. An entry is only removed after
+// the API accepted it, so delivery is at-least-once across restarts.
+type Outbox struct {
+	Client MessageSender
+	Store  OutboxStore
+	// MaxAttempts is how many sends are tried before giving up. Default 5.
+	// Sends a Limiter deferred with a *LimitError don't count.
+	MaxAttempts int
+	// Backoff is the delay after the first failure, doubled for each further
+	// one up to MaxBackoff. Defaults 5s and 10m.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// PollInterval is how often the worker looks for due entries. Default 1s.
+	PollInterval time.Duration
+	// OnSent, if set, is called after an entry is accepted by the API.
+	OnSent func(e OutboxEntry, resp *MessageResponse)
+	// OnFailure, if set, is called when an entry is dropped, either after
+	// MaxAttempts or on an error retrying cannot fix.
+	OnFailure func(e OutboxEntry, err error)
+	// DeadLetters, if set, keeps dropped entries, with LastError set, for
+	// inspection or replay instead of discarding them. An entry is put there
+	// before it is deleted from Store, so a crash in between leaves it in
+	// both and it is retried once more; dead-lettering it again replaces the
+	// copy with the same ID.
+	DeadLetters OutboxStore
+	// MaxPending, if positive, bounds the entries in a store with a Len
+	// method, such as MemoryOutboxStore and FileOutboxStore.
+	MaxPending int
+	// Pressure holds back Enqueue and the worker while sends are throttled.
+	// It defaults to the client's own Backpressure.
+	Pressure *Backpressure
+	// BlockWhenBusy makes Enqueue wait, instead of failing with ErrBusy,
+	// while sends are throttled or MaxPending entries are queued.
+	BlockWhenBusy bool
+
+	now  func() time.Time
+	wake chan struct{}
+
+	mu      sync.Mutex
+	stop    context.CancelFunc
+	stopped chan struct{}
+}
+
+func NewOutbox(client MessageSender, store OutboxStore) *Outbox {
+	if store == nil {
+		store = NewMemoryOutboxStore()
+	}
+	return &Outbox{
+		Client:       client,
+		Store:        store,
+		MaxAttempts:  5,
+		Backoff:      5 * time.Second,
+		MaxBackoff:   10 * time.Minute,
+		PollInterval: time.Second,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Enqueue queues payload, a message as accepted by SendRaw, and returns the
+// entry ID. See BlockWhenBusy for when the outbox is busy.
+func (o *Outbox) Enqueue(ctx context.Context, payload interface{}) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling JSON: %w", err)
+	}
+	var msg struct {
+		To string `json:"to"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil || msg.To == "" {
+		return "", errors.New("outbox payload has no recipient")
+	}
+	if err := o.admit(ctx); err != nil {
+		return "", err
+	}
+	id, err := newOutboxID()
+	if err != nil {
+		return "", err
+	}
+	now := o.clock()
+	e := OutboxEntry{ID: id, Recipient: msg.To, Payload: raw, NextAttempt: now, CreatedAt: now}
+	if err := o.Store.Put(ctx, e); err != nil {
+		return "", fmt.Errorf("failed to enqueue message: %w", err)
+	}
+	select {
+	case o.wakeup() <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// EnqueueText queues a text message.
+func (o *Outbox) EnqueueText(ctx context.Context, to, body string, opts ...SendOption) (string, error) {
+	so := collectSendOptions(opts)
+	if err := so.validate(); err != nil {
+		return "", err
+	}
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]string{"body": body},
+	}
+	if so.callbackData != "" {
+		payload["biz_opaque_callback_data"] = so.callbackData
+	}
+	return o.Enqueue(ctx, payload)
+}
+
+// Run sends due entries until ctx is done. Errors of a pass are logged, see
+// SetLogger, and leave the entries in place for the next one.
+func (o *Outbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.pollInterval())
+	defer ticker.Stop()
+	wake := o.wakeup()
+	for {
+		if err := o.Flush(ctx); err != nil && ctx.Err() == nil {
+			logger().Error("outbox flush failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// Start runs the worker in the background until Shutdown or until ctx is
+// done.
+func (o *Outbox) Start(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stop != nil {
+		return errors.New("outbox already started")
+	}
+	ctx, o.stop = context.WithCancel(ctx)
+	o.stopped = make(chan struct{})
+	go func() {
+		defer close(o.stopped)
+		o.Run(ctx)
+	}()
+	return nil
+}
+
+// Shutdown stops the worker and then sends every entry due now, so nothing
+// queued before shutting down waits for the next start. Entries backing off
+// after a failure stay in the store.
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	o.mu.Lock()
+	stop, stopped := o.stop, o.stopped
+	o.stop, o.stopped = nil, nil
+	o.mu.Unlock()
+	if stop != nil {
+		stop()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return o.drain(ctx)
+}
+
+// drain flushes until no entry is due.
+func (o *Outbox) drain(ctx context.Context) error {
+	for {
+		due, err := o.Store.Due(ctx, o.clock(), 1)
+		if err != nil {
+			return fmt.Errorf("failed to load outbox: %w", err)
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		if p := pressureOf(o.Pressure, o.Client); p != nil {
+			if err := p.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		if err := o.Flush(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Flush makes one pass over the entries due now.
+func (o *Outbox) Flush(ctx context.Context) error {
+	due, err := o.Store.Due(ctx, o.clock(), 100)
+	if err != nil {
+		return fmt.Errorf("failed to load outbox: %w", err)
+	}
+	pressure := pressureOf(o.Pressure, o.Client)
+	for _, e := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if pressure != nil {
+			if _, throttled := pressure.Throttled(); throttled {
+				// The entries stay due for a later pass.
+				return nil
+			}
+		}
+		if err := o.attempt(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Outbox) attempt(ctx context.Context, e OutboxEntry) error {
+	resp, sendErr := o.Client.SendRaw(ctx, e.Payload)
+	if p := pressureOf(o.Pressure, o.Client); p != nil {
+		p.Observe(sendErr)
+	}
+	if sendErr == nil {
+		if err := o.Store.Delete(ctx, e.ID); err != nil {
+			return fmt.Errorf("failed to remove sent message: %w", err)
+		}
+		if o.OnSent != nil {
+			o.OnSent(e, resp)
+		}
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	e.LastError = sendErr.Error()
+	var limitErr *LimitError
+	if errors.As(sendErr, &limitErr) {
+		// A limiter deferral is not a failed attempt; the entry waits for the
+		// next free slot however long that takes. Entries deferred together
+		// are spread out so they don't all contend for that one slot.
+		e.NextAttempt = limitErr.RetryAt.Add(o.spread())
+		if err := o.Store.Put(ctx, e); err != nil {
+			return fmt.Errorf("failed to reschedule message: %w", err)
+		}
+		return nil
+	}
+
+	e.Attempts++
+	if e.Attempts >= o.maxAttempts() || !outboxRetryable(sendErr) {
+		if o.DeadLetters != nil {
+			if err := o.DeadLetters.Put(ctx, e); err != nil {
+				return fmt.Errorf("failed to dead-letter message: %w", err)
+			}
+		}
+		if err := o.Store.Delete(ctx, e.ID); err != nil {
+			return fmt.Errorf("failed to remove failed message: %w", err)
+		}
+		if o.OnFailure != nil {
+			o.OnFailure(e, sendErr)
+		}
+		return nil
+	}
+	e.NextAttempt = o.clock().Add(o.backoff(e.Attempts))
+	if err := o.Store.Put(ctx, e); err != nil {
+		return fmt.Errorf("failed to reschedule message: %w", err)
+	}
+	return nil
+}
+
+// admit returns nil once the outbox can take another entry, or ErrBusy if
+// it cannot and BlockWhenBusy is off.
+func (o *Outbox) admit(ctx context.Context) error {
+	for {
+		wait, busy := o.busy()
+		if !busy {
+			return nil
+		}
+		if !o.BlockWhenBusy {
+			return ErrBusy
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// busy reports whether the outbox should refuse entries and how long to
+// wait before asking again.
+func (o *Outbox) busy() (time.Duration, bool) {
+	if p := pressureOf(o.Pressure, o.Client); p != nil {
+		if until, throttled := p.Throttled(); throttled {
+			return until.Sub(o.clock()), true
+		}
+	}
+	if o.MaxPending > 0 {
+		if store, ok := o.Store.(interface{ Len() int }); ok && store.Len() >= o.MaxPending {
+			return o.pollInterval(), true
+		}
+	}
+	return 0, false
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	d, limit := o.baseBackoff(), o.MaxBackoff
+	if limit <= 0 {
+		limit = 10 * time.Minute
+	}
+	for i := 1; i < attempts && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	return d
+}
+
+// spread returns a random delay of up to Backoff.
+func (o *Outbox) spread() time.Duration {
+	return mrand.N(o.baseBackoff())
+}
+
+// The accessors below apply the documented defaults, so an Outbox built
+// as a literal rather than with NewOutbox works too.
+
+func (o *Outbox) baseBackoff() time.Duration {
+	if o.Backoff <= 0 {
+		return 5 * time.Second
+	}
+	return o.Backoff
+}
+
+func (o *Outbox) maxAttempts() int {
+	if o.MaxAttempts <= 0 {
+		return 5
+	}
+	return o.MaxAttempts
+}
+
+func (o *Outbox) pollInterval() time.Duration {
+	if o.PollInterval <= 0 {
+		return time.Second
+	}
+	return o.PollInterval
+}
+
+func (o *Outbox) clock() time.Time {
+	if o.now == nil {
+		return time.Now()
+	}
+	return o.now()
+}
+
+func (o *Outbox) wakeup() chan struct{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.wake == nil {
+		o.wake = make(chan struct{}, 1)
+	}
+	return o.wake
+}
+
+// outboxRetryable reports whether a failed send may succeed later. The API
+// rejecting the request itself is final, except for throttling.
+func outboxRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500 || errors.Is(err, ErrRateLimited)
+	}
+	var valErr *ValidationError
+	return !errors.As(err, &valErr)
+}
+
+func newOutboxID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate outbox ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
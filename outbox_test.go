@@ -0,0 +1,172 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedSender fails SendRaw with errs in turn, then succeeds.
+type scriptedSender struct {
+	MessageSender
+	errs []error
+}
+
+func (s *scriptedSender) SendRaw(ctx context.Context, payload interface{}) (*MessageResponse, error) {
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return &MessageResponse{Messages: []Messages{{Id: "wamid.1"}}}, nil
+}
+
+func TestOutboxAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	retryAt := now.Add(20 * time.Hour)
+	limitErr := &LimitError{Limit: 250, RetryAt: retryAt, Reason: "messaging tier TIER_250"}
+	tests := []struct {
+		name     string
+		errs     []error
+		sent     bool
+		dropped  bool
+		attempts int
+	}{
+		{"success", nil, true, false, 0},
+		{"limit deferrals are not attempts", []error{limitErr, limitErr, limitErr, limitErr, limitErr, limitErr}, true, false, 0},
+		{"throttled", []error{&APIError{StatusCode: http.StatusTooManyRequests}}, true, false, 1},
+		{"server errors until MaxAttempts", []error{
+			&APIError{StatusCode: 500}, &APIError{StatusCode: 500}, &APIError{StatusCode: 500},
+			&APIError{StatusCode: 500}, &APIError{StatusCode: 500},
+		}, false, true, 5},
+		{"rejected", []error{&APIError{StatusCode: http.StatusBadRequest, Code: ErrCodeReengagementRequired}}, false, true, 1},
+		{"invalid", []error{&ValidationError{Field: "to", Reason: "empty"}}, false, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &scriptedSender{errs: tt.errs}
+			store := NewMemoryOutboxStore()
+			o := NewOutbox(sender, store)
+			o.now = func() time.Time { return now }
+			var sent, dropped bool
+			var last OutboxEntry
+			o.OnSent = func(OutboxEntry, *MessageResponse) { sent = true }
+			o.OnFailure = func(e OutboxEntry, err error) { dropped, last = true, e }
+
+			e := OutboxEntry{ID: "1", Recipient: "15551234567", Payload: []byte(`{"to":"15551234567"}`), NextAttempt: now, CreatedAt: now}
+			for i := 0; i <= len(tt.errs) && !sent && !dropped; i++ {
+				if err := o.attempt(context.Background(), e); err != nil {
+					t.Fatal(err)
+				}
+				if entries := dueEntries(store.entries, time.Unix(1<<40, 0), 0); len(entries) == 1 {
+					e = entries[0]
+				}
+			}
+			if sent != tt.sent || dropped != tt.dropped {
+				t.Fatalf("sent %v dropped %v, want %v %v", sent, dropped, tt.sent, tt.dropped)
+			}
+			if dropped {
+				e = last
+			}
+			if e.Attempts != tt.attempts {
+				t.Errorf("attempts %d, want %d", e.Attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestOutboxSpreadsLimitDeferrals(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	retryAt := now.Add(time.Hour)
+	store := NewMemoryOutboxStore()
+	var errs []error
+	for i := 0; i < 20; i++ {
+		errs = append(errs, &LimitError{Limit: 250, RetryAt: retryAt})
+	}
+	o := NewOutbox(&scriptedSender{errs: errs}, store)
+	o.now = func() time.Time { return now }
+	for i := 0; i < 20; i++ {
+		e := OutboxEntry{ID: string(rune('a' + i)), Payload: []byte(`{}`), NextAttempt: now, CreatedAt: now}
+		if err := o.attempt(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	distinct := make(map[time.Time]bool)
+	for _, e := range store.entries {
+		if e.NextAttempt.Before(retryAt) || !e.NextAttempt.Before(retryAt.Add(o.Backoff)) {
+			t.Errorf("next attempt %v outside [%v, +%v)", e.NextAttempt, retryAt, o.Backoff)
+		}
+		distinct[e.NextAttempt] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("deferred entries all retry at %v", retryAt)
+	}
+}
+
+func TestOutboxRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("connection reset"), true},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{&APIError{StatusCode: http.StatusBadGateway}, true},
+		{&APIError{StatusCode: http.StatusBadRequest, Code: ErrCodeThroughputExceeded}, true},
+		{&APIError{StatusCode: http.StatusBadRequest, Code: ErrCodeReengagementRequired}, false},
+		{&ValidationError{Field: "to"}, false},
+	}
+	for _, tt := range tests {
+		if got := outboxRetryable(tt.err); got != tt.want {
+			t.Errorf("outboxRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// flakyOutboxStore fails the first Due call.
+type flakyOutboxStore struct {
+	*MemoryOutboxStore
+	failed bool
+}
+
+func (s *flakyOutboxStore) Due(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error) {
+	if !s.failed {
+		s.failed = true
+		return nil, errors.New("store unavailable")
+	}
+	return s.MemoryOutboxStore.Due(ctx, now, limit)
+}
+
+func TestOutboxLiteralRunsAndLogsFlushErrors(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	store := &flakyOutboxStore{MemoryOutboxStore: NewMemoryOutboxStore()}
+	o := &Outbox{Store: store, Client: &scriptedSender{}}
+	if _, err := o.EnqueueText(context.Background(), "15551234567", "hi"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- o.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	if store.Len() != 0 {
+		t.Error("entry was not sent")
+	}
+	if !strings.Contains(buf.String(), "store unavailable") {
+		t.Errorf("flush error not logged: %q", buf.String())
+	}
+}
@@ -0,0 +1,125 @@
+package whatsappdau
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSent      = "sent"
+	OutcomeDelivered = "delivered"
+	OutcomeRead      = "read"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+)
+
+// MessageOutcome is what happened to one recipient of a campaign.
+type MessageOutcome struct {
+	Recipient string `json:"recipient"`
+	MessageID string `json:"message_id,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	// CallbackData is the biz_opaque_callback_data reported by status
+	// webhooks for the message.
+	CallbackData string    `json:"callback_data,omitempty"`
+	SentAt       time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+// OutcomeLog collects send results and later status webhooks into one
+// MessageOutcome per recipient, ready for export.
+type OutcomeLog struct {
+	// OnChange, if set, is called with every new or updated outcome.
+	OnChange func(MessageOutcome)
+
+	mu      sync.Mutex
+	order   []string
+	byRcpt  map[string]*MessageOutcome
+	byMsgID map[string]*MessageOutcome
+}
+
+func NewOutcomeLog() *OutcomeLog {
+	return &OutcomeLog{
+		byRcpt:  make(map[string]*MessageOutcome),
+		byMsgID: make(map[string]*MessageOutcome),
+	}
+}
+
+// RecordSend stores the result of sending to recipient.
+func (l *OutcomeLog) RecordSend(recipient string, resp *MessageResponse, err error) {
+	now := time.Now()
+	outcome := &MessageOutcome{Recipient: recipient, Status: OutcomeSent, SentAt: now, UpdatedAt: now}
+	if err != nil {
+		outcome.Status = OutcomeFailed
+		outcome.Error = err.Error()
+	} else if resp != nil && len(resp.Messages) > 0 {
+		outcome.MessageID = resp.Messages[0].Id
+	}
+	l.put(outcome)
+}
+
+// RecordSkip stores a recipient that was deliberately not sent to.
+func (l *OutcomeLog) RecordSkip(recipient, reason string) {
+	l.put(&MessageOutcome{Recipient: recipient, Status: OutcomeSkipped, Error: reason, UpdatedAt: time.Now()})
+}
+
+func (l *OutcomeLog) put(outcome *MessageOutcome) {
+	l.mu.Lock()
+	if _, ok := l.byRcpt[outcome.Recipient]; !ok {
+		l.order = append(l.order, outcome.Recipient)
+	}
+	l.byRcpt[outcome.Recipient] = outcome
+	if outcome.MessageID != "" {
+		l.byMsgID[outcome.MessageID] = outcome
+	}
+	snapshot := *outcome
+	l.mu.Unlock()
+
+	if l.OnChange != nil {
+		l.OnChange(snapshot)
+	}
+}
+
+var outcomeRank = map[string]int{
+	OutcomeSent:      1,
+	OutcomeDelivered: 2,
+	OutcomeRead:      3,
+}
+
+// ApplyStatus updates the outcome for the message a status webhook refers to.
+// Statuses never move backwards, since Meta may deliver them out of order;
+// failed always wins. It reports whether the message was known.
+func (l *OutcomeLog) ApplyStatus(status StatusUpdate) bool {
+	l.mu.Lock()
+	outcome, ok := l.byMsgID[status.ID]
+	if !ok {
+		l.mu.Unlock()
+		return false
+	}
+	if status.CallbackData != "" {
+		outcome.CallbackData = status.CallbackData
+	}
+	if status.Status != OutcomeFailed && outcomeRank[status.Status] <= outcomeRank[outcome.Status] {
+		l.mu.Unlock()
+		return true
+	}
+	outcome.Status = status.Status
+	outcome.UpdatedAt = parseWebhookTime(status.Timestamp)
+	snapshot := *outcome
+	l.mu.Unlock()
+
+	if l.OnChange != nil {
+		l.OnChange(snapshot)
+	}
+	return true
+}
+
+func (l *OutcomeLog) Outcomes() []MessageOutcome {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	outcomes := make([]MessageOutcome, 0, len(l.order))
+	for _, rcpt := range l.order {
+		outcomes = append(outcomes, *l.byRcpt[rcpt])
+	}
+	return outcomes
+}
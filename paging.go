@@ -0,0 +1,93 @@
+package whatsappdau
+
+import (
+	"context"
+	"net/url"
+)
+
+// Paginator walks a cursor-paginated Graph list one page at a time:
+//
+//	p := client.Templates(wabaID)
+//	for p.Next(ctx) {
+//		for _, t := range p.Page() { ... }
+//	}
+//	if err := p.Err(); err != nil { ... }
+type Paginator[T any] struct {
+	w    *WhatsappClient
+	next string
+	page []T
+	// after is the cursor of the current page, for resuming later.
+	after   string
+	err     error
+	started bool
+}
+
+func newPaginator[T any](w *WhatsappClient, endpoint string) *Paginator[T] {
+	return &Paginator[T]{w: w, next: endpoint}
+}
+
+// StartAfter resumes the listing after cursor, as returned by Cursor. It
+// must be called before the first Next.
+func (p *Paginator[T]) StartAfter(cursor string) *Paginator[T] {
+	if p.started || cursor == "" {
+		return p
+	}
+	if u, err := url.Parse(p.next); err == nil {
+		q := u.Query()
+		q.Set("after", cursor)
+		u.RawQuery = q.Encode()
+		p.next = u.String()
+	}
+	return p
+}
+
+// Next fetches the next page and reports whether there was one.
+func (p *Paginator[T]) Next(ctx context.Context) bool {
+	p.started = true
+	if p.err != nil || p.next == "" {
+		return false
+	}
+	var response struct {
+		Data   []T `json:"data"`
+		Paging struct {
+			Cursors struct {
+				Before string `json:"before"`
+				After  string `json:"after"`
+			} `json:"cursors"`
+			Next string `json:"next"`
+		} `json:"paging"`
+	}
+	if err := p.w.callGraph(ctx, "GET", p.next, nil, &response); err != nil {
+		p.err = err
+		p.page = nil
+		return false
+	}
+	p.page = response.Data
+	p.after = response.Paging.Cursors.After
+	// Graph omits next on the last page, even when cursors are present.
+	p.next = response.Paging.Next
+	return true
+}
+
+func (p *Paginator[T]) Page() []T {
+	return p.page
+}
+
+// Cursor is the after cursor of the current page. Pass it to StartAfter on
+// a new Paginator to continue from the following page.
+func (p *Paginator[T]) Cursor() string {
+	return p.after
+}
+
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// All fetches the remaining pages and returns their items.
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.Next(ctx) {
+		all = append(all, p.page...)
+	}
+	return all, p.err
+}
@@ -0,0 +1,115 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// phoneNumberURL is the Graph node of the phone number the client sends from.
+// When apiURL does not have the usual layout it is taken to be the messages
+// endpoint under that node.
+func (w *WhatsappClient) phoneNumberURL() string {
+	if w.endpoint.PhoneNumberID != "" {
+		return w.endpoint.PhoneNumberURL()
+	}
+	return strings.TrimSuffix(strings.TrimRight(w.apiURL, "/"), "/messages")
+}
+
+func (w *WhatsappClient) mediaURL() string {
+	return w.phoneNumberURL() + "/media"
+}
+
+func (w *WhatsappClient) MessagingLimitTier(ctx context.Context) (MessagingTier, error) {
+	limit, err := w.MessagingLimit(ctx)
+	if err != nil {
+		return "", err
+	}
+	return limit.Tier, nil
+}
+
+// MessagingLimit describes how much business-initiated traffic the phone
+// number may send.
+type MessagingLimit struct {
+	Tier MessagingTier
+	// MaxDailyConversations is the number of unique users the number may
+	// start conversations with per rolling 24h, or -1 when unlimited.
+	MaxDailyConversations int
+	QualityRating         string
+	Throughput            string
+}
+
+func (w *WhatsappClient) MessagingLimit(ctx context.Context) (*MessagingLimit, error) {
+	var response struct {
+		MessagingLimitTier MessagingTier `json:"messaging_limit_tier"`
+		QualityRating      string        `json:"quality_rating"`
+		Throughput         struct {
+			Level string `json:"level"`
+		} `json:"throughput"`
+	}
+	endpoint := w.phoneNumberURL() + "?fields=messaging_limit_tier,quality_rating,throughput"
+	if err := w.callGraph(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+	return &MessagingLimit{
+		Tier:                  response.MessagingLimitTier,
+		MaxDailyConversations: response.MessagingLimitTier.Limit(),
+		QualityRating:         response.QualityRating,
+		Throughput:            response.Throughput.Level,
+	}, nil
+}
+
+// graphURL is the versioned Graph API root, e.g.
+// https://graph.facebook.com/v24.0.
+func (w *WhatsappClient) graphURL() string {
+	u := w.phoneNumberURL()
+	if i := strings.LastIndex(u, "/"); i > 0 {
+		return u[:i]
+	}
+	return u
+}
+
+// Register registers the phone number for Cloud API use, setting its
+// two-step verification pin.
+func (w *WhatsappClient) Register(ctx context.Context, pin string) error {
+	if len(pin) != 6 {
+		return &ValidationError{Field: "pin", Reason: "must be 6 digits"}
+	}
+	return w.postPhoneNumberAction(ctx, "register", map[string]string{
+		"messaging_product": "whatsapp",
+		"pin":               pin,
+	})
+}
+
+// Deregister removes the phone number from the Cloud API.
+func (w *WhatsappClient) Deregister(ctx context.Context) error {
+	return w.postPhoneNumberAction(ctx, "deregister", nil)
+}
+
+func (w *WhatsappClient) postPhoneNumberAction(ctx context.Context, action string, payload interface{}) error {
+	var response struct {
+		Success bool `json:"success"`
+	}
+	if err := w.callGraph(ctx, "POST", w.phoneNumberURL()+"/"+action, payload, &response); err != nil {
+		return err
+	}
+	if !response.Success {
+		return fmt.Errorf("%s: API did not report success", action)
+	}
+	return nil
+}
+
+// BlockedUser is a WhatsApp user the phone number has blocked.
+type BlockedUser struct {
+	MessagingProduct string `json:"messaging_product"`
+	WaID             string `json:"wa_id"`
+}
+
+// BlockedUsers pages through the users blocked by the client's phone number.
+func (w *WhatsappClient) BlockedUsers() *Paginator[BlockedUser] {
+	return newPaginator[BlockedUser](w, w.phoneNumberURL()+"/block_users?limit=100")
+}
+
+func (w *WhatsappClient) ListBlockedUsers(ctx context.Context) ([]BlockedUser, error) {
+	return w.BlockedUsers().All(ctx)
+}
@@ -0,0 +1,155 @@
+package whatsappdau
+
+import (
+	"sort"
+	"strings"
+)
+
+// Template and conversation categories, which decide the price.
+const (
+	CategoryMarketing      = "MARKETING"
+	CategoryUtility        = "UTILITY"
+	CategoryAuthentication = "AUTHENTICATION"
+	CategoryService        = "SERVICE"
+)
+
+// PriceList holds the price of a conversation per country and category.
+// Countries are ISO 3166 alpha-2 codes; a country missing from Countries is
+// charged at the Other rates.
+type PriceList struct {
+	Currency  string
+	Countries map[string]map[string]float64
+	Other     map[string]float64
+}
+
+// DefaultPriceList is an approximation of Meta's USD rate card for the
+// larger markets. Rates change several times a year; build a PriceList from
+// the current card for anything that is billed.
+var DefaultPriceList = PriceList{
+	Currency: "USD",
+	Countries: map[string]map[string]float64{
+		"US": {CategoryMarketing: 0.0250, CategoryUtility: 0.0040, CategoryAuthentication: 0.0135},
+		"CA": {CategoryMarketing: 0.0250, CategoryUtility: 0.0040, CategoryAuthentication: 0.0135},
+		"BR": {CategoryMarketing: 0.0625, CategoryUtility: 0.0080, CategoryAuthentication: 0.0315},
+		"MX": {CategoryMarketing: 0.0436, CategoryUtility: 0.0100, CategoryAuthentication: 0.0239},
+		"GB": {CategoryMarketing: 0.0705, CategoryUtility: 0.0220, CategoryAuthentication: 0.0358},
+		"DE": {CategoryMarketing: 0.1365, CategoryUtility: 0.0550, CategoryAuthentication: 0.0768},
+		"FR": {CategoryMarketing: 0.1432, CategoryUtility: 0.0300, CategoryAuthentication: 0.0689},
+		"IT": {CategoryMarketing: 0.0691, CategoryUtility: 0.0300, CategoryAuthentication: 0.0378},
+		"ES": {CategoryMarketing: 0.0615, CategoryUtility: 0.0200, CategoryAuthentication: 0.0342},
+		"RU": {CategoryMarketing: 0.0802, CategoryUtility: 0.0400, CategoryAuthentication: 0.0406},
+		"TR": {CategoryMarketing: 0.0109, CategoryUtility: 0.0053, CategoryAuthentication: 0.0083},
+		"IN": {CategoryMarketing: 0.0107, CategoryUtility: 0.0014, CategoryAuthentication: 0.0014},
+		"ID": {CategoryMarketing: 0.0411, CategoryUtility: 0.0250, CategoryAuthentication: 0.0300},
+		"AE": {CategoryMarketing: 0.0384, CategoryUtility: 0.0157, CategoryAuthentication: 0.0178},
+		"SA": {CategoryMarketing: 0.0455, CategoryUtility: 0.0115, CategoryAuthentication: 0.0107},
+		"NG": {CategoryMarketing: 0.0516, CategoryUtility: 0.0067, CategoryAuthentication: 0.0289},
+		"ZA": {CategoryMarketing: 0.0379, CategoryUtility: 0.0076, CategoryAuthentication: 0.0200},
+	},
+	Other: map[string]float64{CategoryMarketing: 0.0604, CategoryUtility: 0.0077, CategoryAuthentication: 0.0280},
+}
+
+// Price is the cost of one conversation of category with a number in
+// country. Categories without a rate, such as SERVICE, cost nothing.
+func (p PriceList) Price(country, category string) float64 {
+	if rates, ok := p.Countries[strings.ToUpper(country)]; ok {
+		return rates[strings.ToUpper(category)]
+	}
+	return p.Other[strings.ToUpper(category)]
+}
+
+// callingCodes maps international calling codes to the country billed for
+// them. Shared codes map to the largest market (1 to US, 7 to RU), with the
+// known exceptions listed by longer prefix.
+var callingCodes = map[string]string{
+	"1": "US", "7": "RU", "76": "KZ", "77": "KZ",
+	"20": "EG", "27": "ZA", "30": "GR", "31": "NL", "32": "BE", "33": "FR",
+	"34": "ES", "36": "HU", "39": "IT", "40": "RO", "41": "CH", "43": "AT",
+	"44": "GB", "45": "DK", "46": "SE", "47": "NO", "48": "PL", "49": "DE",
+	"51": "PE", "52": "MX", "54": "AR", "55": "BR", "56": "CL", "57": "CO",
+	"58": "VE", "60": "MY", "61": "AU", "62": "ID", "63": "PH", "64": "NZ",
+	"65": "SG", "66": "TH", "81": "JP", "82": "KR", "84": "VN", "86": "CN",
+	"90": "TR", "91": "IN", "92": "PK", "93": "AF", "94": "LK", "98": "IR",
+	"212": "MA", "213": "DZ", "216": "TN", "233": "GH", "234": "NG", "254": "KE",
+	"351": "PT", "353": "IE", "358": "FI", "380": "UA", "420": "CZ",
+	"880": "BD", "966": "SA", "971": "AE", "972": "IL", "974": "QA",
+	"992": "TJ", "993": "TM", "994": "AZ", "995": "GE", "996": "KG", "998": "UZ",
+}
+
+// CountryOfNumber returns the ISO country code for a wa_id, or "" when its
+// calling code is not known.
+func CountryOfNumber(waID string) string {
+	digits := normalizeWAID(waID)
+	for n := 3; n >= 1; n-- {
+		if len(digits) > n {
+			if country, ok := callingCodes[digits[:n]]; ok {
+				return country
+			}
+		}
+	}
+	return ""
+}
+
+// CostEstimate is the expected cost of messaging a set of recipients.
+// Recipients whose country is unknown are priced at the Other rates and
+// listed in Unknown. Invalid numbers are not priced and listed in Invalid.
+type CostEstimate struct {
+	Currency  string
+	Category  string
+	Total     float64
+	ByCountry map[string]float64
+	Count     map[string]int
+	Unknown   []string
+	Invalid   []string
+}
+
+// Countries returns the countries of the estimate, most expensive first.
+func (e CostEstimate) Countries() []string {
+	countries := make([]string, 0, len(e.ByCountry))
+	for c := range e.ByCountry {
+		countries = append(countries, c)
+	}
+	sort.Slice(countries, func(i, j int) bool {
+		if e.ByCountry[countries[i]] != e.ByCountry[countries[j]] {
+			return e.ByCountry[countries[i]] > e.ByCountry[countries[j]]
+		}
+		return countries[i] < countries[j]
+	})
+	return countries
+}
+
+// EstimateCost prices one conversation of category per unique recipient.
+// It is an upper bound: recipients with an open conversation of the same
+// category are not charged again.
+func EstimateCost(prices PriceList, category string, recipients []string) CostEstimate {
+	unique, dedup := DedupRecipients(recipients)
+	estimate := CostEstimate{
+		Currency:  prices.Currency,
+		Category:  strings.ToUpper(category),
+		ByCountry: make(map[string]float64),
+		Count:     make(map[string]int),
+	}
+	for _, d := range dedup.Invalid {
+		estimate.Invalid = append(estimate.Invalid, d.Original)
+	}
+	for _, waID := range unique {
+		country := CountryOfNumber(waID)
+		if country == "" {
+			estimate.Unknown = append(estimate.Unknown, waID)
+		}
+		price := prices.Price(country, category)
+		estimate.Total += price
+		estimate.ByCountry[country] += price
+		estimate.Count[country]++
+	}
+	return estimate
+}
+
+// Estimate prices the campaign for a template of category before it runs.
+func (c *Campaign) Estimate(prices PriceList, category string) CostEstimate {
+	recipients := make([]string, len(c.Recipients))
+	for i, r := range c.Recipients {
+		recipients[i] = r.WaID
+	}
+	return EstimateCost(prices, category, recipients)
+}
@@ -0,0 +1,74 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// UploadResumable uploads data through the Resumable Upload API of the app
+// appID and returns the file handle, as used for profile pictures and
+// template samples.
+func (w *WhatsappClient) UploadResumable(ctx context.Context, appID, mimeType string, data []byte) (string, error) {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	q := url.Values{
+		"file_length": {strconv.Itoa(len(data))},
+		"file_type":   {mimeType},
+	}
+	var session struct {
+		ID string `json:"id"`
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s/uploads?%s", w.graphURL(), appID, q.Encode()), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.accessToken))
+	if err := w.doJSON(req, &session); err != nil {
+		return "", fmt.Errorf("failed to start upload session: %w", err)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", w.graphURL(), session.ID), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", w.accessToken))
+	req.Header.Set("file_offset", "0")
+	var uploaded struct {
+		H string `json:"h"`
+	}
+	if err := w.doJSON(req, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+	return uploaded.H, nil
+}
+
+// SetProfilePicture uploads image (JPEG or PNG) and makes it the business
+// profile picture of the phone number.
+func (w *WhatsappClient) SetProfilePicture(ctx context.Context, appID, mimeType string, image []byte) error {
+	handle, err := w.UploadResumable(ctx, appID, mimeType, image)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"messaging_product":      "whatsapp",
+		"profile_picture_handle": handle,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.phoneNumberURL()+"/whatsapp_business_profile", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.accessToken))
+	req.Header.Set("Content-Type", "application/json")
+	return w.doJSON(req, nil)
+}
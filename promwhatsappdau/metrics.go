@@ -0,0 +1,104 @@
+// Package promwhatsappdau exports whatsappdau API call metrics to
+// Prometheus. It is a separate module so the core package stays free of the
+// Prometheus client dependency.
+package promwhatsappdau
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements whatsappdau.Metrics:
+//
+//	m := promwhatsappdau.NewMetrics(prometheus.DefaultRegisterer)
+//	client := whatsappdau.NewWhatsappClient(ctx, apiURL, token, nil, whatsappdau.WithMetrics(m))
+type Metrics struct {
+	reg prometheus.Registerer
+
+	MessagesSent    *prometheus.CounterVec
+	Errors          *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	UploadBytes     prometheus.Counter
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		reg: reg,
+		MessagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "whatsapp_messages_sent_total",
+			Help: "Messages accepted by the Cloud API, by message type.",
+		}, []string{"type"}),
+		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "whatsapp_api_errors_total",
+			Help: "Failed API calls, by operation and Graph error code.",
+		}, []string{"operation", "code"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "whatsapp_request_duration_seconds",
+			Help:    "Latency of API calls, by operation.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation"}),
+		UploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "whatsapp_media_upload_bytes_total",
+			Help: "Bytes sent in media uploads.",
+		}),
+	}
+	reg.MustRegister(m.MessagesSent, m.Errors, m.RequestDuration, m.UploadBytes)
+	return m
+}
+
+func (m *Metrics) ObserveCall(info whatsappdau.CallInfo) {
+	m.RequestDuration.WithLabelValues(info.Operation).Observe(info.Duration.Seconds())
+
+	failed := info.Err != nil || info.StatusCode >= 300
+	if failed {
+		code := strconv.Itoa(info.ErrorCode)
+		if info.Err != nil {
+			code = "transport"
+		}
+		m.Errors.WithLabelValues(info.Operation, code).Inc()
+		return
+	}
+	switch info.Operation {
+	case "messages.send":
+		m.MessagesSent.WithLabelValues(info.MessageType).Inc()
+	case "media.upload":
+		if info.RequestBytes > 0 {
+			m.UploadBytes.Add(float64(info.RequestBytes))
+		}
+	}
+}
+
+// BudgetSource is implemented by whatsappdau.RampLimiter and TierLimiter.
+type BudgetSource interface {
+	Stats(ctx context.Context) (whatsappdau.BudgetStats, error)
+}
+
+// WatchBudget exports the unique-recipient usage and limit of src as gauges
+// labelled with name.
+func (m *Metrics) WatchBudget(name string, src BudgetSource) {
+	stat := func(pick func(whatsappdau.BudgetStats) int) func() float64 {
+		return func() float64 {
+			stats, err := src.Stats(context.Background())
+			if err != nil {
+				return -1
+			}
+			return float64(pick(stats))
+		}
+	}
+	labels := prometheus.Labels{"limiter": name}
+	m.reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "whatsapp_budget_used",
+			Help:        "Unique recipients messaged in the current 24h window.",
+			ConstLabels: labels,
+		}, stat(func(s whatsappdau.BudgetStats) int { return s.Unique })),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "whatsapp_budget_limit",
+			Help:        "Unique recipient limit of the current 24h window, -1 when unlimited.",
+			ConstLabels: labels,
+		}, stat(func(s whatsappdau.BudgetStats) int { return s.Limit })),
+	)
+}
@@ -0,0 +1,50 @@
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Publisher forwards events to a message bus. key identifies the
+// conversation (the user's wa_id), for buses that keep per-key order. See
+// the kafkawhatsappdau and natswhatsappdau modules for implementations.
+type Publisher interface {
+	Publish(ctx context.Context, topic, key string, data []byte) error
+}
+
+// Default topics used by PublishTo.
+const (
+	TopicMessages = "whatsapp.messages"
+	TopicStatuses = "whatsapp.statuses"
+)
+
+// PublishTo registers handlers publishing every incoming message and status
+// to p as JSON, on TopicMessages and TopicStatuses unless other topics are
+// given. A failed publish fails the delivery, so Meta retries it.
+func (d *Dispatcher) PublishTo(p Publisher, topics ...string) {
+	messageTopic, statusTopic := TopicMessages, TopicStatuses
+	if len(topics) > 0 {
+		messageTopic = topics[0]
+	}
+	if len(topics) > 1 {
+		statusTopic = topics[1]
+	}
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		return publishJSON(ctx, p, messageTopic, msg.From, msg)
+	})
+	d.OnStatus(func(ctx context.Context, status StatusUpdate) error {
+		return publishJSON(ctx, p, statusTopic, status.RecipientID, status)
+	})
+}
+
+func publishJSON(ctx context.Context, p Publisher, topic, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := p.Publish(ctx, topic, key, data); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
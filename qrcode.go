@@ -0,0 +1,95 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type QRImageFormat string
+
+const (
+	QRImagePNG QRImageFormat = "PNG"
+	QRImageSVG QRImageFormat = "SVG"
+)
+
+// QRCode is a click-to-chat deep link that opens a chat with the business
+// number with PrefilledMessage typed in.
+type QRCode struct {
+	Code             string `json:"code"`
+	PrefilledMessage string `json:"prefilled_message"`
+	DeepLinkURL      string `json:"deep_link_url"`
+	QRImageURL       string `json:"qr_image_url,omitempty"`
+}
+
+func (w *WhatsappClient) qrCodesURL() string {
+	return w.phoneNumberURL() + "/message_qrdls"
+}
+
+// CreateQRCode creates a deep link and, when format is set, a QR image for it.
+func (w *WhatsappClient) CreateQRCode(ctx context.Context, prefilledMessage string, format QRImageFormat) (*QRCode, error) {
+	payload := map[string]string{"prefilled_message": prefilledMessage}
+	if format != "" {
+		payload["generate_qr_image"] = string(format)
+	}
+	var code QRCode
+	if err := w.callGraph(ctx, "POST", w.qrCodesURL(), payload, &code); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (w *WhatsappClient) ListQRCodes(ctx context.Context) ([]QRCode, error) {
+	var response struct {
+		Data []QRCode `json:"data"`
+	}
+	if err := w.callGraph(ctx, "GET", w.qrCodesURL(), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetQRCode fetches one code. format selects the image returned in
+// QRImageURL and may be empty.
+func (w *WhatsappClient) GetQRCode(ctx context.Context, code string, format QRImageFormat) (*QRCode, error) {
+	u := w.qrCodesURL() + "/" + url.PathEscape(code)
+	if format != "" {
+		u += "?" + url.Values{"fields": {fmt.Sprintf("code,prefilled_message,deep_link_url,qr_image_url.format(%s)", format)}}.Encode()
+	}
+	var response struct {
+		Data []QRCode `json:"data"`
+	}
+	if err := w.callGraph(ctx, "GET", u, nil, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("qr code %s not found", code)
+	}
+	return &response.Data[0], nil
+}
+
+// UpdateQRCode changes the prefilled message of an existing code.
+func (w *WhatsappClient) UpdateQRCode(ctx context.Context, code, prefilledMessage string) (*QRCode, error) {
+	payload := map[string]string{
+		"code":              code,
+		"prefilled_message": prefilledMessage,
+	}
+	var updated QRCode
+	if err := w.callGraph(ctx, "POST", w.qrCodesURL(), payload, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (w *WhatsappClient) DeleteQRCode(ctx context.Context, code string) error {
+	var response struct {
+		Success bool `json:"success"`
+	}
+	if err := w.callGraph(ctx, "DELETE", w.qrCodesURL()+"/"+url.PathEscape(code), nil, &response); err != nil {
+		return err
+	}
+	if !response.Success {
+		return fmt.Errorf("delete qr code %s: API did not report success", code)
+	}
+	return nil
+}
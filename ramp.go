@@ -0,0 +1,106 @@
+package whatsappdau
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// MessagingTiers is Meta's ladder of business-initiated unique recipients per
+// rolling 24 hours. A new, unverified number starts at the first step.
+var MessagingTiers = []int{250, 1000, 10000, 100000}
+
+// TierEscalationPeriod is how long a number is expected to stay on a tier
+// before Meta considers moving it to the next one.
+const TierEscalationPeriod = 7 * 24 * time.Hour
+
+// RampPolicy describes how quickly a freshly registered number may grow its
+// daily volume. The limit for day n is Day1Limit*Growth^n, capped by the tier
+// the number could have reached by then.
+type RampPolicy struct {
+	Start     time.Time
+	Day1Limit int
+	Growth    float64
+}
+
+func DefaultRampPolicy(start time.Time) RampPolicy {
+	return RampPolicy{
+		Start:     start,
+		Day1Limit: 50,
+		Growth:    1.5,
+	}
+}
+
+// LimitAt returns the number of unique recipients allowed in the 24 hours
+// ending at t. It returns -1 once the ramp has run through all tiers.
+func (p RampPolicy) LimitAt(t time.Time) int {
+	if t.Before(p.Start) {
+		return p.Day1Limit
+	}
+	elapsed := t.Sub(p.Start)
+	day := int(elapsed / (24 * time.Hour))
+
+	step := int(elapsed / TierEscalationPeriod)
+	if step >= len(MessagingTiers) {
+		return -1
+	}
+	ceiling := MessagingTiers[step]
+
+	growth := p.Growth
+	if growth < 1 {
+		growth = 1
+	}
+	limit := float64(p.Day1Limit) * math.Pow(growth, float64(day))
+	if limit > float64(ceiling) {
+		return ceiling
+	}
+	return int(limit)
+}
+
+// RampLimiter enforces a RampPolicy over a rolling 24 hour window of unique
+// recipients. Repeat messages to a recipient already counted in the window
+// are always allowed.
+type RampLimiter struct {
+	Policy RampPolicy
+	Window *RecipientWindow
+}
+
+func NewRampLimiter(policy RampPolicy) *RampLimiter {
+	return &RampLimiter{
+		Policy: policy,
+		Window: NewRecipientWindow(nil),
+	}
+}
+
+func (r *RampLimiter) Allow(ctx context.Context, recipient string) error {
+	_, err := r.reserve(ctx, recipient)
+	return err
+}
+
+func (r *RampLimiter) reserve(ctx context.Context, recipient string) (func(), error) {
+	limit := r.Policy.LimitAt(r.Window.now())
+	ok, retryAt, release, err := r.Window.reserve(ctx, recipient, limit)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &LimitError{
+			Limit:         limit,
+			RetryAt:       retryAt,
+			Reason:        "warm-up ramp",
+			NewRecipients: true,
+		}
+	}
+	return release, nil
+}
+
+// RemainingBudget returns how many new recipients can be messaged right now,
+// or -1 once the ramp no longer limits the number.
+func (r *RampLimiter) RemainingBudget(ctx context.Context) (int, error) {
+	stats, err := r.Stats(ctx)
+	return stats.Remaining, err
+}
+
+func (r *RampLimiter) Stats(ctx context.Context) (BudgetStats, error) {
+	return budgetStats(ctx, r.Window, r.Policy.LimitAt(r.Window.now()))
+}
@@ -0,0 +1,84 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRampPolicyLimitAt(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := DefaultRampPolicy(start)
+	tests := []struct {
+		at   time.Duration
+		want int
+	}{
+		{-time.Hour, 50},
+		{0, 50},
+		{24 * time.Hour, 75},
+		{3 * 24 * time.Hour, 168},
+		{6 * 24 * time.Hour, 250},
+		{7 * 24 * time.Hour, 854},
+		{13 * 24 * time.Hour, 1000},
+		{4 * TierEscalationPeriod, -1},
+	}
+	for _, tt := range tests {
+		if got := p.LimitAt(start.Add(tt.at)); got != tt.want {
+			t.Errorf("LimitAt(start+%v) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestRampLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	l := NewRampLimiter(RampPolicy{Start: start, Day1Limit: 2, Growth: 2})
+	l.Window.now = func() time.Time { return now }
+
+	tests := []struct {
+		at        time.Duration
+		recipient string
+		limited   bool
+	}{
+		{0, "15550000001", false},
+		{time.Hour, "15550000002", false},
+		{2 * time.Hour, "15550000003", true},
+		{2 * time.Hour, "15550000001", false},
+		{24 * time.Hour, "15550000003", false},
+	}
+	for i, tt := range tests {
+		now = start.Add(tt.at)
+		err := l.Allow(ctx, tt.recipient)
+		var limitErr *LimitError
+		if limited := errors.As(err, &limitErr); limited != tt.limited {
+			t.Fatalf("step %d: Allow(%q) = %v, want limited %v", i, tt.recipient, err, tt.limited)
+		}
+		if limitErr != nil && (!limitErr.NewRecipients || !limitErr.RetryAt.Equal(start.Add(24*time.Hour)) || limitErr.Limit != 2) {
+			t.Errorf("step %d: got %+v", i, limitErr)
+		}
+	}
+}
+
+func TestRampLimiterReleasesRejectedSends(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, `{"error":{"message":"failed","code":%d}}`, ErrCodeUndeliverable)
+	}))
+	defer srv.Close()
+
+	l := NewRampLimiter(RampPolicy{Start: time.Now(), Day1Limit: 1, Growth: 1})
+	client := NewWhatsappClient(context.Background(), srv.URL+"/v21.0/1/messages", "token", nil, WithLimiter(l))
+	for _, to := range []string{"15550000001", "15550000002"} {
+		if _, err := client.SendMessage(to, "hi"); !errors.Is(err, ErrUndeliverable) {
+			t.Fatalf("SendMessage(%s) = %v, want ErrUndeliverable", to, err)
+		}
+	}
+	if stats, _ := l.Stats(context.Background()); stats.Unique != 0 {
+		t.Errorf("ramp counted %d recipients of rejected sends", stats.Unique)
+	}
+}
@@ -0,0 +1,98 @@
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SendRaw posts payload as-is to the messages endpoint. It is the escape hatch
+// for message types the library does not model yet; messaging_product is
+// added when payload is a map that lacks it.
+func (w *WhatsappClient) SendRaw(ctx context.Context, payload interface{}) (*MessageResponse, error) {
+	if m, ok := payload.(map[string]interface{}); ok {
+		if _, ok := m["messaging_product"]; !ok {
+			m["messaging_product"] = "whatsapp"
+		}
+	}
+	return w.postMessage(ctx, rawRecipient(payload), payload)
+}
+
+// rawRecipient returns the "to" of a SendRaw payload, or "" if it has none.
+func rawRecipient(payload interface{}) string {
+	var raw []byte
+	switch p := payload.(type) {
+	case map[string]interface{}:
+		to, _ := p["to"].(string)
+		return to
+	case json.RawMessage:
+		raw = p
+	case []byte:
+		raw = p
+	default:
+		var err error
+		if raw, err = json.Marshal(payload); err != nil {
+			return ""
+		}
+	}
+	var recipient struct {
+		To string `json:"to"`
+	}
+	json.Unmarshal(raw, &recipient)
+	return recipient.To
+}
+
+// postMessage marshals payload, applies the limiters for recipient to it
+// and sends it to the messages endpoint. Budget the limiters reserved is
+// given back unless the API accepts the message.
+func (w *WhatsappClient) postMessage(ctx context.Context, recipient string, payload interface{}) (*MessageResponse, error) {
+	release := func() {}
+	if recipient != "" {
+		var err error
+		if release, err = w.allow(ctx, recipient); err != nil {
+			return nil, err
+		}
+	}
+	accepted := false
+	defer func() {
+		if !accepted {
+			release()
+		}
+	}()
+
+	body, err := encodeJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", err)
+	}
+	defer body.release()
+
+	req, err := body.newRequest(ctx, "POST", w.apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.accessToken))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	defer putBuffer(responseBody)
+	if resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, responseBody.Bytes())
+	}
+	// The limiters' budget stays spent from here on, even if the response
+	// can't be parsed.
+	accepted = true
+
+	var messageResponse MessageResponse
+	if err := json.Unmarshal(responseBody.Bytes(), &messageResponse); err != nil {
+		return nil, fmt.Errorf("error unmarshaling JSON: %w", err)
+	}
+	return &messageResponse, nil
+}
@@ -0,0 +1,70 @@
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRawRecipient(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{"map", map[string]interface{}{"to": "15551234567"}, "15551234567"},
+		{"raw JSON", json.RawMessage(`{"to":"15551234567","type":"text"}`), "15551234567"},
+		{"bytes", []byte(`{"to":"15551234567"}`), "15551234567"},
+		{"struct", LocationMessage{To: "15551234567"}, "15551234567"},
+		{"no recipient", map[string]interface{}{"type": "text"}, ""},
+		{"not an object", json.RawMessage(`[1]`), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rawRecipient(tt.payload); got != tt.want {
+				t.Errorf("rawRecipient() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// recipientLimiter records the recipients it is asked about.
+type recipientLimiter struct {
+	recipients []string
+}
+
+func (l *recipientLimiter) Allow(ctx context.Context, recipient string) error {
+	l.recipients = append(l.recipients, recipient)
+	return nil
+}
+
+func TestTypedSendsLimitTheirRecipient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer srv.Close()
+	limiter := &recipientLimiter{}
+	w := NewWhatsappClient(nil, srv.URL+"/v21.0/1/messages", "token", nil, WithLimiter(limiter)).(*WhatsappClient)
+
+	if _, err := w.SendMessage("15551234567", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SendTemplate(context.Background(), "15557654321", NewTemplateMessage("promo", "en", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SendWhatsAppLocation("15550000001", 1, 2, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"15551234567", "15557654321", "15550000001"}
+	if len(limiter.recipients) != len(want) {
+		t.Fatalf("limited %v, want %v", limiter.recipients, want)
+	}
+	for i := range want {
+		if limiter.recipients[i] != want[i] {
+			t.Errorf("limited %v, want %v", limiter.recipients, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package whatsappdau
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Recipient is one addressee of a broadcast or campaign with the template
+// variables that belong to it.
+type Recipient struct {
+	WaID      string
+	Variables map[string]string
+	Row       int
+}
+
+// normalizeWAID reduces a user-entered number to the digits WhatsApp uses as
+// a wa_id.
+func normalizeWAID(recipient string) string {
+	var b strings.Builder
+	for _, r := range recipient {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Duplicate is a recipient dropped by DedupRecipients because an earlier
+// entry normalized to the same wa_id.
+type Duplicate struct {
+	Original   string
+	WaID       string
+	Index      int
+	FirstIndex int
+}
+
+// InvalidRecipient is a recipient dropped by DedupRecipients because it
+// does not normalize to a valid wa_id.
+type InvalidRecipient struct {
+	Original string
+	Index    int
+}
+
+type DedupReport struct {
+	Input      int
+	Unique     int
+	Duplicates []Duplicate
+	Invalid    []InvalidRecipient
+}
+
+func (r DedupReport) String() string {
+	return fmt.Sprintf("%d recipients, %d unique, %d duplicates removed, %d invalid", r.Input, r.Unique, len(r.Duplicates), len(r.Invalid))
+}
+
+// DedupRecipients removes recipients whose normalized wa_id has already been
+// seen, keeping the first occurrence and the original order. The returned
+// slice holds normalized wa_ids. Recipients that are not valid wa_ids once
+// normalized are left out and listed in the report's Invalid, rather than
+// counted as duplicates of each other.
+func DedupRecipients(recipients []string) ([]string, DedupReport) {
+	report := DedupReport{Input: len(recipients)}
+	first := make(map[string]int, len(recipients))
+	unique := make([]string, 0, len(recipients))
+
+	for i, recipient := range recipients {
+		waID := normalizeWAID(recipient)
+		if !isWAID(waID) {
+			report.Invalid = append(report.Invalid, InvalidRecipient{Original: recipient, Index: i})
+			continue
+		}
+		if idx, ok := first[waID]; ok {
+			report.Duplicates = append(report.Duplicates, Duplicate{
+				Original:   recipient,
+				WaID:       waID,
+				Index:      i,
+				FirstIndex: idx,
+			})
+			continue
+		}
+		first[waID] = i
+		unique = append(unique, waID)
+	}
+
+	report.Unique = len(unique)
+	return unique, report
+}
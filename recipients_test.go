@@ -0,0 +1,51 @@
+package whatsappdau
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupRecipients(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         []string
+		unique     []string
+		duplicates []Duplicate
+		invalid    []InvalidRecipient
+	}{
+		{
+			name:   "distinct",
+			in:     []string{"15551234567", "442079460958"},
+			unique: []string{"15551234567", "442079460958"},
+		},
+		{
+			name:       "same number formatted differently",
+			in:         []string{"+1 (555) 123-4567", "442079460958", "15551234567"},
+			unique:     []string{"15551234567", "442079460958"},
+			duplicates: []Duplicate{{Original: "15551234567", WaID: "15551234567", Index: 2, FirstIndex: 0}},
+		},
+		{
+			name:    "invalid numbers are not duplicates of each other",
+			in:      []string{"", "n/a", "15551234567", "12"},
+			unique:  []string{"15551234567"},
+			invalid: []InvalidRecipient{{"", 0}, {"n/a", 1}, {"12", 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unique, report := DedupRecipients(tt.in)
+			if !reflect.DeepEqual(unique, tt.unique) {
+				t.Errorf("unique = %v, want %v", unique, tt.unique)
+			}
+			if !reflect.DeepEqual(report.Duplicates, tt.duplicates) {
+				t.Errorf("duplicates = %+v, want %+v", report.Duplicates, tt.duplicates)
+			}
+			if !reflect.DeepEqual(report.Invalid, tt.invalid) {
+				t.Errorf("invalid = %+v, want %+v", report.Invalid, tt.invalid)
+			}
+			if report.Input != len(tt.in) || report.Unique != len(tt.unique) {
+				t.Errorf("report = %+v", report)
+			}
+		})
+	}
+}
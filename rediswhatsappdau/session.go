@@ -0,0 +1,55 @@
+// Package rediswhatsappdau stores whatsappdau state in Redis. It is a
+// separate module so the core package stays free of the Redis client
+// dependency.
+package rediswhatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionStore implements whatsappdau.SessionStore:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	sessions := rediswhatsappdau.NewSessionStore(rdb, "wa:session:")
+type SessionStore struct {
+	Client redis.UniversalClient
+	// Prefix is prepended to every key.
+	Prefix string
+}
+
+var _ whatsappdau.SessionStore = (*SessionStore)(nil)
+
+func NewSessionStore(client redis.UniversalClient, prefix string) *SessionStore {
+	return &SessionStore{Client: client, Prefix: prefix}
+}
+
+func (s *SessionStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.Client.Get(ctx, s.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, whatsappdau.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis get %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (s *SessionStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.Client.Set(ctx, s.Prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("redis set %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *SessionStore) Delete(ctx context.Context, key string) error {
+	if err := s.Client.Del(ctx, s.Prefix+key).Err(); err != nil {
+		return fmt.Errorf("redis del %s: %w", key, err)
+	}
+	return nil
+}
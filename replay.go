@@ -0,0 +1,130 @@
+package whatsappdau
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReplayedDelivery = errors.New("whatsappdau: webhook delivery already processed")
+	ErrStaleDelivery    = errors.New("whatsappdau: webhook delivery outside the accepted time window")
+)
+
+// NonceStore remembers delivery nonces. Seen records nonce for ttl and
+// reports whether it was already recorded.
+type NonceStore interface {
+	Seen(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+type MemoryNonceStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{expires: make(map[string]time.Time), now: time.Now}
+}
+
+func (s *MemoryNonceStore) Seen(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for n, exp := range s.expires {
+		if now.After(exp) {
+			delete(s.expires, n)
+		}
+	}
+	if _, ok := s.expires[nonce]; ok {
+		return true, nil
+	}
+	s.expires[nonce] = now.Add(ttl)
+	return false, nil
+}
+
+// ReplayGuard rejects webhook deliveries that were already processed or whose
+// events are too old, so a captured request cannot be posted again to repeat
+// bot actions. The nonce is a hash of the signed body.
+//
+// Meta itself redelivers on failure for several days; keep MaxAge above the
+// outage you want to recover from.
+type ReplayGuard struct {
+	// MaxAge is the oldest event timestamp accepted. 0 disables the check.
+	MaxAge time.Duration
+	// MaxSkew is how far in the future an event may be, for clock drift.
+	MaxSkew time.Duration
+	// NonceTTL is how long deliveries are remembered. It defaults to MaxAge,
+	// or 24h when MaxAge is 0.
+	NonceTTL time.Duration
+	Store    NonceStore
+
+	now func() time.Time
+}
+
+func NewReplayGuard(maxAge time.Duration) *ReplayGuard {
+	return &ReplayGuard{
+		MaxAge:  maxAge,
+		MaxSkew: 5 * time.Minute,
+		Store:   NewMemoryNonceStore(),
+		now:     time.Now,
+	}
+}
+
+// Check returns ErrStaleDelivery or ErrReplayedDelivery when the delivery of
+// body, parsed as n, must not be dispatched.
+func (g *ReplayGuard) Check(ctx context.Context, body []byte, n *WebhookNotification) error {
+	now := time.Now()
+	if g.now != nil {
+		now = g.now()
+	}
+	for _, ts := range webhookTimestamps(n) {
+		t := parseWebhookTime(ts)
+		if g.MaxAge > 0 && now.Sub(t) > g.MaxAge {
+			return fmt.Errorf("%w: event at %s", ErrStaleDelivery, t.UTC().Format(time.RFC3339))
+		}
+		if g.MaxSkew > 0 && t.Sub(now) > g.MaxSkew {
+			return fmt.Errorf("%w: event at %s is in the future", ErrStaleDelivery, t.UTC().Format(time.RFC3339))
+		}
+	}
+
+	store := g.Store
+	if store == nil {
+		return nil
+	}
+	ttl := g.NonceTTL
+	if ttl == 0 {
+		ttl = g.MaxAge
+	}
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+	sum := sha256.Sum256(body)
+	seen, err := store.Seen(ctx, hex.EncodeToString(sum[:]), ttl)
+	if err != nil {
+		return fmt.Errorf("failed to check webhook nonce: %w", err)
+	}
+	if seen {
+		return ErrReplayedDelivery
+	}
+	return nil
+}
+
+func webhookTimestamps(n *WebhookNotification) []string {
+	var ts []string
+	for _, entry := range n.Entry {
+		for _, change := range entry.Changes {
+			for _, m := range change.Value.Messages {
+				ts = append(ts, m.Timestamp)
+			}
+			for _, s := range change.Value.Statuses {
+				ts = append(ts, s.Timestamp)
+			}
+		}
+	}
+	return ts
+}
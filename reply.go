@@ -0,0 +1,104 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// ContactDirectory looks up stored attributes for a contact. Every
+// CRMAdapter is a ContactDirectory.
+type ContactDirectory interface {
+	FetchAttributes(ctx context.Context, waID string) (map[string]string, error)
+}
+
+// Replier sends text replies rendered from text/template source against the
+// contact's attributes, e.g. "Hi {{.Name}}, order {{.LastOrderID}} shipped".
+//
+// Attributes come from Directory, then Fallbacks for keys it lacks, plus
+// WaID and Name (the WhatsApp profile name) when neither supplies them.
+// Missing attributes render as empty strings; templates can pick their own
+// default with {{default "there" .Name}}. A failing directory lookup is
+// logged and the reply is rendered from fallbacks alone.
+type Replier struct {
+	Client    MessageSender
+	Directory ContactDirectory
+	Fallbacks map[string]string
+
+	templates sync.Map
+}
+
+func NewReplier(client MessageSender, directory ContactDirectory) *Replier {
+	return &Replier{Client: client, Directory: directory}
+}
+
+var replyFuncs = template.FuncMap{
+	"default": func(def, value string) string {
+		if strings.TrimSpace(value) == "" {
+			return def
+		}
+		return value
+	},
+}
+
+// Render executes tmpl for the contact waID. name is the WhatsApp profile
+// name, used when neither the directory nor Fallbacks has one.
+func (r *Replier) Render(ctx context.Context, waID, name, tmpl string) (string, error) {
+	t, err := r.parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	attrs := map[string]string{"WaID": waID}
+	if name != "" {
+		attrs["Name"] = name
+	}
+	for k, v := range r.Fallbacks {
+		attrs[k] = v
+	}
+	if r.Directory != nil {
+		stored, err := r.Directory.FetchAttributes(ctx, waID)
+		if err != nil {
+			logger().Warn("reply: contact attributes unavailable", "wa_id", waID, "error", err)
+		}
+		for k, v := range stored {
+			if v != "" {
+				attrs[k] = v
+			}
+		}
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, attrs); err != nil {
+		return "", fmt.Errorf("reply: render template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Reply renders tmpl for the sender of msg and sends it back as text.
+func (r *Replier) Reply(ctx context.Context, msg *InboundMessage, tmpl string) (*MessageResponse, error) {
+	return r.Send(ctx, msg.From, msg.ContactName(), tmpl)
+}
+
+// Send renders tmpl for waID and sends it as text.
+func (r *Replier) Send(ctx context.Context, waID, name, tmpl string) (*MessageResponse, error) {
+	text, err := r.Render(ctx, waID, name, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return r.Client.SendMessage(waID, text)
+}
+
+func (r *Replier) parse(tmpl string) (*template.Template, error) {
+	if t, ok := r.templates.Load(tmpl); ok {
+		return t.(*template.Template), nil
+	}
+	t, err := template.New("reply").Funcs(replyFuncs).Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("reply: parse template: %w", err)
+	}
+	r.templates.Store(tmpl, t)
+	return t, nil
+}
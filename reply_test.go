@@ -0,0 +1,46 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type staticDirectory struct {
+	attrs map[string]string
+	err   error
+}
+
+func (d staticDirectory) FetchAttributes(ctx context.Context, waID string) (map[string]string, error) {
+	return d.attrs, d.err
+}
+
+func TestReplierRenderPrecedence(t *testing.T) {
+	const tmpl = `{{.Name}} {{.WaID}} {{.Plan}}`
+	tests := []struct {
+		name      string
+		directory ContactDirectory
+		fallbacks map[string]string
+		profile   string
+		want      string
+	}{
+		{"profile name only", nil, nil, "Ann", "Ann 15551234567 "},
+		{"fallback beats profile name", nil, map[string]string{"Name": "Customer", "Plan": "basic"}, "Ann", "Customer 15551234567 basic"},
+		{"directory beats fallback", staticDirectory{attrs: map[string]string{"Name": "Ann Smith", "Plan": "pro"}}, map[string]string{"Name": "Customer", "Plan": "basic"}, "Ann", "Ann Smith 15551234567 pro"},
+		{"empty directory value keeps fallback", staticDirectory{attrs: map[string]string{"Name": ""}}, map[string]string{"Name": "Customer"}, "Ann", "Customer 15551234567 "},
+		{"failing directory", staticDirectory{err: errors.New("down")}, map[string]string{"Plan": "basic"}, "Ann", "Ann 15551234567 basic"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReplier(nil, tt.directory)
+			r.Fallbacks = tt.fallbacks
+			got, err := r.Render(context.Background(), "15551234567", tt.profile, tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
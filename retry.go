@@ -0,0 +1,138 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides which failed API calls are tried again and how often.
+// A response is retried when its status is in Statuses or its Graph error
+// code is in ErrorCodes.
+type RetryPolicy struct {
+	// MaxAttempts counts the first try; 1 or less disables retries.
+	MaxAttempts int
+	// MaxElapsed stops retrying once this long has passed since the first
+	// try. Zero means no limit.
+	MaxElapsed time.Duration
+	Statuses   []int
+	ErrorCodes []int
+	// RetryNetworkErrors retries requests that failed without a response.
+	// A message may then be sent twice if the first request did reach Meta.
+	RetryNetworkErrors bool
+	// Backoff is the wait before the first retry, doubled for each one after
+	// up to MaxBackoff. A Retry-After header takes precedence.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries throttling and transient server errors three
+// times within a minute.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		MaxElapsed:  time.Minute,
+		Statuses:    []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		ErrorCodes:  []int{ErrCodeAppRateLimit, ErrCodeAccountRateLimit, ErrCodeThroughputExceeded, ErrCodePairRateLimit},
+		Backoff:     500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+	}
+}
+
+// WithRetryPolicy retries failed API calls as p describes. Streamed media
+// uploads cannot be replayed and are never retried.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(w *WhatsappClient) {
+		w.retry = &p
+	}
+}
+
+func (p *RetryPolicy) retryStatus(status int, body []byte) bool {
+	for _, s := range p.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	if len(p.ErrorCodes) == 0 {
+		return false
+	}
+	code := newAPIError(status, body).Code
+	for _, c := range p.ErrorCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	d := p.Backoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+type retryDoer struct {
+	next   HTTPDoer
+	policy *RetryPolicy
+}
+
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if d.policy.MaxAttempts <= 1 || !replayable {
+		return d.next.Do(req)
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		try := req.WithContext(context.WithValue(ctx, attemptKey{}, attempt))
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			try.Body = body
+		}
+
+		resp, err := d.next.Do(try)
+		retry := false
+		switch {
+		case err != nil:
+			retry = d.policy.RetryNetworkErrors && ctx.Err() == nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrSendLimitReached)
+		case resp.StatusCode >= 300:
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			retry = d.policy.retryStatus(resp.StatusCode, body)
+		}
+		if !retry || attempt >= d.policy.MaxAttempts {
+			return resp, err
+		}
+		wait := d.policy.backoff(attempt, resp)
+		if d.policy.MaxElapsed > 0 && time.Since(start)+wait > d.policy.MaxElapsed {
+			return resp, err
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package whatsappdau
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TextRouter sends incoming text messages to the first route that matches,
+// in the order routes were added, or to the fallback. Other message types
+// are ignored.
+type TextRouter struct {
+	// Prefix marks commands, "/" by default. Commands also match without
+	// it, so "/help" and "help" reach the same route.
+	Prefix string
+
+	routes   []textRoute
+	fallback MessageHandler
+}
+
+type textRoute struct {
+	match   func(text string) ([]string, bool)
+	handler MessageHandler
+}
+
+type routeMatchKey struct{}
+
+// RouteMatch returns what the route matched for the message being handled:
+// the arguments after a command, the keyword found, or the regexp submatches
+// with the full match first.
+func RouteMatch(ctx context.Context) []string {
+	m, _ := ctx.Value(routeMatchKey{}).([]string)
+	return m
+}
+
+func NewTextRouter() *TextRouter {
+	return &TextRouter{Prefix: "/"}
+}
+
+// Command routes messages whose first word is name, ignoring case.
+func (r *TextRouter) Command(name string, h MessageHandler) {
+	r.routes = append(r.routes, textRoute{handler: h, match: func(text string) ([]string, bool) {
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return nil, false
+		}
+		word := fields[0]
+		if r.Prefix != "" {
+			word = strings.TrimPrefix(word, r.Prefix)
+		}
+		if !strings.EqualFold(word, strings.TrimPrefix(name, r.Prefix)) {
+			return nil, false
+		}
+		return fields[1:], true
+	}})
+}
+
+// Keyword routes messages containing any of keywords as a whole word,
+// ignoring case.
+func (r *TextRouter) Keyword(h MessageHandler, keywords ...string) {
+	r.routes = append(r.routes, textRoute{handler: h, match: func(text string) ([]string, bool) {
+		words := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+		})
+		for _, kw := range keywords {
+			kw = strings.ToLower(kw)
+			for _, w := range words {
+				if w == kw {
+					return []string{kw}, true
+				}
+			}
+		}
+		return nil, false
+	}})
+}
+
+// Regexp routes messages matching re.
+func (r *TextRouter) Regexp(re *regexp.Regexp, h MessageHandler) {
+	r.routes = append(r.routes, textRoute{handler: h, match: func(text string) ([]string, bool) {
+		m := re.FindStringSubmatch(text)
+		return m, m != nil
+	}})
+}
+
+// Fallback handles text messages no route matches.
+func (r *TextRouter) Fallback(h MessageHandler) {
+	r.fallback = h
+}
+
+// Register makes the router handle every message d dispatches.
+func (r *TextRouter) Register(d *Dispatcher) {
+	d.OnMessage(r.Handle)
+}
+
+func (r *TextRouter) Handle(ctx context.Context, msg *InboundMessage) error {
+	if msg.Text == nil {
+		return nil
+	}
+	text := strings.TrimSpace(msg.Text.Body)
+	for _, route := range r.routes {
+		if m, ok := route.match(text); ok {
+			return route.handler(context.WithValue(ctx, routeMatchKey{}, m), msg)
+		}
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, msg)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package s3whatsappdau stores whatsappdau media in S3-compatible object
+// storage (AWS S3, MinIO, Cloudflare R2, ...). It is a separate module so
+// the core package stays free of the storage client dependency.
+package s3whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daulet140/whatsappdau"
+	"github.com/minio/minio-go/v7"
+)
+
+// Storage implements whatsappdau.Storage:
+//
+//	mc, _ := minio.New("s3.amazonaws.com", &minio.Options{Creds: credentials.NewStaticV4(id, secret, ""), Secure: true})
+//	archiver := whatsappdau.NewMediaArchiver(client, s3whatsappdau.NewStorage(mc, "attachments"))
+type Storage struct {
+	Client *minio.Client
+	Bucket string
+	// Prefix is prepended to every key.
+	Prefix string
+	// BaseURL, if set, replaces the s3:// location returned by Put, e.g.
+	// with a CDN in front of the bucket.
+	BaseURL string
+}
+
+var _ whatsappdau.Storage = (*Storage)(nil)
+
+func NewStorage(client *minio.Client, bucket string) *Storage {
+	return &Storage{Client: client, Bucket: bucket}
+}
+
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	key = s.Prefix + key
+	if _, err := s.Client.PutObject(ctx, s.Bucket, key, r, -1, minio.PutObjectOptions{}); err != nil {
+		return "", fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	if s.BaseURL != "" {
+		return strings.TrimRight(s.BaseURL, "/") + "/" + key, nil
+	}
+	return "s3://" + s.Bucket + "/" + key, nil
+}
@@ -0,0 +1,38 @@
+package whatsappdau
+
+// MaxCallbackDataLength is the limit on biz_opaque_callback_data.
+const MaxCallbackDataLength = 512
+
+// SendOption adjusts a single outgoing message.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	callbackData string
+}
+
+// WithCallbackData attaches biz_opaque_callback_data to the message. It comes
+// back in StatusUpdate.CallbackData on every status webhook for the message,
+// e.g. to correlate statuses with an order or campaign ID.
+func WithCallbackData(data string) SendOption {
+	return func(o *sendOptions) {
+		o.callbackData = data
+	}
+}
+
+// CallbackData returns the callback data opts attach, for test doubles that
+// record what a message would have carried.
+func CallbackData(opts ...SendOption) string {
+	return collectSendOptions(opts).callbackData
+}
+
+func collectSendOptions(opts []SendOption) sendOptions {
+	var o sendOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o sendOptions) validate() error {
+	return validateLength("biz_opaque_callback_data", o.callbackData, MaxCallbackDataLength)
+}
@@ -0,0 +1,112 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("whatsappdau: session not found")
+
+// SessionStore keeps conversation state by key, typically a wa_id. Get
+// returns ErrSessionNotFound for missing and expired keys; a ttl of 0 keeps
+// the value until it is deleted. See the rediswhatsappdau module for a Redis
+// implementation.
+type SessionStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type MemorySessionStore struct {
+	mu      sync.Mutex
+	entries map[string]memorySession
+	now     func() time.Time
+}
+
+type memorySession struct {
+	value   []byte
+	expires time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{entries: make(map[string]memorySession), now: time.Now}
+}
+
+func (s *MemorySessionStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if !e.expires.IsZero() && !s.now().Before(e.expires) {
+		delete(s.entries, key)
+		return nil, ErrSessionNotFound
+	}
+	return append([]byte(nil), e.value...), nil
+}
+
+func (s *MemorySessionStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := memorySession{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expires = s.now().Add(ttl)
+	}
+	s.entries[key] = e
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// Sessions stores typed values in a SessionStore, encoded with Serializer
+// under type name Type so they can be migrated as they change.
+type Sessions struct {
+	Store      SessionStore
+	Serializer *Serializer
+	Type       string
+	TTL        time.Duration
+}
+
+func NewSessions(store SessionStore, typeName string, ttl time.Duration) *Sessions {
+	return &Sessions{Store: store, Serializer: NewSerializer(nil), Type: typeName, TTL: ttl}
+}
+
+// Load decodes the value of key into v and reports whether there was one.
+func (s *Sessions) Load(ctx context.Context, key string, v interface{}) (bool, error) {
+	raw, err := s.Store.Get(ctx, key)
+	if errors.Is(err, ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load session: %w", err)
+	}
+	if err := s.Serializer.Decode(raw, s.Type, v); err != nil {
+		return false, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return true, nil
+}
+
+// Save stores v under key for TTL.
+func (s *Sessions) Save(ctx context.Context, key string, v interface{}) error {
+	raw, err := s.Serializer.Encode(s.Type, v)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := s.Store.Set(ctx, key, raw, s.TTL); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+func (s *Sessions) Delete(ctx context.Context, key string) error {
+	return s.Store.Delete(ctx, key)
+}
@@ -0,0 +1,72 @@
+package whatsappdau
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitText breaks text into chunks of at most max characters, cutting at the
+// last line break or space inside each chunk. Words longer than max are cut
+// hard. A max below 1 sets no limit, so text comes back as one chunk.
+func SplitText(text string, max int) []string {
+	if max < 1 {
+		if text == "" {
+			return nil
+		}
+		return []string{text}
+	}
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > max {
+		cut := lastBreak(runes[:max+1], '\n')
+		if cut <= 0 {
+			cut = lastBreak(runes[:max+1], ' ')
+		}
+		if cut <= 0 {
+			cut = max
+		}
+		chunk := strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
+
+func lastBreak(runes []rune, sep rune) int {
+	for i := len(runes) - 1; i > 0; i-- {
+		if runes[i] == sep || (sep == ' ' && unicode.IsSpace(runes[i])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sendSplitMessage sends an over-long body as consecutive messages. The
+// returned response lists the IDs of every part that went out, even when a
+// later part fails.
+func (w *WhatsappClient) sendSplitMessage(recipientWAID string, messageBody string, opts ...SendOption) (*MessageResponse, error) {
+	parts := SplitText(messageBody, MaxTextBodyLength)
+	combined := &MessageResponse{MessagingProduct: "whatsapp"}
+	for i, part := range parts {
+		resp, err := w.SendMessage(recipientWAID, part, opts...)
+		if err != nil {
+			return combined, fmt.Errorf("failed to send part %d of %d: %w", i+1, len(parts), err)
+		}
+		if combined.Contacts == nil {
+			combined.Contacts = resp.Contacts
+		}
+		combined.Messages = append(combined.Messages, resp.Messages...)
+	}
+	return combined, nil
+}
+
+func needsSplit(body string) bool {
+	return utf8.RuneCountInString(body) > MaxTextBodyLength
+}
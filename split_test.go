@@ -0,0 +1,45 @@
+package whatsappdau
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want []string
+	}{
+		{"empty", "", 10, nil},
+		{"short", "hello", 10, []string{"hello"}},
+		{"exact", "hello", 5, []string{"hello"}},
+		{"at space", "hello brave world", 11, []string{"hello brave", "world"}},
+		{"prefers line break", "one two\nthree four", 12, []string{"one two", "three four"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"runes", "привет мир", 6, []string{"привет", "мир"}},
+		{"zero max", "hello world", 0, []string{"hello world"}},
+		{"negative max", "hello world", -5, []string{"hello world"}},
+		{"zero max empty", "", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, tt.max)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitText(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitTextLimit(t *testing.T) {
+	text := strings.Repeat("word ", 2000) + strings.Repeat("x", 5000)
+	for _, chunk := range SplitText(text, MaxTextBodyLength) {
+		if n := utf8.RuneCountInString(chunk); n > MaxTextBodyLength || n == 0 {
+			t.Fatalf("chunk of %d runes", n)
+		}
+	}
+}
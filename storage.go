@@ -0,0 +1,131 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage keeps downloaded media and returns where it can be fetched from.
+// See the s3whatsappdau module for S3-compatible object storage.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) (string, error)
+}
+
+// DirStorage writes media under Dir. The returned location is BaseURL plus
+// the key, or the file path when BaseURL is empty. Keys that would resolve
+// outside Dir, such as absolute paths or ones climbing out with "..", are
+// rejected.
+type DirStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func (s DirStorage) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	// Keys are built from webhook fields, so one must not reach outside Dir.
+	rel := filepath.Clean(filepath.FromSlash(key))
+	if rel == "." || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("media key %q is outside the media directory", key)
+	}
+	key = filepath.ToSlash(rel)
+	path := filepath.Join(s.Dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create media directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create media file: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write media file: %w", err)
+	}
+	if s.BaseURL == "" {
+		return path, nil
+	}
+	return strings.TrimRight(s.BaseURL, "/") + "/" + key, nil
+}
+
+// StoredMedia is an attachment MediaArchiver has saved.
+type StoredMedia struct {
+	MediaID  string
+	MimeType string
+	Key      string
+	Location string
+}
+
+// MediaArchiver downloads the media of every incoming message and saves it
+// to Storage.
+type MediaArchiver struct {
+	Client  MediaManager
+	Storage Storage
+	// Key names the stored object. The default is "<wa_id>/<media id><ext>".
+	Key func(msg *InboundMessage, media *IncomingMedia) string
+	// OnStored, if set, is called after each attachment is saved.
+	OnStored func(ctx context.Context, msg *InboundMessage, stored StoredMedia) error
+}
+
+func NewMediaArchiver(client MediaManager, storage Storage) *MediaArchiver {
+	return &MediaArchiver{Client: client, Storage: storage}
+}
+
+// Register makes the archiver handle every message d dispatches.
+func (a *MediaArchiver) Register(d *Dispatcher) {
+	d.OnMessage(a.Handle)
+}
+
+func (a *MediaArchiver) Handle(ctx context.Context, msg *InboundMessage) error {
+	media := incomingMedia(&msg.IncomingMessage)
+	if media == nil {
+		return nil
+	}
+	info, err := a.Client.GetMediaURL(media.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get media URL for %s: %w", media.ID, err)
+	}
+	data, err := a.Client.DownloadMedia(info.Url)
+	if err != nil {
+		return fmt.Errorf("failed to download media %s: %w", media.ID, err)
+	}
+
+	key := defaultMediaKey(msg, media)
+	if a.Key != nil {
+		key = a.Key(msg, media)
+	}
+	location, err := a.Storage.Put(ctx, key, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to store media %s: %w", media.ID, err)
+	}
+	if a.OnStored == nil {
+		return nil
+	}
+	return a.OnStored(ctx, msg, StoredMedia{MediaID: media.ID, MimeType: media.MimeType, Key: key, Location: location})
+}
+
+func incomingMedia(msg *IncomingMessage) *IncomingMedia {
+	for _, m := range []*IncomingMedia{msg.Image, msg.Audio, msg.Video, msg.Document, msg.Sticker} {
+		if m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+func defaultMediaKey(msg *InboundMessage, media *IncomingMedia) string {
+	ext := filepath.Ext(media.Filename)
+	if ext == "" {
+		mimeType, _, _ := strings.Cut(media.MimeType, ";")
+		if exts, _ := mime.ExtensionsByType(strings.TrimSpace(mimeType)); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return msg.From + "/" + media.ID + ext
+}
@@ -0,0 +1,49 @@
+package whatsappdau
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirStoragePut(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "15551234567/media.jpg", want: "15551234567/media.jpg"},
+		{key: "a/./b/../media.jpg", want: "a/media.jpg"},
+		{key: "../media.jpg", wantErr: true},
+		{key: "a/../../media.jpg", wantErr: true},
+		{key: "/etc/media.jpg", wantErr: true},
+		{key: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			root := t.TempDir()
+			s := DirStorage{Dir: filepath.Join(root, "media"), BaseURL: "https://cdn.example.com/"}
+			location, err := s.Put(context.Background(), tt.key, strings.NewReader("data"))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Put(%q) = %q, want an error", tt.key, location)
+				}
+				if entries, _ := os.ReadDir(root); len(entries) != 0 {
+					t.Errorf("Put(%q) wrote %v", tt.key, entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if location != "https://cdn.example.com/"+tt.want {
+				t.Errorf("location = %q", location)
+			}
+			if _, err := os.Stat(filepath.Join(s.Dir, filepath.FromSlash(tt.want))); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package whatsappdau
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is one incoming message or status update. Exactly one of Message
+// and Status is set.
+type Event struct {
+	Message  *InboundMessage
+	Status   *StatusUpdate
+	Received time.Time
+}
+
+// Overflow decides what an EventStream does when its buffer is full.
+type Overflow int
+
+const (
+	// OverflowBlock makes the webhook handler wait for room. If the delivery
+	// request ends first the handler fails, so Meta redelivers later.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest discards the incoming event.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest
+)
+
+// EventStream delivers dispatched events on a channel:
+//
+//	events := dispatcher.Stream(100, whatsappdau.OverflowBlock)
+//	for ev := range events.C() {
+//		...
+//	}
+type EventStream struct {
+	ch       chan Event
+	overflow Overflow
+	dropped  atomic.Int64
+	done     chan struct{}
+	mu       sync.RWMutex
+	once     sync.Once
+}
+
+// Stream registers a handler feeding every message and status into a new
+// EventStream with room for buffer events.
+func (d *Dispatcher) Stream(buffer int, overflow Overflow) *EventStream {
+	s := &EventStream{ch: make(chan Event, buffer), overflow: overflow, done: make(chan struct{})}
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		return s.publish(ctx, Event{Message: msg, Received: time.Now()})
+	})
+	d.OnStatus(func(ctx context.Context, status StatusUpdate) error {
+		return s.publish(ctx, Event{Status: &status, Received: time.Now()})
+	})
+	return s
+}
+
+// C is closed by Close.
+func (s *EventStream) C() <-chan Event {
+	return s.ch
+}
+
+// Dropped counts events discarded because the buffer was full.
+func (s *EventStream) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops the stream and closes C. Events dispatched afterwards are
+// ignored.
+func (s *EventStream) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		close(s.ch)
+		s.mu.Unlock()
+	})
+}
+
+func (s *EventStream) publish(ctx context.Context, ev Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	switch s.overflow {
+	case OverflowDropNewest:
+		select {
+		case s.ch <- ev:
+		default:
+			s.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.ch <- ev:
+				return nil
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
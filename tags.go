@@ -0,0 +1,147 @@
+package whatsappdau
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// TagStore keeps the tags attached to each conversation, keyed by wa_id.
+type TagStore interface {
+	AddTag(ctx context.Context, waID, tag string) error
+	RemoveTag(ctx context.Context, waID, tag string) error
+	Tags(ctx context.Context, waID string) ([]string, error)
+}
+
+type MemoryTagStore struct {
+	mu   sync.Mutex
+	tags map[string]map[string]bool
+}
+
+func NewMemoryTagStore() *MemoryTagStore {
+	return &MemoryTagStore{tags: make(map[string]map[string]bool)}
+}
+
+func (s *MemoryTagStore) AddTag(ctx context.Context, waID, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tags[waID] == nil {
+		s.tags[waID] = make(map[string]bool)
+	}
+	s.tags[waID][tag] = true
+	return nil
+}
+
+func (s *MemoryTagStore) RemoveTag(ctx context.Context, waID, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tags[waID], tag)
+	return nil
+}
+
+func (s *MemoryTagStore) Tags(ctx context.Context, waID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tags := make([]string, 0, len(s.tags[waID]))
+	for tag := range s.tags[waID] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// Classifier inspects an inbound message and returns tags to attach to the
+// conversation, or none.
+type Classifier func(ctx context.Context, msg *InboundMessage) []string
+
+// TagRouter sends each inbound message to the handler group of the first
+// matching tag on its conversation, in the order groups were added, or to
+// the default handlers when no group matches. Classifiers run first, so a
+// message can tag its own conversation before routing.
+type TagRouter struct {
+	Store TagStore
+
+	classifiers []Classifier
+	order       []string
+	groups      map[string][]MessageHandler
+	fallback    []MessageHandler
+}
+
+func NewTagRouter(store TagStore) *TagRouter {
+	if store == nil {
+		store = NewMemoryTagStore()
+	}
+	return &TagRouter{Store: store, groups: make(map[string][]MessageHandler)}
+}
+
+// Register makes the router handle every message d dispatches.
+func (r *TagRouter) Register(d *Dispatcher) {
+	d.OnMessage(r.Handle)
+}
+
+// Group adds handlers to the group for tag.
+func (r *TagRouter) Group(tag string, handlers ...MessageHandler) {
+	if _, ok := r.groups[tag]; !ok {
+		r.order = append(r.order, tag)
+	}
+	r.groups[tag] = append(r.groups[tag], handlers...)
+}
+
+// Default adds handlers for conversations that match no group.
+func (r *TagRouter) Default(handlers ...MessageHandler) {
+	r.fallback = append(r.fallback, handlers...)
+}
+
+func (r *TagRouter) Classify(c Classifier) {
+	r.classifiers = append(r.classifiers, c)
+}
+
+func (r *TagRouter) Tag(ctx context.Context, waID, tag string) error {
+	return r.Store.AddTag(ctx, waID, tag)
+}
+
+func (r *TagRouter) Untag(ctx context.Context, waID, tag string) error {
+	return r.Store.RemoveTag(ctx, waID, tag)
+}
+
+func (r *TagRouter) Tags(ctx context.Context, waID string) ([]string, error) {
+	return r.Store.Tags(ctx, waID)
+}
+
+func (r *TagRouter) Handle(ctx context.Context, msg *InboundMessage) error {
+	for _, classify := range r.classifiers {
+		for _, tag := range classify(ctx, msg) {
+			if err := r.Store.AddTag(ctx, msg.From, tag); err != nil {
+				return err
+			}
+		}
+	}
+
+	tags, err := r.Store.Tags(ctx, msg.From)
+	if err != nil {
+		return err
+	}
+	handlers := r.fallback
+	for _, tag := range r.order {
+		if containsString(tags, tag) {
+			handlers = r.groups[tag]
+			break
+		}
+	}
+
+	for _, h := range handlers {
+		if err := h(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
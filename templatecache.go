@@ -0,0 +1,123 @@
+package whatsappdau
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TemplateLister fetches the templates of a business account;
+// *WhatsappClient is one.
+type TemplateLister interface {
+	ListTemplates(ctx context.Context, wabaID string) ([]Template, error)
+}
+
+// TemplateCache keeps the templates of a business account in memory, so
+// validation and localization need no API call per message. It is both a
+// TemplateCatalog and a TemplateLookup:
+//
+//	cache := whatsappdau.NewTemplateCache(nil, wabaID)
+//	client := whatsappdau.NewWhatsappClient(ctx, url, token, nil, whatsappdau.WithTemplateDefinitions(cache))
+//	cache.Client = client.(*whatsappdau.WhatsappClient)
+//	go cache.Run(ctx)
+//
+// Until the first refresh it knows no templates.
+type TemplateCache struct {
+	Client TemplateLister
+	WABAID string
+	// Interval between refreshes in Run. Default 15 minutes.
+	Interval time.Duration
+
+	mu        sync.RWMutex
+	templates map[string][]Template
+	updated   time.Time
+}
+
+func NewTemplateCache(client TemplateLister, wabaID string) *TemplateCache {
+	return &TemplateCache{Client: client, WABAID: wabaID, Interval: 15 * time.Minute}
+}
+
+// Refresh replaces the cached templates with the current ones. On error the
+// cache keeps what it had.
+func (c *TemplateCache) Refresh(ctx context.Context) error {
+	list, err := c.Client.ListTemplates(ctx, c.WABAID)
+	if err != nil {
+		return err
+	}
+	templates := make(map[string][]Template)
+	for _, t := range list {
+		templates[t.Name] = append(templates[t.Name], t)
+	}
+	c.mu.Lock()
+	c.templates = templates
+	c.updated = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the cache now and every Interval until ctx is done. Failed
+// refreshes are logged and retried at the next tick.
+func (c *TemplateCache) Run(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			logger().Error("template cache refresh failed", "waba_id", c.WABAID, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Updated returns when the cache was last refreshed, zero if never.
+func (c *TemplateCache) Updated() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.updated
+}
+
+// Lookup returns the template name in language, whatever its status.
+func (c *TemplateCache) Lookup(name, language string) (Template, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return TemplateList(c.templates[name]).Lookup(name, language)
+}
+
+// Languages returns the languages template name is approved in.
+func (c *TemplateCache) Languages(name string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return TemplateList(c.templates[name]).Languages(name)
+}
+
+// Get returns every language of template name.
+func (c *TemplateCache) Get(name string) []Template {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Template(nil), c.templates[name]...)
+}
+
+// Templates returns all cached templates ordered by name and language.
+func (c *TemplateCache) Templates() TemplateList {
+	c.mu.RLock()
+	var list TemplateList
+	for _, ts := range c.templates {
+		list = append(list, ts...)
+	}
+	c.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Language < list[j].Language
+	})
+	return list
+}
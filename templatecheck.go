@@ -0,0 +1,188 @@
+package whatsappdau
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateLookup finds the definition of a template in one language.
+type TemplateLookup interface {
+	Lookup(name, language string) (Template, bool)
+}
+
+// WithTemplateDefinitions makes SendTemplate check messages against their
+// definitions in l, see Template.Validate. Templates l does not know are sent
+// unchecked.
+func WithTemplateDefinitions(l TemplateLookup) Option {
+	return func(w *WhatsappClient) {
+		w.templates = l
+	}
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// placeholders returns the distinct variables of text in order of
+// appearance, e.g. "1", "2" or "first_name".
+func placeholders(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// buttonSubTypes maps definition button types to the sub_type of the button
+// component that fills them.
+var buttonSubTypes = map[string]string{
+	"URL":         "url",
+	"OTP":         "url",
+	"QUICK_REPLY": "quick_reply",
+	"COPY_CODE":   "copy_code",
+	"FLOW":        "flow",
+	"CATALOG":     "catalog",
+	"MPM":         "mpm",
+}
+
+// Validate checks msg against the definition t before it is sent: every
+// header, body and URL variable needs a parameter of a type it accepts, and
+// button components must point at a button of their sub_type. Mistakes the
+// API would reject with error 132000 are returned as a *ValidationError.
+func (t Template) Validate(msg TemplateMessage) error {
+	var header, body *TemplateDefinitionComponent
+	var buttons []TemplateDefinitionButton
+	for i := range t.Components {
+		switch c := &t.Components[i]; strings.ToUpper(c.Type) {
+		case "HEADER":
+			header = c
+		case "BODY":
+			body = c
+		case "BUTTONS":
+			buttons = c.Buttons
+		}
+	}
+	bodyText := ""
+	if body != nil {
+		bodyText = body.Text
+	}
+
+	filled := make(map[string]bool)
+	for _, c := range msg.Components {
+		switch strings.ToLower(c.Type) {
+		case "header":
+			filled["header"] = true
+			if err := checkHeaderParameters(header, c.Parameters); err != nil {
+				return err
+			}
+		case "body":
+			filled["body"] = true
+			if err := checkTemplateParameters("template body", bodyText, c.Parameters, "text", "currency", "date_time"); err != nil {
+				return err
+			}
+		case "button":
+			i, err := strconv.Atoi(c.Index)
+			if err != nil || i < 0 || i >= len(buttons) {
+				return &ValidationError{Field: "template button", Reason: fmt.Sprintf("index %q does not match any of the %d buttons of %s", c.Index, len(buttons), t.Name)}
+			}
+			filled["button "+c.Index] = true
+			if err := checkButtonParameters(i, buttons[i], c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if header != nil && !filled["header"] && headerNeedsParameters(header) {
+		return &ValidationError{Field: "template header", Reason: fmt.Sprintf("%s header parameter is required", strings.ToLower(header.Format))}
+	}
+	if n := len(placeholders(bodyText)); n > 0 && !filled["body"] {
+		return &ValidationError{Field: "template body", Reason: fmt.Sprintf("expects %d parameters, got none", n)}
+	}
+	for i, b := range buttons {
+		if strings.EqualFold(b.Type, "URL") && len(placeholders(b.URL)) > 0 && !filled["button "+strconv.Itoa(i)] {
+			return &ValidationError{Field: "template button", Reason: fmt.Sprintf("URL button %d needs its dynamic suffix", i)}
+		}
+	}
+	return nil
+}
+
+func headerNeedsParameters(header *TemplateDefinitionComponent) bool {
+	switch strings.ToUpper(header.Format) {
+	case "", "TEXT":
+		return len(placeholders(header.Text)) > 0
+	}
+	return true
+}
+
+func checkHeaderParameters(header *TemplateDefinitionComponent, params []TemplateParameter) error {
+	if header == nil {
+		return &ValidationError{Field: "template header", Reason: "the template has no header"}
+	}
+	switch format := strings.ToLower(header.Format); format {
+	case "", "text":
+		return checkTemplateParameters("template header", header.Text, params, "text")
+	default:
+		if len(params) != 1 {
+			return &ValidationError{Field: "template header", Reason: fmt.Sprintf("expects 1 %s parameter, got %d", format, len(params))}
+		}
+		if params[0].Type != format {
+			return &ValidationError{Field: "template header", Reason: fmt.Sprintf("expects a %s parameter, got %q", format, params[0].Type)}
+		}
+	}
+	return nil
+}
+
+// checkTemplateParameters matches params against the variables of text.
+func checkTemplateParameters(field, text string, params []TemplateParameter, types ...string) error {
+	names := placeholders(text)
+	if len(params) != len(names) {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("expects %d parameters, got %d", len(names), len(params))}
+	}
+	for i, p := range params {
+		if !containsString(types, p.Type) {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("parameter %d has type %q, expected %s", i+1, p.Type, strings.Join(types, " or "))}
+		}
+		if p.ParameterName != "" && !containsString(names, p.ParameterName) {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("has no variable {{%s}}", p.ParameterName)}
+		}
+	}
+	return nil
+}
+
+func checkButtonParameters(i int, b TemplateDefinitionButton, c TemplateComponent) error {
+	field := fmt.Sprintf("template button %d", i)
+	want, ok := buttonSubTypes[strings.ToUpper(b.Type)]
+	if !ok {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("%s buttons take no parameters", b.Type)}
+	}
+	if !strings.EqualFold(c.SubType, want) {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("is a %s button, expected sub_type %q, got %q", b.Type, want, c.SubType)}
+	}
+	switch strings.ToUpper(b.Type) {
+	case "URL":
+		return checkTemplateParameters(field, b.URL, c.Parameters, "text")
+	case "OTP":
+		return checkTemplateParameters(field, "{{1}}", c.Parameters, "text")
+	case "QUICK_REPLY":
+		return checkTemplateParameters(field, "{{1}}", c.Parameters, "payload")
+	case "COPY_CODE":
+		return checkTemplateParameters(field, "{{1}}", c.Parameters, "coupon_code")
+	}
+	return nil
+}
+
+// checkTemplate validates msg against its definition, if one is known.
+func (w *WhatsappClient) checkTemplate(msg TemplateMessage) error {
+	if w.templates == nil {
+		return nil
+	}
+	def, ok := w.templates.Lookup(msg.Name, msg.Language.Code)
+	if !ok {
+		return nil
+	}
+	return def.Validate(msg)
+}
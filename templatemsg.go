@@ -0,0 +1,318 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemplateSender sends approved message templates, the only messages allowed
+// outside the customer service window.
+type TemplateSender interface {
+	SendTemplate(ctx context.Context, recipientWAID string, t TemplateMessage, opts ...SendOption) (*MessageResponse, error)
+}
+
+// TemplateMessage selects a template and fills its variables.
+type TemplateMessage struct {
+	Name       string              `json:"name"`
+	Language   TemplateLanguage    `json:"language"`
+	Components []TemplateComponent `json:"components,omitempty"`
+}
+
+type TemplateLanguage struct {
+	Code string `json:"code"`
+}
+
+// TemplateComponent carries the parameters of one part of a template:
+// "header", "body" or "button".
+type TemplateComponent struct {
+	Type       string              `json:"type"`
+	SubType    string              `json:"sub_type,omitempty"`
+	Index      string              `json:"index,omitempty"`
+	Parameters []TemplateParameter `json:"parameters,omitempty"`
+}
+
+type TemplateParameter struct {
+	Type string `json:"type"`
+	// ParameterName is set for templates using named parameters.
+	ParameterName string `json:"parameter_name,omitempty"`
+	Text          string `json:"text,omitempty"`
+	CouponCode    string `json:"coupon_code,omitempty"`
+	// Payload is set on payload parameters of quick-reply buttons.
+	Payload string `json:"payload,omitempty"`
+	// LimitedTimeOffer is set on limited_time_offer parameters.
+	LimitedTimeOffer *LimitedTimeOffer `json:"limited_time_offer,omitempty"`
+	Currency         *TemplateCurrency `json:"currency,omitempty"`
+	DateTime         *TemplateDateTime `json:"date_time,omitempty"`
+	Image            *TemplateMedia    `json:"image,omitempty"`
+	Video            *TemplateMedia    `json:"video,omitempty"`
+	Document         *TemplateMedia    `json:"document,omitempty"`
+	Location         *TemplateLocation `json:"location,omitempty"`
+}
+
+// TemplateMedia is the media of a header, given by the ID of an uploaded
+// file or by a public https link. Filename is only shown for documents.
+type TemplateMedia struct {
+	ID       string `json:"id,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// TemplateCurrency is an amount WhatsApp formats for the recipient's locale.
+// Amount1000 is the amount multiplied by 1000, so 12.99 is 12990.
+type TemplateCurrency struct {
+	FallbackValue string `json:"fallback_value"`
+	Code          string `json:"code"`
+	Amount1000    int64  `json:"amount_1000"`
+}
+
+// TemplateDateTime is a date shown as FallbackValue.
+type TemplateDateTime struct {
+	FallbackValue string `json:"fallback_value"`
+}
+
+type LimitedTimeOffer struct {
+	ExpirationTimeMs int64 `json:"expiration_time_ms"`
+}
+
+// NewTemplateMessage returns a template message whose body is filled from
+// vars. Keys "1", "2", ... fill positional parameters in order; any other key
+// fills the named parameter of that name.
+func NewTemplateMessage(name, language string, vars map[string]string) TemplateMessage {
+	t := TemplateMessage{Name: name, Language: TemplateLanguage{Code: language}}
+	if params := BodyParameters(vars); len(params) > 0 {
+		t.Components = append(t.Components, TemplateComponent{Type: "body", Parameters: params})
+	}
+	return t
+}
+
+// BodyParameters turns template variables into body parameters, see
+// NewTemplateMessage.
+func BodyParameters(vars map[string]string) []TemplateParameter {
+	keys := make([]string, 0, len(vars))
+	positional := true
+	for k := range vars {
+		keys = append(keys, k)
+		if n, err := strconv.Atoi(k); err != nil || n < 1 {
+			positional = false
+		}
+	}
+	if positional {
+		sort.Slice(keys, func(i, j int) bool {
+			a, _ := strconv.Atoi(keys[i])
+			b, _ := strconv.Atoi(keys[j])
+			return a < b
+		})
+	} else {
+		sort.Strings(keys)
+	}
+
+	params := make([]TemplateParameter, len(keys))
+	for i, k := range keys {
+		params[i] = TemplateParameter{Type: "text", Text: vars[k]}
+		if !positional {
+			params[i].ParameterName = k
+		}
+	}
+	return params
+}
+
+// CurrencyParameter returns a currency parameter for amount1000/1000 in the
+// ISO 4217 currency code, with fallback as the text shown where the amount
+// cannot be localized, e.g. CurrencyParameter("$12.99", "USD", 12990).
+func CurrencyParameter(fallback, code string, amount1000 int64) TemplateParameter {
+	return TemplateParameter{Type: "currency", Currency: &TemplateCurrency{FallbackValue: fallback, Code: code, Amount1000: amount1000}}
+}
+
+// DateTimeParameter returns a date_time parameter showing fallback.
+func DateTimeParameter(fallback string) TemplateParameter {
+	return TemplateParameter{Type: "date_time", DateTime: &TemplateDateTime{FallbackValue: fallback}}
+}
+
+type TemplateLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// ImageHeader fills the image header of a template.
+func ImageHeader(m TemplateMedia) TemplateComponent {
+	return TemplateComponent{Type: "header", Parameters: []TemplateParameter{{Type: "image", Image: &m}}}
+}
+
+// VideoHeader fills the video header of a template.
+func VideoHeader(m TemplateMedia) TemplateComponent {
+	return TemplateComponent{Type: "header", Parameters: []TemplateParameter{{Type: "video", Video: &m}}}
+}
+
+// DocumentHeader fills the document header of a template.
+func DocumentHeader(m TemplateMedia) TemplateComponent {
+	return TemplateComponent{Type: "header", Parameters: []TemplateParameter{{Type: "document", Document: &m}}}
+}
+
+// LocationHeader fills the location header of a template; the map is shown
+// above the body with name and address below it.
+func LocationHeader(l TemplateLocation) TemplateComponent {
+	return TemplateComponent{Type: "header", Parameters: []TemplateParameter{{Type: "location", Location: &l}}}
+}
+
+// QuickReplyButton sets the payload of the quick-reply button at index. When
+// the recipient taps it, the webhook message's Reply has payload as its ID.
+func QuickReplyButton(index int, payload string) TemplateComponent {
+	return TemplateComponent{
+		Type:       "button",
+		SubType:    "quick_reply",
+		Index:      strconv.Itoa(index),
+		Parameters: []TemplateParameter{{Type: "payload", Payload: payload}},
+	}
+}
+
+// DynamicURLButton fills the variable at the end of the URL of the button at
+// index: for a button defined as "https://example.com/orders/{{1}}", suffix
+// "8812" links to https://example.com/orders/8812.
+func DynamicURLButton(index int, suffix string) TemplateComponent {
+	return TemplateComponent{
+		Type:       "button",
+		SubType:    "url",
+		Index:      strconv.Itoa(index),
+		Parameters: []TemplateParameter{{Type: "text", Text: suffix}},
+	}
+}
+
+// MaxCouponCodeLength is the longest code a coupon_code button accepts.
+const MaxCouponCodeLength = 15
+
+// CouponCodeButton fills the copy-code button at index with code, which the
+// recipient can copy to the clipboard with one tap.
+func CouponCodeButton(index int, code string) TemplateComponent {
+	return TemplateComponent{
+		Type:       "button",
+		SubType:    "COPY_CODE",
+		Index:      strconv.Itoa(index),
+		Parameters: []TemplateParameter{{Type: "coupon_code", CouponCode: code}},
+	}
+}
+
+// LimitedTimeOfferComponent sets when the offer of a limited-time offer
+// template expires, shown to the recipient as a countdown. It is only needed
+// for templates created with has_expiration; the coupon code, if any, goes in
+// a CouponCodeButton.
+func LimitedTimeOfferComponent(expires time.Time) TemplateComponent {
+	return TemplateComponent{
+		Type: "limited_time_offer",
+		Parameters: []TemplateParameter{{
+			Type:             "limited_time_offer",
+			LimitedTimeOffer: &LimitedTimeOffer{ExpirationTimeMs: expires.UnixMilli()},
+		}},
+	}
+}
+
+func validateTemplateComponents(components []TemplateComponent) error {
+	for _, c := range components {
+		if strings.EqualFold(c.SubType, "url") {
+			if err := validateURLSuffix(c); err != nil {
+				return err
+			}
+		}
+		for _, p := range c.Parameters {
+			if err := validateTemplateParameter(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateTemplateParameter(p TemplateParameter) error {
+	switch p.Type {
+	case "limited_time_offer":
+		if p.LimitedTimeOffer == nil || p.LimitedTimeOffer.ExpirationTimeMs <= 0 {
+			return &ValidationError{Field: "limited time offer", Reason: "expiration time is required"}
+		}
+	case "payload":
+		return validateRequired("quick reply payload", p.Payload)
+	case "coupon_code":
+		return validateLength("coupon code", p.CouponCode, MaxCouponCodeLength)
+	case "currency":
+		return validateCurrency(p.Currency)
+	case "date_time":
+		if p.DateTime == nil || p.DateTime.FallbackValue == "" {
+			return &ValidationError{Field: "date_time parameter", Reason: "fallback value is required"}
+		}
+	case "image":
+		return validateTemplateMedia(p.Type, p.Image)
+	case "video":
+		return validateTemplateMedia(p.Type, p.Video)
+	case "document":
+		return validateTemplateMedia(p.Type, p.Document)
+	case "location":
+		if p.Location == nil {
+			return &ValidationError{Field: "location header", Reason: "location is required"}
+		}
+		return validateCoordinates(p.Location.Latitude, p.Location.Longitude)
+	}
+	return nil
+}
+
+// validateURLSuffix checks the parameter of a dynamic URL button, which is
+// appended to the URL in the template rather than replacing it.
+func validateURLSuffix(c TemplateComponent) error {
+	field := fmt.Sprintf("url button %s", c.Index)
+	if len(c.Parameters) != 1 || c.Parameters[0].Type != "text" {
+		return &ValidationError{Field: field, Reason: "expects one text parameter with the URL suffix"}
+	}
+	suffix := c.Parameters[0].Text
+	if err := validateRequired(field+" suffix", suffix); err != nil {
+		return err
+	}
+	if strings.HasPrefix(suffix, "http://") || strings.HasPrefix(suffix, "https://") {
+		return &ValidationError{Field: field + " suffix", Reason: fmt.Sprintf("%q is a full URL; pass only the part that replaces the variable", suffix)}
+	}
+	return nil
+}
+
+func validateCurrency(c *TemplateCurrency) error {
+	if c == nil {
+		return &ValidationError{Field: "currency parameter", Reason: "currency is required"}
+	}
+	if err := validateRequired("currency fallback value", c.FallbackValue); err != nil {
+		return err
+	}
+	if len(c.Code) != 3 || strings.ToUpper(c.Code) != c.Code {
+		return &ValidationError{Field: "currency code", Reason: fmt.Sprintf("%q must be a three-letter ISO 4217 code like USD", c.Code)}
+	}
+	return nil
+}
+
+func validateTemplateMedia(kind string, m *TemplateMedia) error {
+	field := kind + " header"
+	switch {
+	case m == nil || (m.ID == "" && m.Link == ""):
+		return &ValidationError{Field: field, Reason: "a media ID or link is required"}
+	case m.ID != "" && m.Link != "":
+		return &ValidationError{Field: field, Reason: "set either the media ID or the link, not both"}
+	case m.Link != "" && !strings.HasPrefix(m.Link, "https://"):
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("link %q must be https", m.Link)}
+	}
+	return nil
+}
+
+func (w *WhatsappClient) SendTemplate(ctx context.Context, recipientWAID string, t TemplateMessage, opts ...SendOption) (*MessageResponse, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), validateRequired("template name", t.Name), ValidateLanguage(t.Language.Code), validateTemplateComponents(t.Components), w.checkTemplate(t), o.validate()); err != nil {
+		return nil, err
+	}
+	payload := struct {
+		MessagingProduct string          `json:"messaging_product"`
+		RecipientType    string          `json:"recipient_type"`
+		To               string          `json:"to"`
+		Type             string          `json:"type"`
+		CallbackData     string          `json:"biz_opaque_callback_data,omitempty"`
+		Template         TemplateMessage `json:"template"`
+	}{"whatsapp", "individual", recipientWAID, "template", o.callbackData, t}
+	return w.postMessage(ctx, recipientWAID, payload)
+}
@@ -0,0 +1,206 @@
+package whatsappdau
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TemplateApproved = "APPROVED"
+	TemplatePending  = "PENDING"
+	TemplateRejected = "REJECTED"
+	TemplatePaused   = "PAUSED"
+	TemplateDisabled = "DISABLED"
+)
+
+const (
+	QualityGreen   = "GREEN"
+	QualityYellow  = "YELLOW"
+	QualityRed     = "RED"
+	QualityUnknown = "UNKNOWN"
+)
+
+type TemplateQuality struct {
+	Score string
+	Date  time.Time
+}
+
+func (q *TemplateQuality) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Score string `json:"score"`
+		Date  int64  `json:"date"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	q.Score = raw.Score
+	if raw.Date > 0 {
+		q.Date = time.Unix(raw.Date, 0)
+	}
+	return nil
+}
+
+// Template is a message template of a business account.
+type Template struct {
+	ID           string                        `json:"id"`
+	Name         string                        `json:"name"`
+	Language     string                        `json:"language"`
+	Category     string                        `json:"category"`
+	Status       string                        `json:"status"`
+	QualityScore TemplateQuality               `json:"quality_score"`
+	Components   []TemplateDefinitionComponent `json:"components,omitempty"`
+}
+
+// TemplateDefinitionComponent is one part of a template as it was approved:
+// its HEADER, BODY, FOOTER or BUTTONS.
+type TemplateDefinitionComponent struct {
+	Type string `json:"type"`
+	// Format is the kind of header: TEXT, IMAGE, VIDEO, DOCUMENT or LOCATION.
+	Format  string                     `json:"format,omitempty"`
+	Text    string                     `json:"text,omitempty"`
+	Buttons []TemplateDefinitionButton `json:"buttons,omitempty"`
+}
+
+type TemplateDefinitionButton struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	URL         string `json:"url,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+}
+
+// Degraded reports whether the template is paused, disabled or no longer
+// rated green, i.e. likely to stop delivering.
+func (t Template) Degraded() bool {
+	switch t.Status {
+	case TemplatePaused, TemplateDisabled:
+		return true
+	}
+	return t.QualityScore.Score == QualityYellow || t.QualityScore.Score == QualityRed
+}
+
+// Templates pages through the templates of the business account wabaID with
+// their status, quality score and components.
+func (w *WhatsappClient) Templates(wabaID string) *Paginator[Template] {
+	q := url.Values{
+		"fields": {"id,name,language,category,status,quality_score,components"},
+		"limit":  {"100"},
+	}
+	return newPaginator[Template](w, w.graphURL()+"/"+wabaID+"/message_templates?"+q.Encode())
+}
+
+// ListTemplates returns every template of the business account wabaID with
+// its status and quality score.
+func (w *WhatsappClient) ListTemplates(ctx context.Context, wabaID string) ([]Template, error) {
+	return w.Templates(wabaID).All(ctx)
+}
+
+// DegradedTemplates returns the templates of wabaID for which Degraded is
+// true.
+func (w *WhatsappClient) DegradedTemplates(ctx context.Context, wabaID string) ([]Template, error) {
+	templates, err := w.ListTemplates(ctx, wabaID)
+	if err != nil {
+		return nil, err
+	}
+	var degraded []Template
+	for _, t := range templates {
+		if t.Degraded() {
+			degraded = append(degraded, t)
+		}
+	}
+	return degraded, nil
+}
+
+type TemplateAnalyticsQuery struct {
+	Start       time.Time
+	End         time.Time
+	TemplateIDs []string
+}
+
+type TemplateButtonClicks struct {
+	Type          string `json:"type"`
+	ButtonContent string `json:"button_content"`
+	Count         int    `json:"count"`
+}
+
+// TemplateDataPoint is the performance of one template over one day.
+type TemplateDataPoint struct {
+	TemplateID string
+	Start      time.Time
+	End        time.Time
+	Sent       int
+	Delivered  int
+	Read       int
+	Clicked    []TemplateButtonClicks
+}
+
+func (dp *TemplateDataPoint) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		TemplateID string                 `json:"template_id"`
+		Start      int64                  `json:"start"`
+		End        int64                  `json:"end"`
+		Sent       int                    `json:"sent"`
+		Delivered  int                    `json:"delivered"`
+		Read       int                    `json:"read"`
+		Clicked    []TemplateButtonClicks `json:"clicked"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*dp = TemplateDataPoint{
+		TemplateID: raw.TemplateID,
+		Start:      time.Unix(raw.Start, 0),
+		End:        time.Unix(raw.End, 0),
+		Sent:       raw.Sent,
+		Delivered:  raw.Delivered,
+		Read:       raw.Read,
+		Clicked:    raw.Clicked,
+	}
+	return nil
+}
+
+// ReadRate is Read/Delivered, or 0 when nothing was delivered.
+func (dp TemplateDataPoint) ReadRate() float64 {
+	if dp.Delivered == 0 {
+		return 0
+	}
+	return float64(dp.Read) / float64(dp.Delivered)
+}
+
+// TemplateAnalytics fetches daily sent, delivered, read and click counts of
+// the given templates. The API accepts at most 10 template IDs per query.
+func (w *WhatsappClient) TemplateAnalytics(ctx context.Context, wabaID string, q TemplateAnalyticsQuery) ([]TemplateDataPoint, error) {
+	if q.Start.IsZero() || q.End.IsZero() || !q.End.After(q.Start) {
+		return nil, errors.New("template analytics: need a start before end")
+	}
+	if len(q.TemplateIDs) == 0 || len(q.TemplateIDs) > 10 {
+		return nil, &ValidationError{Field: "template_ids", Reason: "between 1 and 10 required"}
+	}
+	params := url.Values{
+		"start":        {strconv.FormatInt(q.Start.Unix(), 10)},
+		"end":          {strconv.FormatInt(q.End.Unix(), 10)},
+		"granularity":  {"DAILY"},
+		"metric_types": {`["SENT","DELIVERED","READ","CLICKED"]`},
+		"template_ids": {"[" + strings.Join(q.TemplateIDs, ",") + "]"},
+	}
+
+	var response struct {
+		Data []struct {
+			DataPoints []TemplateDataPoint `json:"data_points"`
+		} `json:"data"`
+	}
+	endpoint := fmt.Sprintf("%s/%s/template_analytics?%s", w.graphURL(), wabaID, params.Encode())
+	if err := w.callGraph(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+	var points []TemplateDataPoint
+	for _, d := range response.Data {
+		points = append(points, d.DataPoints...)
+	}
+	return points, nil
+}
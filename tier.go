@@ -0,0 +1,193 @@
+package whatsappdau
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MessagingTier is the messaging_limit_tier reported for a phone number: the
+// number of unique users it may start conversations with per rolling 24h.
+type MessagingTier string
+
+const (
+	Tier50        MessagingTier = "TIER_50"
+	Tier250       MessagingTier = "TIER_250"
+	Tier1K        MessagingTier = "TIER_1K"
+	Tier2K        MessagingTier = "TIER_2K"
+	Tier10K       MessagingTier = "TIER_10K"
+	Tier100K      MessagingTier = "TIER_100K"
+	TierUnlimited MessagingTier = "TIER_UNLIMITED"
+)
+
+// Limit returns the unique-recipient budget for the tier, or -1 when the tier
+// is unlimited or not recognised.
+func (t MessagingTier) Limit() int {
+	switch t {
+	case Tier50:
+		return 50
+	case Tier250:
+		return 250
+	case Tier1K:
+		return 1000
+	case Tier2K:
+		return 2000
+	case Tier10K:
+		return 10000
+	case Tier100K:
+		return 100000
+	default:
+		return -1
+	}
+}
+
+type TierSource interface {
+	MessagingLimitTier(ctx context.Context) (MessagingTier, error)
+}
+
+// TierLimiter enforces the phone number's messaging limit tier over a rolling
+// 24 hour window of unique recipients. The tier is fetched from Source and
+// refreshed every RefreshInterval. Recipients over budget are refused with a
+// *LimitError whose RetryAt is the start of the next free slot, and reported
+// to OnDeferred. Replies to customers with an open service window bypass it
+// when the client has WithServiceWindows.
+//
+// When installed with WithLimiter and Source is nil, the client itself is
+// used as the source.
+type TierLimiter struct {
+	Source          TierSource
+	RefreshInterval time.Duration
+	OnDeferred      func(recipient string, err *LimitError)
+	Window          *RecipientWindow
+
+	mu        sync.Mutex
+	tier      MessagingTier
+	fetchedAt time.Time
+	deferred  int
+	// fetching is closed when the fetch in flight, if any, completes.
+	fetching chan struct{}
+}
+
+func NewTierLimiter(source TierSource) *TierLimiter {
+	return &TierLimiter{
+		Source:          source,
+		RefreshInterval: time.Hour,
+		Window:          NewRecipientWindow(nil),
+	}
+}
+
+func (t *TierLimiter) bindClient(w *WhatsappClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Source == nil {
+		t.Source = w
+	}
+}
+
+// Tier returns the tier currently enforced, refreshing it if it is stale.
+// The fetch runs without holding up other sends: while one is in flight they
+// keep using the last known tier, or wait for it if there is none yet.
+func (t *TierLimiter) Tier(ctx context.Context) (MessagingTier, error) {
+	for {
+		t.mu.Lock()
+		if t.tier != "" && time.Since(t.fetchedAt) < t.RefreshInterval {
+			tier := t.tier
+			t.mu.Unlock()
+			return tier, nil
+		}
+		if wait := t.fetching; wait != nil {
+			last := t.tier
+			t.mu.Unlock()
+			if last != "" {
+				return last, nil
+			}
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		t.fetching = done
+		source := t.Source
+		t.mu.Unlock()
+
+		tier, err := source.MessagingLimitTier(ctx)
+
+		t.mu.Lock()
+		t.fetching = nil
+		close(done)
+		if err == nil {
+			t.tier = tier
+			t.fetchedAt = time.Now()
+		}
+		last := t.tier
+		t.mu.Unlock()
+		if err != nil {
+			if last != "" {
+				// keep enforcing the last known tier rather than failing sends
+				return last, nil
+			}
+			return "", err
+		}
+		return tier, nil
+	}
+}
+
+// Deferred returns how many sends have been refused for exceeding the tier.
+func (t *TierLimiter) Deferred() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.deferred
+}
+
+func (t *TierLimiter) Allow(ctx context.Context, recipient string) error {
+	_, err := t.reserve(ctx, recipient)
+	return err
+}
+
+func (t *TierLimiter) reserve(ctx context.Context, recipient string) (func(), error) {
+	tier, err := t.Tier(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := tier.Limit()
+	ok, retryAt, release, err := t.Window.reserve(ctx, recipient, limit)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return release, nil
+	}
+
+	limitErr := &LimitError{
+		Limit:         limit,
+		RetryAt:       retryAt,
+		Reason:        "messaging tier " + string(tier),
+		NewRecipients: true,
+	}
+	t.mu.Lock()
+	t.deferred++
+	t.mu.Unlock()
+	if t.OnDeferred != nil {
+		t.OnDeferred(recipient, limitErr)
+	}
+	return nil, limitErr
+}
+
+// RemainingBudget returns how many new unique recipients can be messaged in
+// the current window, or -1 when the tier is unlimited.
+func (t *TierLimiter) RemainingBudget(ctx context.Context) (int, error) {
+	stats, err := t.Stats(ctx)
+	return stats.Remaining, err
+}
+
+func (t *TierLimiter) Stats(ctx context.Context) (BudgetStats, error) {
+	tier, err := t.Tier(ctx)
+	if err != nil {
+		return BudgetStats{}, err
+	}
+	return budgetStats(ctx, t.Window, tier.Limit())
+}
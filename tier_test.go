@@ -0,0 +1,168 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTierLimiterAllow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		tier     MessagingTier
+		sends    int
+		refused  int
+		deferred int
+	}{
+		{"under tier", Tier50, 50, 0, 0},
+		{"over tier", Tier50, 53, 3, 3},
+		{"unlimited", TierUnlimited, 300, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l := NewTierLimiter(staticTier(tt.tier))
+			l.Window.now = func() time.Time { return start }
+			var notified int
+			l.OnDeferred = func(string, *LimitError) { notified++ }
+
+			refused := 0
+			for i := 0; i < tt.sends; i++ {
+				err := l.Allow(ctx, fmt.Sprintf("1555000%04d", i))
+				if err == nil {
+					continue
+				}
+				refused++
+				var limitErr *LimitError
+				if !errors.As(err, &limitErr) {
+					t.Fatalf("Allow() = %v, want a *LimitError", err)
+				}
+				if limitErr.Limit != tt.tier.Limit() || !limitErr.NewRecipients || !limitErr.RetryAt.Equal(start.Add(24*time.Hour)) {
+					t.Errorf("got %+v", limitErr)
+				}
+			}
+			if refused != tt.refused || l.Deferred() != tt.deferred || notified != tt.deferred {
+				t.Errorf("refused %d, Deferred() %d, OnDeferred %d; want %d, %d, %d", refused, l.Deferred(), notified, tt.refused, tt.deferred, tt.deferred)
+			}
+			if tt.refused > 0 {
+				if err := l.Allow(ctx, "15550000000"); err != nil {
+					t.Errorf("recipient already in the window refused: %v", err)
+				}
+			}
+		})
+	}
+}
+
+type flakyTier struct {
+	tiers []MessagingTier
+	err   error
+}
+
+func (f *flakyTier) MessagingLimitTier(ctx context.Context) (MessagingTier, error) {
+	if len(f.tiers) == 0 {
+		return "", f.err
+	}
+	tier := f.tiers[0]
+	f.tiers = f.tiers[1:]
+	return tier, nil
+}
+
+func TestTierLimiterKeepsLastTier(t *testing.T) {
+	ctx := context.Background()
+	source := &flakyTier{tiers: []MessagingTier{Tier1K}, err: errors.New("graph unavailable")}
+	l := NewTierLimiter(source)
+	l.RefreshInterval = 0
+	for i := 0; i < 2; i++ {
+		if tier, err := l.Tier(ctx); err != nil || tier != Tier1K {
+			t.Fatalf("Tier() = %v, %v; want %v", tier, err, Tier1K)
+		}
+	}
+
+	if _, err := NewTierLimiter(source).Tier(ctx); err == nil {
+		t.Error("want the source error before any tier is known")
+	}
+}
+
+// blockingTier returns tier once release is closed.
+type blockingTier struct {
+	tier    MessagingTier
+	calls   int32
+	release chan struct{}
+}
+
+func (b *blockingTier) MessagingLimitTier(ctx context.Context) (MessagingTier, error) {
+	atomic.AddInt32(&b.calls, 1)
+	<-b.release
+	return b.tier, nil
+}
+
+func TestTierLimiterRefreshDoesNotBlockSends(t *testing.T) {
+	ctx := context.Background()
+	source := &blockingTier{tier: Tier1K, release: make(chan struct{})}
+	l := NewTierLimiter(source)
+
+	// Concurrent first calls share one fetch.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tier, err := l.Tier(ctx); err != nil || tier != Tier1K {
+				t.Errorf("Tier() = %v, %v", tier, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&source.calls); n != 1 {
+		t.Fatalf("fetched %d times, want 1", n)
+	}
+
+	// While a refresh is in flight the last known tier is served at once.
+	source.release = make(chan struct{})
+	l.mu.Lock()
+	l.fetchedAt = time.Time{}
+	l.mu.Unlock()
+	go l.Tier(ctx)
+	for atomic.LoadInt32(&source.calls) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := l.Allow(ctx, "15550000001"); err != nil {
+			t.Error(err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Allow waited for the tier refresh")
+	}
+	close(source.release)
+}
+
+func TestTierLimiterReleasesRejectedSends(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, `{"error":{"message":"failed","code":%d}}`, ErrCodeUndeliverable)
+	}))
+	defer srv.Close()
+
+	l := NewTierLimiter(staticTier(Tier50))
+	client := NewWhatsappClient(context.Background(), srv.URL+"/v21.0/1/messages", "token", nil, WithLimiter(l))
+	if _, err := client.SendMessage("15550000001", "hi"); !errors.Is(err, ErrUndeliverable) {
+		t.Fatalf("SendMessage() = %v, want ErrUndeliverable", err)
+	}
+	if stats, _ := l.Stats(context.Background()); stats.Unique != 0 {
+		t.Errorf("tier counted %d recipients of rejected sends", stats.Unique)
+	}
+}
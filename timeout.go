@@ -0,0 +1,87 @@
+package whatsappdau
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Timeouts bounds each kind of API call separately. A zero field leaves that
+// kind to Default, and a zero Default to the request context. The
+// *http.Client's own Timeout still applies on top, so leave it unset or at
+// least as long as the largest value here.
+type Timeouts struct {
+	// Send covers message sends and read receipts.
+	Send time.Duration
+	// Upload covers media uploads.
+	Upload time.Duration
+	// Download covers fetching media bytes from the URL GetMediaURL returns.
+	Download time.Duration
+	// Default covers every other Graph call.
+	Default time.Duration
+}
+
+// WithTimeouts sets per-operation timeouts, e.g. minutes for uploads and
+// seconds for text sends.
+func WithTimeouts(t Timeouts) Option {
+	return func(w *WhatsappClient) {
+		w.timeouts = t
+	}
+}
+
+func (t Timeouts) zero() bool {
+	return t == Timeouts{}
+}
+
+func (t Timeouts) forRequest(req *http.Request, graph string) time.Duration {
+	var d time.Duration
+	url := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	switch {
+	case !strings.HasPrefix(url, graph):
+		d = t.Download
+	case strings.HasSuffix(req.URL.Path, "/messages"):
+		d = t.Send
+	case strings.HasSuffix(req.URL.Path, "/media") && req.Method == http.MethodPost:
+		d = t.Upload
+	}
+	if d == 0 {
+		d = t.Default
+	}
+	return d
+}
+
+type timeoutDoer struct {
+	next     HTTPDoer
+	timeouts Timeouts
+	graph    func() string
+}
+
+func (d *timeoutDoer) Do(req *http.Request) (*http.Response, error) {
+	timeout := d.timeouts.forRequest(req, d.graph())
+	if timeout <= 0 {
+		return d.next.Do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := d.next.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	// The deadline also covers reading the body, so cancel only once the
+	// caller closes it.
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
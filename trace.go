@@ -0,0 +1,200 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CallInfo describes one HTTP request the client made to the API.
+type CallInfo struct {
+	// Operation names the call, e.g. "messages.send", "media.upload" or
+	// "graph.subscribed_apps".
+	Operation string
+	Method    string
+	// MessageType is the "type" of a sent message.
+	MessageType string
+	// RecipientHash identifies the recipient without exposing the number. It
+	// is empty unless the client has WithRecipientHashKey.
+	RecipientHash string
+	// Attempt is 1 for the first try and grows with each retry.
+	Attempt      int
+	RequestBytes int64
+	StatusCode   int
+	// ErrorCode is the Graph error code of a failed call.
+	ErrorCode int
+	Duration  time.Duration
+	Err       error
+}
+
+// Tracer is notified around every API call. Start may return a derived
+// context, which is used for the request.
+type Tracer interface {
+	Start(ctx context.Context, operation string) (context.Context, Span)
+}
+
+type Span interface {
+	End(info CallInfo)
+}
+
+// WithTracer reports every API call to t. See the otelwhatsappdau module for
+// an OpenTelemetry implementation.
+func WithTracer(t Tracer) Option {
+	return func(w *WhatsappClient) {
+		w.tracers = append(w.tracers, t)
+	}
+}
+
+type attemptKey struct{}
+
+func attemptFrom(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
+
+// WithRecipientHashKey sets CallInfo.RecipientHash to HashRecipient(key,
+// recipient). Keep key secret and stable: phone numbers are few enough to
+// enumerate, so only the key stops a hash being traced back to its number.
+func WithRecipientHashKey(key []byte) Option {
+	return func(w *WhatsappClient) {
+		w.recipientHashKey = key
+	}
+}
+
+// HashRecipient is the RecipientHash reported for waID: a truncated
+// HMAC-SHA256 keyed with key.
+func HashRecipient(key []byte, waID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(normalizeWAID(waID)))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// tracingDoer wraps the client's doer to report calls to its tracers.
+type tracingDoer struct {
+	next    HTTPDoer
+	tracers []Tracer
+	graph   func() string
+	hashKey []byte
+}
+
+func (d *tracingDoer) Do(req *http.Request) (*http.Response, error) {
+	info := d.describe(req)
+	ctx := req.Context()
+	spans := make([]Span, len(d.tracers))
+	for i, t := range d.tracers {
+		ctx, spans[i] = t.Start(ctx, info.Operation)
+	}
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	resp, err := d.next.Do(req)
+	info.Duration = time.Since(start)
+	info.Err = err
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+		if resp.StatusCode >= 300 {
+			// Only a bounded prefix is buffered; the caller still reads the
+			// whole body, prefix first.
+			prefix, _ := io.ReadAll(io.LimitReader(resp.Body, maxTracedErrorBody))
+			resp.Body = prefixedBody{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+			info.ErrorCode = newAPIError(resp.StatusCode, prefix).Code
+		}
+	}
+	for i := len(spans) - 1; i >= 0; i-- {
+		spans[i].End(info)
+	}
+	return resp, err
+}
+
+// maxTracedErrorBody bounds how much of an error response tracingDoer reads
+// to find the error code. Graph error envelopes are far smaller.
+const maxTracedErrorBody = 64 << 10
+
+// prefixedBody reads a response body whose start was already consumed from
+// a copy, and closes the original.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func (d *tracingDoer) describe(req *http.Request) CallInfo {
+	info := CallInfo{
+		Method:       req.Method,
+		Attempt:      attemptFrom(req.Context()),
+		RequestBytes: req.ContentLength,
+	}
+
+	graph := d.graph()
+	url := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	if !strings.HasPrefix(url, graph) {
+		info.Operation = "media.download"
+		return info
+	}
+	segs := strings.Split(strings.Trim(strings.TrimPrefix(url, graph), "/"), "/")
+	last := segs[len(segs)-1]
+	switch {
+	case last == "messages":
+		info.Operation = "messages.send"
+		var payload struct {
+			To     string `json:"to"`
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		}
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				json.NewDecoder(body).Decode(&payload)
+				body.Close()
+			}
+		}
+		if payload.Status == "read" {
+			info.Operation = "messages.read"
+		}
+		info.MessageType = payload.Type
+		if payload.To != "" && len(d.hashKey) > 0 {
+			info.RecipientHash = HashRecipient(d.hashKey, payload.To)
+		}
+	case last == "media":
+		info.Operation = "media.upload"
+	case len(segs) == 1:
+		info.Operation = "node." + strings.ToLower(req.Method)
+	default:
+		info.Operation = "graph." + last
+	}
+	return info
+}
+
+// Metrics receives a CallInfo after every API call.
+type Metrics interface {
+	ObserveCall(info CallInfo)
+}
+
+// WithMetrics reports every API call to m. See the promwhatsappdau module for
+// a Prometheus implementation. It is built on WithTracer, so m is called from
+// a span and pays for the same per-call bookkeeping: decoding every message
+// payload to describe the call, hashing the recipient when
+// WithRecipientHashKey is set, and reading the start of error bodies for their
+// code.
+func WithMetrics(m Metrics) Option {
+	return WithTracer(metricsTracer{m})
+}
+
+type metricsTracer struct {
+	metrics Metrics
+}
+
+func (t metricsTracer) Start(ctx context.Context, operation string) (context.Context, Span) {
+	return ctx, t
+}
+
+func (t metricsTracer) End(info CallInfo) {
+	t.metrics.ObserveCall(info)
+}
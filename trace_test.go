@@ -0,0 +1,98 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingTracer struct {
+	calls []CallInfo
+}
+
+func (r *recordingTracer) Start(ctx context.Context, operation string) (context.Context, Span) {
+	return ctx, r
+}
+
+func (r *recordingTracer) End(info CallInfo) {
+	r.calls = append(r.calls, info)
+}
+
+func TestHashRecipient(t *testing.T) {
+	a := HashRecipient([]byte("key-a"), "+1 555 123 4567")
+	if a != HashRecipient([]byte("key-a"), "15551234567") {
+		t.Error("hash depends on number formatting")
+	}
+	if a == HashRecipient([]byte("key-b"), "15551234567") {
+		t.Error("hash does not depend on the key")
+	}
+	if strings.Contains(a, "5551234567") || len(a) != 32 {
+		t.Errorf("unexpected hash %q", a)
+	}
+}
+
+func TestTracerRecipientHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"messages":[{"id":"wamid.1"}]}`)
+	}))
+	defer srv.Close()
+	key := []byte("secret")
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"no key", nil, ""},
+		{"key", []Option{WithRecipientHashKey(key)}, HashRecipient(key, "15551234567")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			opts := append([]Option{WithTracer(tracer)}, tt.opts...)
+			client := NewWhatsappClient(context.Background(), srv.URL+"/v24.0/1/messages", "token", nil, opts...)
+			if _, err := client.SendMessage("15551234567", "hi"); err != nil {
+				t.Fatal(err)
+			}
+			if len(tracer.calls) != 1 {
+				t.Fatalf("got %d calls", len(tracer.calls))
+			}
+			info := tracer.calls[0]
+			if info.Operation != "messages.send" || info.MessageType != "text" || info.RecipientHash != tt.want {
+				t.Errorf("got %+v, want hash %q", info, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracerBoundsErrorBody(t *testing.T) {
+	envelope := `{"error":{"message":"rate limited","code":130429}}`
+	padding := strings.Repeat(" ", 2*maxTracedErrorBody)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(rw, envelope+padding)
+	}))
+	defer srv.Close()
+
+	tracer := &recordingTracer{}
+	d := &tracingDoer{next: http.DefaultClient, tracers: []Tracer{tracer}, graph: func() string { return srv.URL }}
+	req, _ := http.NewRequest("GET", srv.URL+"/v21.0/1", nil)
+	resp, err := d.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if len(tracer.calls) != 1 || tracer.calls[0].ErrorCode != 130429 {
+		t.Errorf("calls = %+v, want error code 130429", tracer.calls)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != envelope+padding {
+		t.Errorf("caller read %d bytes, want the whole %d byte body", len(body), len(envelope+padding))
+	}
+}
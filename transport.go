@@ -0,0 +1,82 @@
+package whatsappdau
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportSettings tunes connection reuse to the Graph API. Zero fields
+// keep the values of the transport being tuned, http.DefaultTransport's
+// unless the *http.Client passed to NewWhatsappClient has its own.
+type TransportSettings struct {
+	MaxIdleConns int
+	// MaxIdleConnsPerHost matters most: net/http keeps only 2 idle
+	// connections per host by default, so a sender with more concurrent sends
+	// keeps opening new ones.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps connections to graph.facebook.com, 0 for no cap.
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive probe interval of new connections.
+	KeepAlive time.Duration
+	// DisableKeepAlives opens a connection per request.
+	DisableKeepAlives bool
+}
+
+// HighThroughputTransport suits senders with tens of concurrent sends.
+func HighThroughputTransport() TransportSettings {
+	return TransportSettings{
+		MaxIdleConns:        200,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+	}
+}
+
+// WithTransport applies s to a copy of the client's HTTP transport. It has
+// no effect when requests go through WithHTTPDoer or an *http.Client whose
+// Transport is not an *http.Transport.
+func WithTransport(s TransportSettings) Option {
+	return func(w *WhatsappClient) {
+		w.transport = &s
+	}
+}
+
+// tunedClient returns a copy of doer using a transport tuned by s, or doer
+// itself if it cannot be tuned.
+func tunedClient(doer HTTPDoer, s TransportSettings) HTTPDoer {
+	c, ok := doer.(*http.Client)
+	if !ok {
+		return doer
+	}
+	base := http.DefaultTransport
+	if c.Transport != nil {
+		base = c.Transport
+	}
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return doer
+	}
+	t = t.Clone()
+	if s.MaxIdleConns > 0 {
+		t.MaxIdleConns = s.MaxIdleConns
+	}
+	if s.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = s.MaxIdleConnsPerHost
+	}
+	if s.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = s.MaxConnsPerHost
+	}
+	if s.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = s.IdleConnTimeout
+	}
+	if s.KeepAlive != 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: s.KeepAlive}
+		t.DialContext = dialer.DialContext
+	}
+	t.DisableKeepAlives = s.DisableKeepAlives
+	tuned := *c
+	tuned.Transport = t
+	return &tuned
+}
@@ -0,0 +1,141 @@
+package whatsappdau
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BusinessUseCaseUsage is one entry of the X-Business-Use-Case-Usage header:
+// how much of a rate limit a business has used, in percent. Meta throttles
+// calls once any of the percentages reaches 100.
+type BusinessUseCaseUsage struct {
+	BusinessID   string `json:"business_id,omitempty"`
+	Type         string `json:"type"`
+	CallCount    int    `json:"call_count"`
+	TotalCPUTime int    `json:"total_cputime"`
+	TotalTime    int    `json:"total_time"`
+	// EstimatedTimeToRegainAccess is in minutes, 0 unless throttled.
+	EstimatedTimeToRegainAccess int       `json:"estimated_time_to_regain_access"`
+	ObservedAt                  time.Time `json:"observed_at"`
+}
+
+// Percent is the highest of the call count, CPU time and total time
+// percentages.
+func (u BusinessUseCaseUsage) Percent() int {
+	return max(u.CallCount, u.TotalCPUTime, u.TotalTime)
+}
+
+// RegainAccessAt is when a throttled business may call again, zero if it is
+// not throttled.
+func (u BusinessUseCaseUsage) RegainAccessAt() time.Time {
+	if u.EstimatedTimeToRegainAccess <= 0 {
+		return time.Time{}
+	}
+	return u.ObservedAt.Add(time.Duration(u.EstimatedTimeToRegainAccess) * time.Minute)
+}
+
+// ParseBusinessUseCaseUsage decodes an X-Business-Use-Case-Usage header,
+// ordered by business ID and type.
+func ParseBusinessUseCaseUsage(header string) ([]BusinessUseCaseUsage, error) {
+	var raw map[string][]BusinessUseCaseUsage
+	if err := json.Unmarshal([]byte(header), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse business use case usage: %w", err)
+	}
+	var usage []BusinessUseCaseUsage
+	for id, entries := range raw {
+		for _, u := range entries {
+			u.BusinessID = id
+			usage = append(usage, u)
+		}
+	}
+	sortUsage(usage)
+	return usage, nil
+}
+
+// WithUsageHandler calls fn with every business use case usage the API
+// reports, as responses arrive.
+func WithUsageHandler(fn func(BusinessUseCaseUsage)) Option {
+	return func(w *WhatsappClient) {
+		w.onUsage = fn
+	}
+}
+
+// Usage returns the latest business use case usage reported for each
+// business and type, ordered by business ID and type.
+func (w *WhatsappClient) Usage() []BusinessUseCaseUsage {
+	return w.usage.snapshot()
+}
+
+func sortUsage(usage []BusinessUseCaseUsage) {
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].BusinessID != usage[j].BusinessID {
+			return usage[i].BusinessID < usage[j].BusinessID
+		}
+		return usage[i].Type < usage[j].Type
+	})
+}
+
+type usageKey struct {
+	businessID string
+	typ        string
+}
+
+type usageTracker struct {
+	mu     sync.Mutex
+	latest map[usageKey]BusinessUseCaseUsage
+}
+
+func (t *usageTracker) record(u BusinessUseCaseUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.latest == nil {
+		t.latest = make(map[usageKey]BusinessUseCaseUsage)
+	}
+	t.latest[usageKey{u.BusinessID, u.Type}] = u
+}
+
+func (t *usageTracker) snapshot() []BusinessUseCaseUsage {
+	t.mu.Lock()
+	usage := make([]BusinessUseCaseUsage, 0, len(t.latest))
+	for _, u := range t.latest {
+		usage = append(usage, u)
+	}
+	t.mu.Unlock()
+	sortUsage(usage)
+	return usage
+}
+
+// usageDoer records the X-Business-Use-Case-Usage header of every response.
+type usageDoer struct {
+	next    HTTPDoer
+	tracker *usageTracker
+	fn      func(BusinessUseCaseUsage)
+}
+
+func (d *usageDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	header := resp.Header.Get("X-Business-Use-Case-Usage")
+	if header == "" {
+		return resp, nil
+	}
+	usage, parseErr := ParseBusinessUseCaseUsage(header)
+	if parseErr != nil {
+		return resp, nil
+	}
+	now := time.Now()
+	for _, u := range usage {
+		u.ObservedAt = now
+		d.tracker.record(u)
+		if d.fn != nil {
+			d.fn(u)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,140 @@
+package whatsappdau
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	MaxTextBodyLength        = 4096
+	MaxInteractiveBodyLength = 1024
+	MaxReplyButtons          = 3
+	MaxButtonTitleLength     = 20
+	MaxListRows              = 10
+	MaxListRowTitleLength    = 24
+	MaxListRowDescription    = 72
+)
+
+var ErrValidation = errors.New("whatsappdau: validation failed")
+
+// ValidationError describes a payload the Cloud API would reject. It is
+// returned before any request is made.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// validate returns the first non-nil error, unless validation is disabled.
+func (w *WhatsappClient) validate(errs ...error) error {
+	if w.skipValidation {
+		return nil
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isWAID(s string) bool {
+	if len(s) < 8 || len(s) > 15 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func validateRecipient(to string) error {
+	if !isWAID(to) {
+		return &ValidationError{Field: "recipient", Reason: fmt.Sprintf("%q must be 8-15 digits in E.164 format without '+'", to)}
+	}
+	return nil
+}
+
+func validateLength(field, value string, max int) error {
+	if n := utf8.RuneCountInString(value); n > max {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("%d characters exceeds the limit of %d", n, max)}
+	}
+	return nil
+}
+
+func validateRequired(field, value string) error {
+	if value == "" {
+		return &ValidationError{Field: field, Reason: "must not be empty"}
+	}
+	return nil
+}
+
+func validateTextBody(body string) error {
+	if err := validateRequired("body", body); err != nil {
+		return err
+	}
+	return validateLength("body", body, MaxTextBodyLength)
+}
+
+func validateListItems(buttonTitle string, items []ListItem) error {
+	if err := validateRequired("list button", buttonTitle); err != nil {
+		return err
+	}
+	if err := validateLength("list button", buttonTitle, MaxButtonTitleLength); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return &ValidationError{Field: "list rows", Reason: "at least one row is required"}
+	}
+	if len(items) > MaxListRows {
+		return &ValidationError{Field: "list rows", Reason: fmt.Sprintf("%d rows exceeds the limit of %d", len(items), MaxListRows)}
+	}
+	for i, item := range items {
+		if err := validateRequired(fmt.Sprintf("list row %d id", i), item.ID); err != nil {
+			return err
+		}
+		if err := validateLength(fmt.Sprintf("list row %d title", i), item.Title, MaxListRowTitleLength); err != nil {
+			return err
+		}
+		if err := validateLength(fmt.Sprintf("list row %d description", i), item.Description, MaxListRowDescription); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateButtons(buttons []ButtonItem) error {
+	replies := 0
+	for i, btn := range buttons {
+		if err := validateLength(fmt.Sprintf("button %d title", i), btn.Text, MaxButtonTitleLength); err != nil {
+			return err
+		}
+		if btn.Link == "" {
+			replies++
+		}
+	}
+	if replies > MaxReplyButtons {
+		return &ValidationError{Field: "buttons", Reason: fmt.Sprintf("%d reply buttons exceeds the limit of %d", replies, MaxReplyButtons)}
+	}
+	return nil
+}
+
+func validateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return &ValidationError{Field: "latitude", Reason: fmt.Sprintf("%v is out of range", latitude)}
+	}
+	if longitude < -180 || longitude > 180 {
+		return &ValidationError{Field: "longitude", Reason: fmt.Sprintf("%v is out of range", longitude)}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package whatsappdau
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// WithAPIVersion selects the Graph API version, e.g. "v20.0", overriding the
+// one in the API URL. The last one given wins. It needs an API URL of the form
+// ParseEndpoint accepts; with any other the constructor logs that it is
+// ignored.
+func WithAPIVersion(version string) Option {
+	return func(w *WhatsappClient) {
+		w.apiVersion = version
+	}
+}
+
+// APIVersion is the Graph API version the client requests.
+func (w *WhatsappClient) APIVersion() string {
+	if w.endpoint.PhoneNumberID == "" {
+		return ""
+	}
+	if w.endpoint.Version == "" {
+		return DefaultAPIVersion
+	}
+	return w.endpoint.Version
+}
+
+// DeprecationWarning reports that the requested API version is being phased
+// out. Served is the version Meta answered with, taken from the
+// facebook-api-version header; it differs from Requested once the requested
+// version has reached end of life and calls are upgraded automatically.
+// Message carries any explicit version warning header.
+type DeprecationWarning struct {
+	Requested string
+	Served    string
+	Message   string
+}
+
+// WithDeprecationHandler passes API version warnings to fn instead of the
+// standard logger. Each distinct warning is reported once per client.
+func WithDeprecationHandler(fn func(DeprecationWarning)) Option {
+	return func(w *WhatsappClient) {
+		w.onDeprecation = fn
+	}
+}
+
+// versionWarningHeaders are the response headers Meta uses for version
+// deprecation notices.
+var versionWarningHeaders = []string{"X-Ad-Api-Version-Warning", "X-Fb-Api-Version-Warning"}
+
+type deprecationDoer struct {
+	next      HTTPDoer
+	requested func() string
+	fn        func(DeprecationWarning)
+	log       func() *slog.Logger
+
+	mu   sync.Mutex
+	seen map[DeprecationWarning]bool
+}
+
+func (d *deprecationDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.next.Do(req)
+	if resp == nil {
+		return resp, err
+	}
+	warning := DeprecationWarning{Requested: d.requested()}
+	if served := resp.Header.Get("Facebook-Api-Version"); served != "" && served != warning.Requested {
+		warning.Served = served
+	}
+	for _, h := range versionWarningHeaders {
+		if msg := resp.Header.Get(h); msg != "" {
+			warning.Message = msg
+			break
+		}
+	}
+	if warning.Served != "" || warning.Message != "" {
+		d.report(warning)
+	}
+	return resp, err
+}
+
+func (d *deprecationDoer) report(warning DeprecationWarning) {
+	d.mu.Lock()
+	if d.seen[warning] {
+		d.mu.Unlock()
+		return
+	}
+	if d.seen == nil {
+		d.seen = make(map[DeprecationWarning]bool)
+	}
+	d.seen[warning] = true
+	d.mu.Unlock()
+
+	if d.fn != nil {
+		d.fn(warning)
+		return
+	}
+	if warning.Served != "" {
+		d.log().Warn("API version served differs from the requested one, upgrade the client", "requested", warning.Requested, "served", warning.Served)
+	}
+	if warning.Message != "" {
+		d.log().Warn("API version deprecation", "requested", warning.Requested, "message", warning.Message)
+	}
+}
@@ -0,0 +1,230 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxVideoSize is the largest video the Cloud API accepts.
+const MaxVideoSize = 16 << 20
+
+// ValidateVideo checks that r, from its current position, is an MP4 or 3GPP
+// file within MaxVideoSize with an H.264 video track and, if it has audio,
+// an AAC track. It returns a *ValidationError describing the first problem
+// and leaves r where it was.
+func ValidateVideo(r io.ReadSeeker) error {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to read video: %w", err)
+	}
+	defer r.Seek(start, io.SeekStart)
+
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to read video: %w", err)
+	}
+	size := end - start
+	if size > MaxVideoSize {
+		return &ValidationError{Field: "video", Reason: fmt.Sprintf("%d bytes exceeds the 16MB limit", size)}
+	}
+
+	var moov []byte
+	for pos := start; pos < end && moov == nil; {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read video: %w", err)
+		}
+		typ, hdr, n, err := readBoxHeader(r, end-pos)
+		switch {
+		case pos == start && (err != nil || typ != "ftyp"):
+			return &ValidationError{Field: "video", Reason: "must be MP4 or 3GPP, got a file without an ftyp box"}
+		case err != nil:
+			return err
+		case typ == "ftyp":
+			brand := make([]byte, 4)
+			if _, err := io.ReadFull(r, brand); err != nil {
+				return &ValidationError{Field: "video", Reason: "truncated ftyp box"}
+			}
+			if strings.HasPrefix(string(brand), "qt") {
+				return &ValidationError{Field: "video", Reason: "must be MP4 or 3GPP, got QuickTime"}
+			}
+		case typ == "moov":
+			moov = make([]byte, n-hdr)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return &ValidationError{Field: "video", Reason: "truncated moov box"}
+			}
+		}
+		pos += n
+	}
+	if moov == nil {
+		return &ValidationError{Field: "video", Reason: "no moov box; the file is incomplete"}
+	}
+	return checkVideoTracks(moov)
+}
+
+// readBoxHeader reads an ISO BMFF box header and returns the box type, the
+// header length and the total box length, at most remaining.
+func readBoxHeader(r io.Reader, remaining int64) (string, int64, int64, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:8]); err != nil {
+		return "", 0, 0, &ValidationError{Field: "video", Reason: "truncated box header"}
+	}
+	typ := string(hdr[4:8])
+	n, hdrLen := int64(binary.BigEndian.Uint32(hdr[:4])), int64(8)
+	switch n {
+	case 0:
+		n = remaining
+	case 1:
+		if _, err := io.ReadFull(r, hdr[8:16]); err != nil {
+			return "", 0, 0, &ValidationError{Field: "video", Reason: "truncated box header"}
+		}
+		n, hdrLen = int64(binary.BigEndian.Uint64(hdr[8:16])), 16
+	}
+	if n < hdrLen || n > remaining {
+		return "", 0, 0, &ValidationError{Field: "video", Reason: fmt.Sprintf("malformed %q box", typ)}
+	}
+	return typ, hdrLen, n, nil
+}
+
+// checkVideoTracks looks up the handler and sample entry of every trak in
+// moov.
+func checkVideoTracks(moov []byte) error {
+	hasVideo := false
+	for _, trak := range childBoxes(moov, "trak") {
+		mdia := firstBox(trak, "mdia")
+		hdlr := firstBox(mdia, "hdlr")
+		if len(hdlr) < 12 {
+			continue
+		}
+		codec := ""
+		if stsd := firstBox(firstBox(firstBox(mdia, "minf"), "stbl"), "stsd"); len(stsd) >= 16 {
+			codec = string(stsd[12:16])
+		}
+		switch string(hdlr[8:12]) {
+		case "vide":
+			if codec != "avc1" && codec != "avc3" {
+				return &ValidationError{Field: "video", Reason: fmt.Sprintf("video must be H.264, got %s", videoCodecName(codec))}
+			}
+			hasVideo = true
+		case "soun":
+			if codec != "mp4a" {
+				return &ValidationError{Field: "video", Reason: fmt.Sprintf("audio must be AAC, got %s", videoCodecName(codec))}
+			}
+		}
+	}
+	if !hasVideo {
+		return &ValidationError{Field: "video", Reason: "no video track"}
+	}
+	return nil
+}
+
+func videoCodecName(fourcc string) string {
+	switch fourcc {
+	case "hvc1", "hev1":
+		return "H.265/HEVC"
+	case "vp09":
+		return "VP9"
+	case "av01":
+		return "AV1"
+	case "mp4v":
+		return "MPEG-4 Part 2"
+	case "s263":
+		return "H.263"
+	case "samr", "sawb":
+		return "AMR"
+	case "Opus":
+		return "Opus"
+	case "ac-3", "ec-3":
+		return "Dolby Digital"
+	case "":
+		return "an unknown codec"
+	}
+	return fmt.Sprintf("%q", fourcc)
+}
+
+// childBoxes returns the payloads of the boxes of type typ directly inside
+// data.
+func childBoxes(data []byte, typ string) [][]byte {
+	var out [][]byte
+	for len(data) >= 8 {
+		n := int(binary.BigEndian.Uint32(data[:4]))
+		if n < 8 || n > len(data) {
+			break
+		}
+		if string(data[4:8]) == typ {
+			out = append(out, data[8:n])
+		}
+		data = data[n:]
+	}
+	return out
+}
+
+func firstBox(data []byte, typ string) []byte {
+	if boxes := childBoxes(data, typ); len(boxes) > 0 {
+		return boxes[0]
+	}
+	return nil
+}
+
+// checkVideoReader validates r and returns a reader of all of it. Readers
+// that cannot seek are buffered, up to one byte over MaxVideoSize.
+func (w *WhatsappClient) checkVideoReader(r io.Reader) (io.Reader, error) {
+	if w.skipValidation {
+		return r, nil
+	}
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(io.LimitReader(r, MaxVideoSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read video: %w", err)
+		}
+		rs = bytes.NewReader(data)
+	}
+	if err := ValidateVideo(rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// SendVideoToWhatsApp uploads the MP4 file at filePath and sends it,
+// returning the media ID.
+func (w *WhatsappClient) SendVideoToWhatsApp(recipientWAID string, filePath string, opts ...SendOption) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+	return w.SendVideoFrom(w.context(), recipientWAID, file, filepath.Base(filePath), opts...)
+}
+
+// SendVideoFrom uploads r as an MP4 video and sends it, returning the media
+// ID. The video is checked with ValidateVideo before anything is uploaded.
+func (w *WhatsappClient) SendVideoFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...SendOption) (string, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), o.validate()); err != nil {
+		return "", err
+	}
+	video, err := w.checkVideoReader(r)
+	if err != nil {
+		return "", err
+	}
+	mimeType := "video/mp4"
+	if ext := strings.ToLower(filepath.Ext(filename)); ext == ".3gp" || ext == ".3gpp" {
+		mimeType = "video/3gpp"
+	}
+	mediaId, err := w.UploadMedia(ctx, video, filename, mimeType)
+	if err != nil {
+		return "", err
+	}
+	message := VideoMessage{MessagingProduct: "whatsapp", To: recipientWAID, Type: "video", CallbackData: o.callbackData}
+	message.Video.ID = mediaId
+	if _, err := w.postMessage(ctx, recipientWAID, message); err != nil {
+		return "", err
+	}
+	return mediaId, nil
+}
@@ -0,0 +1,86 @@
+package whatsappdau
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type SubscribedApp struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+// SubscribeApp subscribes the app owning the access token to webhooks of the
+// business account wabaID. A non-empty callbackURL overrides the app's
+// default webhook URL for this account, answered with verifyToken.
+func (w *WhatsappClient) SubscribeApp(ctx context.Context, wabaID, callbackURL, verifyToken string) error {
+	endpoint := w.graphURL() + "/" + wabaID + "/subscribed_apps"
+	if callbackURL != "" {
+		endpoint += "?" + url.Values{
+			"override_callback_uri": {callbackURL},
+			"verify_token":          {verifyToken},
+		}.Encode()
+	}
+	return w.subscribedAppsAction(ctx, "POST", endpoint)
+}
+
+func (w *WhatsappClient) UnsubscribeApp(ctx context.Context, wabaID string) error {
+	return w.subscribedAppsAction(ctx, "DELETE", w.graphURL()+"/"+wabaID+"/subscribed_apps")
+}
+
+func (w *WhatsappClient) ListSubscribedApps(ctx context.Context, wabaID string) ([]SubscribedApp, error) {
+	var response struct {
+		Data []struct {
+			App SubscribedApp `json:"whatsapp_business_api_data"`
+		} `json:"data"`
+	}
+	if err := w.callGraph(ctx, "GET", w.graphURL()+"/"+wabaID+"/subscribed_apps", nil, &response); err != nil {
+		return nil, err
+	}
+	apps := make([]SubscribedApp, len(response.Data))
+	for i, d := range response.Data {
+		apps[i] = d.App
+	}
+	return apps, nil
+}
+
+func (w *WhatsappClient) subscribedAppsAction(ctx context.Context, method, endpoint string) error {
+	var response struct {
+		Success bool `json:"success"`
+	}
+	if err := w.callGraph(ctx, method, endpoint, nil, &response); err != nil {
+		return err
+	}
+	if !response.Success {
+		return fmt.Errorf("%s subscribed_apps: API did not report success", method)
+	}
+	return nil
+}
+
+// PhoneNumber is a phone number registered to a business account.
+type PhoneNumber struct {
+	ID                     string `json:"id"`
+	DisplayPhoneNumber     string `json:"display_phone_number"`
+	VerifiedName           string `json:"verified_name"`
+	QualityRating          string `json:"quality_rating"`
+	CodeVerificationStatus string `json:"code_verification_status,omitempty"`
+	PlatformType           string `json:"platform_type,omitempty"`
+	Throughput             struct {
+		Level string `json:"level"`
+	} `json:"throughput"`
+}
+
+// PhoneNumbers pages through the phone numbers of the business account wabaID.
+func (w *WhatsappClient) PhoneNumbers(wabaID string) *Paginator[PhoneNumber] {
+	q := url.Values{
+		"fields": {"id,display_phone_number,verified_name,quality_rating,code_verification_status,platform_type,throughput"},
+		"limit":  {"100"},
+	}
+	return newPaginator[PhoneNumber](w, w.graphURL()+"/"+wabaID+"/phone_numbers?"+q.Encode())
+}
+
+func (w *WhatsappClient) ListPhoneNumbers(ctx context.Context, wabaID string) ([]PhoneNumber, error) {
+	return w.PhoneNumbers(wabaID).All(ctx)
+}
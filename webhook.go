@@ -0,0 +1,335 @@
+package whatsappdau
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type WebhookNotification struct {
+	Object string         `json:"object"`
+	Entry  []WebhookEntry `json:"entry"`
+	// Raw is the delivery as received, set by ParseWebhook.
+	Raw json.RawMessage `json:"-"`
+}
+
+type WebhookEntry struct {
+	ID      string          `json:"id"`
+	Changes []WebhookChange `json:"changes"`
+}
+
+type WebhookChange struct {
+	Field string       `json:"field"`
+	Value WebhookValue `json:"value"`
+}
+
+type WebhookValue struct {
+	MessagingProduct string            `json:"messaging_product"`
+	Metadata         WebhookMetadata   `json:"metadata"`
+	Contacts         []WebhookContact  `json:"contacts,omitempty"`
+	Messages         []IncomingMessage `json:"messages,omitempty"`
+	Statuses         []StatusUpdate    `json:"statuses,omitempty"`
+	Errors           []WebhookError    `json:"errors,omitempty"`
+	// MessageEchoes holds messages sent from the WhatsApp Business app, on
+	// the smb_message_echoes field.
+	MessageEchoes []MessageEcho `json:"message_echoes,omitempty"`
+	// Raw is this value as received, for fields the structs don't cover.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON also keeps the raw JSON of the value and of each message and
+// status, so handlers can read fields Meta adds before the structs do:
+//
+//	var extra struct{ NewField string `json:"new_field"` }
+//	json.Unmarshal(msg.Raw, &extra)
+func (v *WebhookValue) UnmarshalJSON(data []byte) error {
+	type plain WebhookValue
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+	var raw struct {
+		Messages      []json.RawMessage `json:"messages"`
+		Statuses      []json.RawMessage `json:"statuses"`
+		MessageEchoes []json.RawMessage `json:"message_echoes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for i := range v.Messages {
+		v.Messages[i].Raw = raw.Messages[i]
+	}
+	for i := range v.Statuses {
+		v.Statuses[i].Raw = raw.Statuses[i]
+	}
+	for i := range v.MessageEchoes {
+		v.MessageEchoes[i].Raw = raw.MessageEchoes[i]
+	}
+	v.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+type WebhookMetadata struct {
+	DisplayPhoneNumber string `json:"display_phone_number"`
+	PhoneNumberID      string `json:"phone_number_id"`
+}
+
+type WebhookContact struct {
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	WaID string `json:"wa_id"`
+}
+
+type IncomingMessage struct {
+	From        string               `json:"from"`
+	ID          string               `json:"id"`
+	Timestamp   string               `json:"timestamp"`
+	Type        string               `json:"type"`
+	Context     *MessageContext      `json:"context,omitempty"`
+	Text        *IncomingText        `json:"text,omitempty"`
+	Image       *IncomingMedia       `json:"image,omitempty"`
+	Audio       *IncomingMedia       `json:"audio,omitempty"`
+	Video       *IncomingMedia       `json:"video,omitempty"`
+	Document    *IncomingMedia       `json:"document,omitempty"`
+	Sticker     *IncomingMedia       `json:"sticker,omitempty"`
+	Location    *IncomingLocation    `json:"location,omitempty"`
+	Interactive *IncomingInteractive `json:"interactive,omitempty"`
+	Button      *IncomingButton      `json:"button,omitempty"`
+	Reaction    *IncomingReaction    `json:"reaction,omitempty"`
+	Referral    *Referral            `json:"referral,omitempty"`
+	System      *IncomingSystem      `json:"system,omitempty"`
+	Identity    *IncomingIdentity    `json:"identity,omitempty"`
+	// Contacts are the contact cards of a "contacts" message.
+	Contacts []Contact      `json:"contacts,omitempty"`
+	Order    *IncomingOrder `json:"order,omitempty"`
+	// Errors is set on messages of type "unsupported".
+	Errors []WebhookError `json:"errors,omitempty"`
+	// Raw is the message as received, see WebhookValue.UnmarshalJSON.
+	Raw json.RawMessage `json:"-"`
+}
+
+// MessageEcho is a message a business agent sent from the WhatsApp Business
+// app. From is the business number and To the customer.
+type MessageEcho struct {
+	IncomingMessage
+	To string `json:"to"`
+}
+
+type MessageContext struct {
+	From string `json:"from"`
+	ID   string `json:"id"`
+}
+
+type IncomingText struct {
+	Body string `json:"body"`
+}
+
+type IncomingMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Sha256   string `json:"sha256"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Voice    bool   `json:"voice,omitempty"`
+	Animated bool   `json:"animated,omitempty"`
+}
+
+type IncomingLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+type IncomingInteractive struct {
+	Type        string            `json:"type"`
+	ButtonReply *InteractiveReply `json:"button_reply,omitempty"`
+	ListReply   *InteractiveReply `json:"list_reply,omitempty"`
+}
+
+type InteractiveReply struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+type IncomingButton struct {
+	Payload string `json:"payload"`
+	Text    string `json:"text"`
+}
+
+// Referral is set on the first message a user sends from a click-to-WhatsApp
+// ad or a post. SourceID is the ad or post ID and SourceType "ad" or "post".
+type Referral struct {
+	SourceURL    string `json:"source_url"`
+	SourceID     string `json:"source_id"`
+	SourceType   string `json:"source_type"`
+	Headline     string `json:"headline,omitempty"`
+	Body         string `json:"body,omitempty"`
+	MediaType    string `json:"media_type,omitempty"`
+	ImageURL     string `json:"image_url,omitempty"`
+	VideoURL     string `json:"video_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// CtwaClid is the click ID to report back with conversion events.
+	CtwaClid string `json:"ctwa_clid,omitempty"`
+}
+
+// Kinds of IncomingSystem.
+const (
+	SystemUserChangedNumber = "user_changed_number"
+	SystemIdentityChanged   = "customer_identity_changed"
+)
+
+// IncomingSystem is the body of a "system" message. For a changed number the
+// message is From the old wa_id and NewWaID is the new one.
+type IncomingSystem struct {
+	Body     string `json:"body"`
+	Type     string `json:"type"`
+	NewWaID  string `json:"new_wa_id,omitempty"`
+	Identity string `json:"identity,omitempty"`
+	Customer string `json:"customer,omitempty"`
+}
+
+// IncomingIdentity accompanies messages from a user whose security code
+// changed, when identity change checks are enabled.
+type IncomingIdentity struct {
+	Acknowledged     bool   `json:"acknowledged"`
+	CreatedTimestamp string `json:"created_timestamp"`
+	Hash             string `json:"hash"`
+}
+
+// IncomingOrder is the cart a user sends from a catalog or product message.
+type IncomingOrder struct {
+	CatalogID    string             `json:"catalog_id"`
+	Text         string             `json:"text,omitempty"`
+	ProductItems []OrderProductItem `json:"product_items"`
+}
+
+// OrderProductItem is a line of an IncomingOrder. Quantity and ItemPrice
+// arrive as JSON numbers or as numeric strings; json.Number takes both.
+type OrderProductItem struct {
+	ProductRetailerID string      `json:"product_retailer_id"`
+	Quantity          json.Number `json:"quantity"`
+	ItemPrice         json.Number `json:"item_price"`
+	Currency          string      `json:"currency"`
+}
+
+type IncomingReaction struct {
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+// knownMessageTypes are the message types IncomingMessage has fields for.
+// "unsupported" carries Errors.
+var knownMessageTypes = map[string]bool{
+	"text": true, "image": true, "audio": true, "video": true, "document": true,
+	"sticker": true, "location": true, "interactive": true, "button": true,
+	"reaction": true, "system": true, "unsupported": true, "contacts": true,
+	"order": true,
+}
+
+// KnownType reports whether the library models m's type. For other types
+// the content is only in Raw.
+func (m *IncomingMessage) KnownType() bool {
+	return knownMessageTypes[m.Type]
+}
+
+// Kinds of Reply.
+const (
+	ReplyKindButton     = "button_reply"
+	ReplyKindList       = "list_reply"
+	ReplyKindQuickReply = "button"
+)
+
+// Reply is the choice a user made on an interactive message or a template
+// quick-reply button. For quick replies ID is the button payload.
+type Reply struct {
+	Kind        string
+	ID          string
+	Title       string
+	Description string
+}
+
+// Reply returns the button, list row or quick-reply button the message
+// selects, if it is such a reply.
+func (m *IncomingMessage) Reply() (Reply, bool) {
+	if r, ok := m.ButtonReply(); ok {
+		return Reply{Kind: ReplyKindButton, ID: r.ID, Title: r.Title}, true
+	}
+	if r, ok := m.ListReply(); ok {
+		return Reply{Kind: ReplyKindList, ID: r.ID, Title: r.Title, Description: r.Description}, true
+	}
+	if m.Button != nil {
+		return Reply{Kind: ReplyKindQuickReply, ID: m.Button.Payload, Title: m.Button.Text}, true
+	}
+	return Reply{}, false
+}
+
+// ButtonReply returns the reply button the user tapped.
+func (m *IncomingMessage) ButtonReply() (InteractiveReply, bool) {
+	if m.Interactive == nil || m.Interactive.ButtonReply == nil {
+		return InteractiveReply{}, false
+	}
+	return *m.Interactive.ButtonReply, true
+}
+
+// ListReply returns the list row the user selected.
+func (m *IncomingMessage) ListReply() (InteractiveReply, bool) {
+	if m.Interactive == nil || m.Interactive.ListReply == nil {
+		return InteractiveReply{}, false
+	}
+	return *m.Interactive.ListReply, true
+}
+
+type StatusUpdate struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Timestamp   string `json:"timestamp"`
+	RecipientID string `json:"recipient_id"`
+	// CallbackData echoes the biz_opaque_callback_data the message was sent
+	// with, see WithCallbackData.
+	CallbackData string        `json:"biz_opaque_callback_data,omitempty"`
+	Conversation *Conversation `json:"conversation,omitempty"`
+	Pricing      *Pricing      `json:"pricing,omitempty"`
+	// Errors explains a "failed" status.
+	Errors []WebhookError `json:"errors,omitempty"`
+	// Raw is the status as received, see WebhookValue.UnmarshalJSON.
+	Raw json.RawMessage `json:"-"`
+}
+
+type Conversation struct {
+	ID                  string `json:"id"`
+	ExpirationTimestamp string `json:"expiration_timestamp,omitempty"`
+	Origin              struct {
+		Type string `json:"type"`
+	} `json:"origin"`
+}
+
+type Pricing struct {
+	Billable     bool   `json:"billable"`
+	PricingModel string `json:"pricing_model"`
+	Category     string `json:"category"`
+}
+
+func ParseWebhook(body []byte) (*WebhookNotification, error) {
+	var notification WebhookNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("error unmarshaling webhook: %w", err)
+	}
+	if notification.Object != "whatsapp_business_account" {
+		return nil, fmt.Errorf("unexpected webhook object %q", notification.Object)
+	}
+	notification.Raw = append(json.RawMessage(nil), body...)
+	return &notification, nil
+}
+
+// parseWebhookTime converts a webhook's unix-seconds timestamp, falling back
+// to the current time when it is missing or malformed.
+func parseWebhookTime(ts string) time.Time {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.Unix(sec, 0)
+}
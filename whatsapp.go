@@ -6,44 +6,160 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 )
 
-type Whatsapp interface {
-	SendMessage(to string, message string) (*MessageResponse, error)
-	SendAudioToWhatsApp(recipientWAID string, filePath string) (string, error)
-	SendImageToWhatsApp(recipientWAID string, filePath string) (string, error)
-	SendInteractiveList(recipientPhoneNumber string, bodyText string, buttonTitle string, items []ListItem) (*MessageResponse, error)
-	SendWhatsAppLocation(recipientPhone string, latitude, longitude float64, name, address string) (*MessageResponse, error)
-	SendInteractiveButtons(recipientPhoneNumber string, menuType, bodyText string, buttons []ButtonItem) (*MessageResponse, error)
-	MessageRead(messageID string) error
+type MessageSender interface {
+	SendMessage(to string, message string, opts ...SendOption) (*MessageResponse, error)
+	SendWhatsAppLocation(recipientPhone string, latitude, longitude float64, name, address string, opts ...SendOption) (*MessageResponse, error)
+	SendContacts(ctx context.Context, recipientWAID string, contacts []Contact, opts ...SendOption) (*MessageResponse, error)
+	SendRaw(ctx context.Context, payload interface{}) (*MessageResponse, error)
+}
+
+type InteractiveSender interface {
+	SendInteractiveList(recipientPhoneNumber string, bodyText string, buttonTitle string, items []ListItem, opts ...SendOption) (*MessageResponse, error)
+	SendInteractiveButtons(recipientPhoneNumber string, menuType, bodyText string, buttons []ButtonItem, opts ...SendOption) (*MessageResponse, error)
+}
+
+type MediaSender interface {
+	SendAudioToWhatsApp(recipientWAID string, filePath string, opts ...SendOption) (string, error)
+	SendImageToWhatsApp(recipientWAID string, filePath string, opts ...SendOption) (string, error)
+	SendAudioFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...SendOption) (string, error)
+	SendImageFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...SendOption) (string, error)
+	SendVideoToWhatsApp(recipientWAID string, filePath string, opts ...SendOption) (string, error)
+	SendVideoFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...SendOption) (string, error)
+}
+
+type MediaManager interface {
 	GetMediaURL(mediaID string) (*MediaUrl, error)
 	DownloadMedia(mediaUrl string) ([]byte, error)
 }
 
+type ReadMarker interface {
+	MessageRead(messageID string) error
+	Acknowledge(ctx context.Context, messageID string) error
+}
+
+// HTTPDoer is the part of *http.Client the client uses, so runtimes without
+// net/http's transport (WASM, TinyGo) can supply their own.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Whatsapp is the full client API. Prefer depending on the narrower
+// interfaces above when only part of it is needed.
+type Whatsapp interface {
+	MessageSender
+	InteractiveSender
+	TemplateSender
+	MediaSender
+	MediaManager
+	ReadMarker
+}
+
 type WhatsappClient struct {
 	Ctx         context.Context
 	apiURL      string
+	endpoint    Endpoint
+	apiVersion  string
 	accessToken string
-	client      *http.Client
+	client      HTTPDoer
+	limiters    []Limiter
+	tracers     []Tracer
+
+	skipValidation   bool
+	splitLongText    bool
+	maintenance      atomic.Bool
+	debug            func(DebugEntry)
+	debugOn          atomic.Bool
+	dryRun           bool
+	timeouts         Timeouts
+	headers          http.Header
+	onDeprecation    func(DeprecationWarning)
+	imageProcessor   ImageProcessor
+	retry            *RetryPolicy
+	pressure         *Backpressure
+	templates        TemplateLookup
+	logger           *slog.Logger
+	transport        *TransportSettings
+	usage            usageTracker
+	onUsage          func(BusinessUseCaseUsage)
+	adaptive         *AdaptiveThrottle
+	serviceWindows   *ServiceWindows
+	recipientHashKey []byte
 }
 
-func NewWhatsappClient(ctx context.Context, apiURL string, accessToken string, client *http.Client) Whatsapp {
-	log.Printf("apiURL: %s", apiURL)
-	log.Printf("accessToken: %s", accessToken)
-	return &WhatsappClient{
+func NewWhatsappClient(ctx context.Context, apiURL string, accessToken string, client *http.Client, opts ...Option) Whatsapp {
+	w := &WhatsappClient{
 		Ctx:         ctx,
 		apiURL:      apiURL,
 		accessToken: accessToken,
-		client:      client,
+		client:      http.DefaultClient,
+	}
+	endpoint, endpointErr := ParseEndpoint(apiURL)
+	w.endpoint = endpoint
+	if client != nil {
+		w.client = client
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if endpointErr != nil {
+		// The URL is still used as given for sends; only the calls derived
+		// from it fall back to the defaults.
+		w.log().Warn("API URL not understood, phone number and version calls use the defaults", "error", endpointErr)
+		if w.apiVersion != "" {
+			w.log().Warn("WithAPIVersion ignored: the API URL has no version to replace", "version", w.apiVersion)
+		}
+	} else if w.apiVersion != "" {
+		w.endpoint.Version = w.apiVersion
+		w.apiURL = w.endpoint.MessagesURL()
+	}
+	if w.transport != nil {
+		w.client = tunedClient(w.client, *w.transport)
+	}
+	if w.dryRun {
+		w.client = &dryRunDoer{}
+	} else {
+		w.client = &deprecationDoer{next: w.client, requested: w.APIVersion, fn: w.onDeprecation, log: w.log}
+	}
+	w.pressure = NewBackpressure()
+	w.client = &pressureDoer{next: w.client, pressure: w.pressure}
+	w.client = &usageDoer{next: w.client, tracker: &w.usage, fn: w.onUsage}
+	if w.adaptive != nil {
+		w.client = &adaptiveDoer{next: w.client, throttle: w.adaptive, usage: &w.usage, pressure: w.pressure}
+	}
+	if w.debug != nil {
+		w.client = &debugDoer{next: w.client, fn: w.debug, on: &w.debugOn, token: accessToken}
+	}
+	if len(w.tracers) > 0 {
+		w.client = &tracingDoer{next: w.client, tracers: w.tracers, graph: w.graphURL, hashKey: w.recipientHashKey}
+	}
+	if !w.timeouts.zero() {
+		w.client = &timeoutDoer{next: w.client, timeouts: w.timeouts, graph: w.graphURL}
+	}
+	if w.retry != nil {
+		w.client = &retryDoer{next: w.client, policy: w.retry}
+	}
+	if w.headers != nil {
+		w.client = &headerDoer{next: w.client, headers: w.headers}
 	}
+	return w
 }
 
-func (w *WhatsappClient) SendMessage(recipientWAID string, messageBody string) (*MessageResponse, error) {
+func (w *WhatsappClient) SendMessage(recipientWAID string, messageBody string, opts ...SendOption) (*MessageResponse, error) {
+	if w.splitLongText && needsSplit(messageBody) {
+		return w.sendSplitMessage(recipientWAID, messageBody, opts...)
+	}
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), validateTextBody(messageBody), o.validate()); err != nil {
+		return nil, err
+	}
 	messageData := map[string]interface{}{
 		"messaging_product": "whatsapp",
 		"recipient_type":    "individual",
@@ -53,47 +169,24 @@ func (w *WhatsappClient) SendMessage(recipientWAID string, messageBody string) (
 			"body": messageBody,
 		},
 	}
-
-	jsonData, err := json.Marshal(messageData)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling JSON: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", w.apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	token := fmt.Sprintf("Bearer %s", w.accessToken)
-	log.Printf("token: %s", token)
-	req.Header.Set("Authorization", token)
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
-	}
-
-	fmt.Printf("WhatsApp API Response Status: %d\n", resp.StatusCode)
-	fmt.Printf("WhatsApp API Response Body: %s\n", string(responseBody))
-	var messageResponse MessageResponse
-	err = json.Unmarshal(responseBody, &messageResponse)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling JSON: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return nil, fmt.Errorf("failed to send message, status code: %d, response: %s", resp.StatusCode, string(responseBody))
+	if o.callbackData != "" {
+		messageData["biz_opaque_callback_data"] = o.callbackData
 	}
 
-	return &messageResponse, nil
+	return w.postMessage(w.context(), recipientWAID, messageData)
 }
 
-func (w *WhatsappClient) SendInteractiveList(recipientPhoneNumber string, bodyText string, buttonTitle string, items []ListItem) (*MessageResponse, error) {
+func (w *WhatsappClient) SendInteractiveList(recipientPhoneNumber string, bodyText string, buttonTitle string, items []ListItem, opts ...SendOption) (*MessageResponse, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(
+		validateRecipient(recipientPhoneNumber),
+		validateRequired("body", bodyText),
+		validateLength("body", bodyText, MaxInteractiveBodyLength),
+		validateListItems(buttonTitle, items),
+		o.validate(),
+	); err != nil {
+		return nil, err
+	}
 	sections := []ListSection{
 		{
 			Rows: items,
@@ -117,15 +210,26 @@ func (w *WhatsappClient) SendInteractiveList(recipientPhoneNumber string, bodyTe
 		To:               recipientPhoneNumber,
 		Type:             "interactive",
 		Interactive:      interactive,
+		CallbackData:     o.callbackData,
 	}
 
 	return w.sendListMessage(message)
 }
 
-func (w *WhatsappClient) SendInteractiveButtons(recipientPhoneNumber string, menuType, bodyText string, buttons []ButtonItem) (*MessageResponse, error) {
+func (w *WhatsappClient) SendInteractiveButtons(recipientPhoneNumber string, menuType, bodyText string, buttons []ButtonItem, opts ...SendOption) (*MessageResponse, error) {
 	action := ButtonAction{}
 	if menuType == "text" {
-		return w.SendMessage(recipientPhoneNumber, bodyText)
+		return w.SendMessage(recipientPhoneNumber, bodyText, opts...)
+	}
+	o := collectSendOptions(opts)
+	if err := w.validate(
+		validateRecipient(recipientPhoneNumber),
+		validateRequired("body", bodyText),
+		validateLength("body", bodyText, MaxInteractiveBodyLength),
+		validateButtons(buttons),
+		o.validate(),
+	); err != nil {
+		return nil, err
 	}
 
 	if menuType == "location_request_message" {
@@ -169,108 +273,150 @@ func (w *WhatsappClient) SendInteractiveButtons(recipientPhoneNumber string, men
 		To:               recipientPhoneNumber,
 		Type:             "interactive",
 		Interactive:      interactive,
+		CallbackData:     o.callbackData,
 	}
 
 	return w.sendListMessage(message)
 }
 
+// sendListMessage posts an interactive message. Responses are decoded like
+// every other send: a *MessageResponse, or an *APIError from the error
+// envelope.
 func (w *WhatsappClient) sendListMessage(message WhatsAppMessage) (*MessageResponse, error) {
-	jsonData, err := json.Marshal(message)
-	if err != nil {
-		fmt.Println("Ошибка кодирования JSON:", err)
+	return w.postMessage(w.context(), message.To, message)
+}
 
+// context is the client's Ctx, for the methods that take none.
+func (w *WhatsappClient) context() context.Context {
+	if w.Ctx == nil {
+		return context.Background()
 	}
+	return w.Ctx
+}
 
-	log.Printf("JSON-сообщение: %s", string(jsonData))
-
-	req, err := http.NewRequest("POST", w.apiURL, bytes.NewBuffer(jsonData))
+func (w *WhatsappClient) SendAudioToWhatsApp(recipientWAID string, filePath string, opts ...SendOption) (string, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), o.validate()); err != nil {
+		return "", err
+	}
+	file, err := os.Open(filePath)
 	if err != nil {
-		fmt.Println("Ошибка создания HTTP-запроса:", err)
-
+		return "", fmt.Errorf("failed to open file: %v", err)
 	}
-
-	req.Header.Set("Content-Type", "application/json")
-	token := fmt.Sprintf("Bearer %s", w.accessToken)
-	log.Printf("token: %s", token)
-	req.Header.Set("Authorization", token)
-
-	resp, err := w.client.Do(req)
+	defer file.Close()
+	audio, err := w.checkVoiceNoteReader(file)
 	if err != nil {
-		fmt.Println("Ошибка отправки HTTP-запроса:", err)
-		return nil, err
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	fmt.Println("Статус код:", resp.Status)
-
-	var response MessageResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	mediaId, err := w.UploadMedia(w.context(), audio, filepath.Base(filePath), "audio/ogg")
 	if err != nil {
-		fmt.Println("Ошибка декодирования JSON ответа:", err)
+		return "", err
+	}
 
+	_, err = w.sendWhatsAppMedia(recipientWAID, mediaId, o)
+	if err != nil {
+		return "", err
 	}
-	fmt.Println("Тело ответа:", response)
-	return &response, nil
+	return mediaId, nil
 }
 
-func (w *WhatsappClient) SendAudioToWhatsApp(recipientWAID string, filePath string) (string, error) {
-	mediaId, err := w.uploadMedia(filePath, "audio/ogg")
+func (w *WhatsappClient) SendImageToWhatsApp(recipientWAID string, filePath string, opts ...SendOption) (string, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), o.validate()); err != nil {
+		return "", err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+	img, filename, mimeType, err := w.prepareImage(file, filepath.Base(filePath))
+	if err != nil {
+		return "", err
+	}
+	mediaId, err := w.UploadMedia(w.context(), img, filename, mimeType)
 	if err != nil {
 		return "", err
 	}
 
-	_, err = w.sendWhatsAppMedia(recipientWAID, mediaId)
+	err = w.sendWhatsAppImage(recipientWAID, mediaId, o)
 	if err != nil {
 		return "", err
 	}
 	return mediaId, nil
 }
 
-func (w *WhatsappClient) SendImageToWhatsApp(recipientWAID string, filePath string) (string, error) {
-	mediaId, err := w.uploadMedia(filePath, "image/jpeg")
+// SendAudioFrom uploads r as OGG audio and sends it, returning the media ID.
+// r must hold OGG/Opus audio so it shows as a voice note; see
+// ValidateVoiceNote.
+func (w *WhatsappClient) SendAudioFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...SendOption) (string, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), o.validate()); err != nil {
+		return "", err
+	}
+	audio, err := w.checkVoiceNoteReader(r)
 	if err != nil {
 		return "", err
 	}
-
-	err = w.sendWhatsAppImage(recipientWAID, mediaId)
+	mediaId, err := w.UploadMedia(ctx, audio, filename, "audio/ogg")
 	if err != nil {
 		return "", err
 	}
+	if _, err := w.sendWhatsAppMedia(recipientWAID, mediaId, o); err != nil {
+		return "", err
+	}
 	return mediaId, nil
 }
 
-func (w *WhatsappClient) uploadMedia(filePath, mediaType string) (string, error) {
-
-	file, err := os.Open(filePath)
+// SendImageFrom uploads r as a JPEG image and sends it, returning the media
+// ID. See WithImagePreprocessing for other formats and oversized images.
+func (w *WhatsappClient) SendImageFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...SendOption) (string, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientWAID), o.validate()); err != nil {
+		return "", err
+	}
+	img, filename, mimeType, err := w.prepareImage(r, filename)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %v", err)
+		return "", err
 	}
-	defer file.Close()
-
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-
-	// Add file part
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	mediaId, err := w.UploadMedia(ctx, img, filename, mimeType)
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %v", err)
+		return "", err
 	}
-	if _, err := io.Copy(part, file); err != nil {
-		return "", fmt.Errorf("failed to copy file: %v", err)
+	if err := w.sendWhatsAppImage(recipientWAID, mediaId, o); err != nil {
+		return "", err
 	}
+	return mediaId, nil
+}
 
-	// Add required fields
-	_ = writer.WriteField("type", mediaType)
+// UploadMedia uploads the contents of r as filename and returns the media ID.
+// Unlike the file path based senders it needs no file system. The body is
+// streamed from r, so memory use does not grow with the file size.
+func (w *WhatsappClient) UploadMedia(ctx context.Context, r io.Reader, filename, mimeType string) (string, error) {
+	// Everything but the file contents is written up front, so the request
+	// body is head, r, tail with no copy of r.
+	var head, tail bytes.Buffer
+	writer := multipart.NewWriter(&head)
+	_ = writer.WriteField("type", mimeType)
 	_ = writer.WriteField("messaging_product", "whatsapp")
-
+	if _, err := writer.CreateFormFile("file", filename); err != nil {
+		return "", fmt.Errorf("failed to create form file: %v", err)
+	}
+	headLen := head.Len()
 	if err := writer.Close(); err != nil {
 		return "", fmt.Errorf("failed to close writer: %v", err)
 	}
+	tail.Write(head.Bytes()[headLen:])
+	head.Truncate(headLen)
 
-	req, err := http.NewRequest("POST", w.apiURL, &requestBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", w.mediaURL(), io.MultiReader(&head, r, &tail))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %v", err)
 	}
+	req.ContentLength = -1
+	if size, ok := readerSize(r); ok {
+		req.ContentLength = int64(head.Len()+tail.Len()) + size
+	}
 	req.Header.Set("Authorization", "Bearer "+w.accessToken)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
@@ -282,7 +428,7 @@ func (w *WhatsappClient) uploadMedia(filePath, mediaType string) (string, error)
 
 	if resp.StatusCode >= 300 {
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, respBody)
+		return "", newAPIError(resp.StatusCode, respBody)
 	}
 
 	var response struct {
@@ -296,86 +442,64 @@ func (w *WhatsappClient) uploadMedia(filePath, mediaType string) (string, error)
 	return response.ID, nil
 }
 
-func (w *WhatsappClient) sendWhatsAppMedia(recipientPhone, mediaID string) (*MessageResponse, error) {
+// readerSize reports how many bytes are left in r when it can tell without
+// reading, so uploads can send a Content-Length instead of chunking.
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		pos, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - pos, true
+	}
+	return 0, false
+}
 
+func (w *WhatsappClient) sendWhatsAppMedia(recipientPhone, mediaID string, o sendOptions) (*MessageResponse, error) {
 	message := AudioMessage{
 		MessagingProduct: "whatsapp",
 		To:               recipientPhone,
 		Type:             "audio",
 	}
 	message.Audio.ID = mediaID
+	message.CallbackData = o.callbackData
 
-	body, err := json.Marshal(message)
+	response, err := w.postMessage(w.context(), recipientPhone, message)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", w.apiURL, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
-	}
-	token := fmt.Sprintf("Bearer %s", w.accessToken)
-	log.Printf("token: %s", token)
-	req.Header.Set("Authorization", token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("error: received status code %d", resp.StatusCode)
-	}
-	var response MessageResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		fmt.Println("Ошибка декодирования JSON ответа:", err)
 		return nil, err
 	}
-	fmt.Println("Audio sent successfully!")
-	return &response, nil
+	w.log().Debug("media sent", "type", "audio", "to", recipientPhone, "media_id", mediaID)
+	return response, nil
 }
 
-func (w *WhatsappClient) sendWhatsAppImage(recipientPhone, mediaID string) error {
+func (w *WhatsappClient) sendWhatsAppImage(recipientPhone, mediaID string, o sendOptions) error {
 	message := ImageMessage{
 		MessagingProduct: "whatsapp",
 		To:               recipientPhone,
 		Type:             "image",
 	}
 	message.Image.ID = mediaID
+	message.CallbackData = o.callbackData
 
-	body, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", w.apiURL, bytes.NewBuffer(body))
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %v", err)
-	}
-	token := fmt.Sprintf("Bearer %s", w.accessToken)
-	log.Printf("token: %s", token)
-	req.Header.Set("Authorization", token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send HTTP request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error: received status code %d - %s", resp.StatusCode, string(bodyBytes))
+	if _, err := w.postMessage(w.context(), recipientPhone, message); err != nil {
+		return err
 	}
-
-	fmt.Println("Image sent successfully!")
+	w.log().Debug("media sent", "type", "image", "to", recipientPhone, "media_id", mediaID)
 	return nil
 }
 
-func (w *WhatsappClient) SendWhatsAppLocation(recipientPhone string, latitude, longitude float64, name, address string) (*MessageResponse, error) {
+func (w *WhatsappClient) SendWhatsAppLocation(recipientPhone string, latitude, longitude float64, name, address string, opts ...SendOption) (*MessageResponse, error) {
+	o := collectSendOptions(opts)
+	if err := w.validate(validateRecipient(recipientPhone), validateCoordinates(latitude, longitude), o.validate()); err != nil {
+		return nil, err
+	}
 	message := LocationMessage{
 		MessagingProduct: "whatsapp",
 		To:               recipientPhone,
@@ -385,68 +509,23 @@ func (w *WhatsappClient) SendWhatsAppLocation(recipientPhone string, latitude, l
 	message.Location.Longitude = longitude
 	message.Location.Name = name
 	message.Location.Address = address
+	message.CallbackData = o.callbackData
 
-	body, err := json.Marshal(message)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", w.apiURL, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
-	}
-	token := fmt.Sprintf("Bearer %s", w.accessToken)
-	log.Printf("token: %s", token)
-	req.Header.Set("Authorization", token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("error: received status code %d - %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	fmt.Println("Location sent successfully!")
-
-	var response MessageResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		fmt.Println("Ошибка декодирования JSON ответа:", err)
-		return nil, err
-	}
-
-	return &response, nil
+	return w.postMessage(w.context(), recipientPhone, message)
 }
+
 func (w *WhatsappClient) GetMediaURL(mediaID string) (*MediaUrl, error) {
 	var mediaUrl MediaUrl
-	url := fmt.Sprintf("%s/%s", "https://graph.facebook.com/v17.0", mediaID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
+	if err := w.callGraph(w.context(), "GET", w.graphURL()+"/"+mediaID, nil, &mediaUrl); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.accessToken))
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&mediaUrl)
-	if err != nil {
-		return nil, err
-	}
-
 	return &mediaUrl, nil
 }
 
+// DownloadMedia fetches the bytes behind a URL from GetMediaURL. A non-2xx
+// response is returned as *APIError.
 func (w *WhatsappClient) DownloadMedia(mediaUrl string) ([]byte, error) {
-	req, err := http.NewRequest("GET", mediaUrl, nil)
+	req, err := http.NewRequestWithContext(w.context(), "GET", mediaUrl, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -462,7 +541,9 @@ func (w *WhatsappClient) DownloadMedia(mediaUrl string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-
+	if resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
 	return body, nil
 }
 
@@ -472,28 +553,18 @@ func (w *WhatsappClient) MessageRead(messageID string) error {
 		Status:           "read",
 		MessageId:        messageID,
 	}
+	return w.callGraph(w.context(), "POST", w.apiURL, request, nil)
+}
 
-	body, err := json.Marshal(request)
-	if err != nil {
-		return err
-	}
-	url := fmt.Sprintf("%s/%s", "https://graph.facebook.com/v17.0", messageID)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.accessToken))
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error: received status code %d - %s", resp.StatusCode, string(bodyBytes))
+// Acknowledge marks the incoming message read and shows the typing
+// indicator to its sender. The indicator clears when the reply is sent, or
+// after about 25 seconds.
+func (w *WhatsappClient) Acknowledge(ctx context.Context, messageID string) error {
+	request := MessageStatus{
+		MessagingProduct: "whatsapp",
+		Status:           "read",
+		MessageId:        messageID,
+		TypingIndicator:  &TypingIndicator{Type: "text"},
 	}
-
-	return nil
+	return w.callGraph(ctx, "POST", w.apiURL, request, nil)
 }
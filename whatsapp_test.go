@@ -0,0 +1,152 @@
+package whatsappdau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendPathsReturnAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   int
+		target error
+	}{
+		{"reengagement", http.StatusBadRequest, ErrCodeReengagementRequired, ErrReengagementRequired},
+		{"throughput", http.StatusBadRequest, ErrCodeThroughputExceeded, ErrRateLimited},
+		{"pair rate limit", http.StatusBadRequest, ErrCodePairRateLimit, ErrPairRateLimited},
+		{"token expired", http.StatusUnauthorized, ErrCodeTokenExpired, ErrTokenExpired},
+		{"template missing", http.StatusNotFound, ErrCodeTemplateNotFound, ErrTemplateNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(tt.status)
+				fmt.Fprintf(rw, `{"error":{"message":"failed","code":%d}}`, tt.code)
+			}))
+			defer srv.Close()
+			w := NewWhatsappClient(context.Background(), srv.URL+"/v21.0/123/messages", "token", nil).(*WhatsappClient)
+
+			calls := map[string]func() error{
+				"SendMessage": func() error {
+					_, err := w.SendMessage("15551234567", "hi")
+					return err
+				},
+				"SendWhatsAppLocation": func() error {
+					_, err := w.SendWhatsAppLocation("15551234567", 1, 2, "", "")
+					return err
+				},
+				"sendWhatsAppMedia": func() error {
+					_, err := w.sendWhatsAppMedia("15551234567", "media-1", sendOptions{})
+					return err
+				},
+				"sendWhatsAppImage": func() error {
+					return w.sendWhatsAppImage("15551234567", "media-1", sendOptions{})
+				},
+				"MessageRead": func() error {
+					return w.MessageRead("wamid.X")
+				},
+				"GetMediaURL": func() error {
+					_, err := w.GetMediaURL("media-1")
+					return err
+				},
+				"DownloadMedia": func() error {
+					_, err := w.DownloadMedia(srv.URL + "/media-1")
+					return err
+				},
+			}
+			for name, call := range calls {
+				err := call()
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Errorf("%s: got %v, want *APIError", name, err)
+					continue
+				}
+				if apiErr.StatusCode != tt.status || apiErr.Code != tt.code {
+					t.Errorf("%s: got status %d code %d, want %d %d", name, apiErr.StatusCode, apiErr.Code, tt.status, tt.code)
+				}
+				if !errors.Is(err, tt.target) {
+					t.Errorf("%s: errors.Is(%v, %v) = false", name, err, tt.target)
+				}
+			}
+		})
+	}
+}
+
+func TestSendMessageSuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"messaging_product":"whatsapp","messages":[{"id":"wamid.1"}]}`)
+	}))
+	defer srv.Close()
+	w := NewWhatsappClient(context.Background(), srv.URL+"/v21.0/123/messages", "token", nil)
+
+	resp, err := w.SendMessage("15551234567", "hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 1 || resp.Messages[0].Id != "wamid.1" {
+		t.Fatalf("got %+v", resp)
+	}
+}
+
+func TestClientOptionsAreDeterministic(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	const apiURL = "https://graph.facebook.com/v21.0/123/messages"
+	tests := []struct {
+		name    string
+		url     string
+		opts    []Option
+		wantURL string
+		version string
+		logged  []string
+	}{
+		{"version", apiURL, []Option{WithAPIVersion("v23.0")}, "https://graph.facebook.com/v23.0/123/messages", "v23.0", nil},
+		{"last version wins", apiURL, []Option{WithAPIVersion("v22.0"), WithAPIVersion("v23.0")}, "https://graph.facebook.com/v23.0/123/messages", "v23.0", nil},
+		{"unparsable URL", "http://proxy.local/send", nil, "http://proxy.local/send", "", []string{"API URL not understood"}},
+		{"version without phone number ID", "http://proxy.local/send", []Option{WithAPIVersion("v23.0")}, "http://proxy.local/send", "", []string{"API URL not understood", "WithAPIVersion ignored"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			w := NewWhatsappClient(nil, tt.url, "token", nil, tt.opts...).(*WhatsappClient)
+			if w.apiURL != tt.wantURL {
+				t.Errorf("apiURL = %q, want %q", w.apiURL, tt.wantURL)
+			}
+			if got := w.APIVersion(); got != tt.version {
+				t.Errorf("APIVersion() = %q, want %q", got, tt.version)
+			}
+			for _, msg := range tt.logged {
+				if !strings.Contains(buf.String(), msg) {
+					t.Errorf("log %q lacks %q", buf.String(), msg)
+				}
+			}
+			if tt.logged == nil && buf.Len() > 0 {
+				t.Errorf("unexpected log %q", buf.String())
+			}
+		})
+	}
+
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		agents = r.Header.Values("User-Agent")
+		rw.Write([]byte(`{"messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer srv.Close()
+	w := NewWhatsappClient(nil, srv.URL+"/v21.0/1/messages", "token", nil, WithUserAgent("app/1"), WithUserAgent("app/2"))
+	if _, err := w.SendMessage("15551234567", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 || agents[0] != "app/2" {
+		t.Errorf("User-Agent = %q, want [app/2]", agents)
+	}
+}
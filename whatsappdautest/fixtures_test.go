@@ -0,0 +1,52 @@
+package whatsappdautest
+
+import (
+	"testing"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+func TestFixtures(t *testing.T) {
+	value := func(n *whatsappdau.WebhookNotification) whatsappdau.WebhookValue {
+		return n.Entry[0].Changes[0].Value
+	}
+	tests := map[string]func(n *whatsappdau.WebhookNotification) bool{
+		FixtureSystem: func(n *whatsappdau.WebhookNotification) bool {
+			m := value(n).Messages[0]
+			return m.Type == "system" && m.System != nil && m.System.NewWaID != ""
+		},
+		FixtureUnsupported: func(n *whatsappdau.WebhookNotification) bool {
+			m := value(n).Messages[0]
+			return m.Type == "unsupported" && len(m.Errors) == 1
+		},
+		FixtureContacts: func(n *whatsappdau.WebhookNotification) bool {
+			m := value(n).Messages[0]
+			return m.Type == "contacts" && m.KnownType() && len(m.Contacts) == 1 && m.Contacts[0].Phones[0].WaID == "19405551234"
+		},
+		FixtureOrder: func(n *whatsappdau.WebhookNotification) bool {
+			m := value(n).Messages[0]
+			return m.Type == "order" && m.KnownType() && m.Order != nil && len(m.Order.ProductItems) == 2 &&
+				m.Order.ProductItems[0].Quantity == "2" && m.Order.ProductItems[0].ItemPrice == "12.50"
+		},
+		FixtureReferral: func(n *whatsappdau.WebhookNotification) bool {
+			r := value(n).Messages[0].Referral
+			return r != nil && r.SourceType == "ad" && r.CtwaClid != ""
+		},
+		FixtureEcho: func(n *whatsappdau.WebhookNotification) bool {
+			v := value(n)
+			return n.Entry[0].Changes[0].Field == "smb_message_echoes" && len(v.MessageEchoes) == 1 && v.MessageEchoes[0].To != ""
+		},
+		FixtureErrors: func(n *whatsappdau.WebhookNotification) bool {
+			v := value(n)
+			return len(v.Errors) == 1 && len(v.Messages) == 0 && len(v.Statuses) == 0
+		},
+	}
+	for name, check := range tests {
+		if !check(Notification(name)) {
+			t.Errorf("fixture %q does not hold the expected event", name)
+		}
+	}
+	for _, name := range FixtureNames() {
+		Notification(name)
+	}
+}
@@ -0,0 +1,253 @@
+package whatsappdautest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+var _ whatsappdau.Whatsapp = (*MockClient)(nil)
+
+// Call is a single recorded invocation on a MockClient. Opts are the
+// SendOptions passed to a send, CallbackData what they set.
+type Call struct {
+	Method       string
+	Args         []interface{}
+	Opts         []whatsappdau.SendOption
+	CallbackData string
+}
+
+// MockClient implements whatsappdau.Whatsapp in memory. Every call is
+// recorded. By default sends succeed with a synthetic message ID, and media
+// sends return a synthetic media ID as the real client returns the uploaded
+// one; use FailWith, Respond or the *Func fields to program other outcomes.
+type MockClient struct {
+	SendMessageFunc            func(to, message string) (*whatsappdau.MessageResponse, error)
+	SendInteractiveListFunc    func(to, bodyText, buttonTitle string, items []whatsappdau.ListItem) (*whatsappdau.MessageResponse, error)
+	SendInteractiveButtonsFunc func(to, menuType, bodyText string, buttons []whatsappdau.ButtonItem) (*whatsappdau.MessageResponse, error)
+
+	mu        sync.Mutex
+	calls     []Call
+	errs      map[string][]error
+	responses map[string][]*whatsappdau.MessageResponse
+	media     map[string][]byte
+	nextID    int
+	nextMedia int
+}
+
+func NewMockClient() *MockClient {
+	return &MockClient{
+		errs:      make(map[string][]error),
+		responses: make(map[string][]*whatsappdau.MessageResponse),
+		media:     make(map[string][]byte),
+	}
+}
+
+// FailWith queues err as the result of the next call to method.
+func (m *MockClient) FailWith(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs[method] = append(m.errs[method], err)
+}
+
+// Respond queues resp as the result of the next call to method.
+func (m *MockClient) Respond(method string, resp *whatsappdau.MessageResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[method] = append(m.responses[method], resp)
+}
+
+// AddMedia makes data available to GetMediaURL and DownloadMedia under id.
+func (m *MockClient) AddMedia(id string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.media[id] = data
+}
+
+func (m *MockClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *MockClient) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, c := range m.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// LastCall returns the most recent call to method, or false if there was none.
+func (m *MockClient) LastCall(method string) (Call, bool) {
+	calls := m.CallsTo(method)
+	if len(calls) == 0 {
+		return Call{}, false
+	}
+	return calls[len(calls)-1], true
+}
+
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.errs = make(map[string][]error)
+	m.responses = make(map[string][]*whatsappdau.MessageResponse)
+}
+
+// record stores the call and pops any programmed outcome for it.
+func (m *MockClient) record(method string, args ...interface{}) (*whatsappdau.MessageResponse, error) {
+	return m.recordSend(method, nil, args...)
+}
+
+// recordSend is record for a send, keeping its options on the call.
+func (m *MockClient) recordSend(method string, opts []whatsappdau.SendOption, args ...interface{}) (*whatsappdau.MessageResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args, Opts: opts, CallbackData: whatsappdau.CallbackData(opts...)})
+
+	if errs := m.errs[method]; len(errs) > 0 {
+		m.errs[method] = errs[1:]
+		return nil, errs[0]
+	}
+	if resps := m.responses[method]; len(resps) > 0 {
+		m.responses[method] = resps[1:]
+		return resps[0], nil
+	}
+
+	m.nextID++
+	to, _ := args[0].(string)
+	return &whatsappdau.MessageResponse{
+		MessagingProduct: "whatsapp",
+		Contacts:         []whatsappdau.Contacts{{Input: to, WaId: to}},
+		Messages:         []whatsappdau.Messages{{Id: fmt.Sprintf("wamid.MOCK%06d", m.nextID)}},
+	}, nil
+}
+
+// sendMedia records a media send and returns a new media ID. Like the real
+// client it ignores the message response, so a response queued with Respond
+// may have no messages.
+func (m *MockClient) sendMedia(method string, opts []whatsappdau.SendOption, args ...interface{}) (string, error) {
+	if _, err := m.recordSend(method, opts, args...); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextMedia++
+	return fmt.Sprintf("media.MOCK%06d", m.nextMedia), nil
+}
+
+func (m *MockClient) SendMessage(to string, message string, opts ...whatsappdau.SendOption) (*whatsappdau.MessageResponse, error) {
+	resp, err := m.recordSend("SendMessage", opts, to, message)
+	if m.SendMessageFunc != nil {
+		return m.SendMessageFunc(to, message)
+	}
+	return resp, err
+}
+
+func (m *MockClient) SendTemplate(ctx context.Context, to string, t whatsappdau.TemplateMessage, opts ...whatsappdau.SendOption) (*whatsappdau.MessageResponse, error) {
+	return m.recordSend("SendTemplate", opts, to, t)
+}
+
+func (m *MockClient) SendContacts(ctx context.Context, to string, contacts []whatsappdau.Contact, opts ...whatsappdau.SendOption) (*whatsappdau.MessageResponse, error) {
+	return m.recordSend("SendContacts", opts, to, contacts)
+}
+
+func (m *MockClient) SendRaw(ctx context.Context, payload interface{}) (*whatsappdau.MessageResponse, error) {
+	return m.record("SendRaw", payload)
+}
+
+func (m *MockClient) SendAudioToWhatsApp(recipientWAID string, filePath string, opts ...whatsappdau.SendOption) (string, error) {
+	return m.sendMedia("SendAudioToWhatsApp", opts, recipientWAID, filePath)
+}
+
+func (m *MockClient) SendImageToWhatsApp(recipientWAID string, filePath string, opts ...whatsappdau.SendOption) (string, error) {
+	return m.sendMedia("SendImageToWhatsApp", opts, recipientWAID, filePath)
+}
+
+// SendAudioFrom records the filename; the reader is not consumed.
+func (m *MockClient) SendAudioFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...whatsappdau.SendOption) (string, error) {
+	return m.sendMedia("SendAudioFrom", opts, recipientWAID, filename)
+}
+
+// SendImageFrom records the filename; the reader is not consumed.
+func (m *MockClient) SendImageFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...whatsappdau.SendOption) (string, error) {
+	return m.sendMedia("SendImageFrom", opts, recipientWAID, filename)
+}
+
+func (m *MockClient) SendVideoToWhatsApp(recipientWAID string, filePath string, opts ...whatsappdau.SendOption) (string, error) {
+	return m.sendMedia("SendVideoToWhatsApp", opts, recipientWAID, filePath)
+}
+
+// SendVideoFrom records the filename; the reader is not consumed.
+func (m *MockClient) SendVideoFrom(ctx context.Context, recipientWAID string, r io.Reader, filename string, opts ...whatsappdau.SendOption) (string, error) {
+	return m.sendMedia("SendVideoFrom", opts, recipientWAID, filename)
+}
+
+func (m *MockClient) SendInteractiveList(recipientPhoneNumber string, bodyText string, buttonTitle string, items []whatsappdau.ListItem, opts ...whatsappdau.SendOption) (*whatsappdau.MessageResponse, error) {
+	resp, err := m.recordSend("SendInteractiveList", opts, recipientPhoneNumber, bodyText, buttonTitle, items)
+	if m.SendInteractiveListFunc != nil {
+		return m.SendInteractiveListFunc(recipientPhoneNumber, bodyText, buttonTitle, items)
+	}
+	return resp, err
+}
+
+func (m *MockClient) SendWhatsAppLocation(recipientPhone string, latitude, longitude float64, name, address string, opts ...whatsappdau.SendOption) (*whatsappdau.MessageResponse, error) {
+	return m.recordSend("SendWhatsAppLocation", opts, recipientPhone, latitude, longitude, name, address)
+}
+
+func (m *MockClient) SendInteractiveButtons(recipientPhoneNumber string, menuType, bodyText string, buttons []whatsappdau.ButtonItem, opts ...whatsappdau.SendOption) (*whatsappdau.MessageResponse, error) {
+	resp, err := m.recordSend("SendInteractiveButtons", opts, recipientPhoneNumber, menuType, bodyText, buttons)
+	if m.SendInteractiveButtonsFunc != nil {
+		return m.SendInteractiveButtonsFunc(recipientPhoneNumber, menuType, bodyText, buttons)
+	}
+	return resp, err
+}
+
+func (m *MockClient) MessageRead(messageID string) error {
+	_, err := m.record("MessageRead", messageID)
+	return err
+}
+
+func (m *MockClient) Acknowledge(ctx context.Context, messageID string) error {
+	_, err := m.record("Acknowledge", messageID)
+	return err
+}
+
+func (m *MockClient) GetMediaURL(mediaID string) (*whatsappdau.MediaUrl, error) {
+	if _, err := m.record("GetMediaURL", mediaID); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	data, ok := m.media[mediaID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("mock: unknown media id %q", mediaID)
+	}
+	return &whatsappdau.MediaUrl{
+		Id:       mediaID,
+		FileSize: len(data),
+		Url:      "mock://media/" + mediaID,
+	}, nil
+}
+
+func (m *MockClient) DownloadMedia(mediaUrl string) ([]byte, error) {
+	if _, err := m.record("DownloadMedia", mediaUrl); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, data := range m.media {
+		if mediaUrl == "mock://media/"+id {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("mock: unknown media url %q", mediaUrl)
+}
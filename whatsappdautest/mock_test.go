@@ -0,0 +1,47 @@
+package whatsappdautest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+func TestMockMediaSends(t *testing.T) {
+	sends := map[string]func(m *MockClient) (string, error){
+		"SendAudioToWhatsApp": func(m *MockClient) (string, error) {
+			return m.SendAudioToWhatsApp("15551234567", "a.ogg", whatsappdau.WithCallbackData("order-1"))
+		},
+		"SendImageToWhatsApp": func(m *MockClient) (string, error) {
+			return m.SendImageToWhatsApp("15551234567", "a.jpg", whatsappdau.WithCallbackData("order-1"))
+		},
+		"SendVideoToWhatsApp": func(m *MockClient) (string, error) {
+			return m.SendVideoToWhatsApp("15551234567", "a.mp4", whatsappdau.WithCallbackData("order-1"))
+		},
+		"SendAudioFrom": func(m *MockClient) (string, error) {
+			return m.SendAudioFrom(context.Background(), "15551234567", nil, "a.ogg", whatsappdau.WithCallbackData("order-1"))
+		},
+		"SendImageFrom": func(m *MockClient) (string, error) {
+			return m.SendImageFrom(context.Background(), "15551234567", nil, "a.jpg", whatsappdau.WithCallbackData("order-1"))
+		},
+		"SendVideoFrom": func(m *MockClient) (string, error) {
+			return m.SendVideoFrom(context.Background(), "15551234567", nil, "a.mp4", whatsappdau.WithCallbackData("order-1"))
+		},
+	}
+	for method, send := range sends {
+		m := NewMockClient()
+		m.Respond(method, &whatsappdau.MessageResponse{})
+		id, err := send(m)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if !strings.HasPrefix(id, "media.") {
+			t.Errorf("%s: got %q, want a media ID", method, id)
+		}
+		call, ok := m.LastCall(method)
+		if !ok || call.CallbackData != "order-1" || len(call.Opts) != 1 {
+			t.Errorf("%s: got call %+v, want callback data recorded", method, call)
+		}
+	}
+}
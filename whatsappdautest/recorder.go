@@ -0,0 +1,220 @@
+package whatsappdautest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper for golden-file tests of the requests
+// the library makes:
+//
+//	rec := whatsappdautest.NewRecorder("testdata/send_text")
+//	rec.Update = *update // a -update flag in the test package
+//	client := whatsappdau.NewWhatsappClient(ctx, url, "token", rec.Client())
+//	client.SendMessage("15551234567", "hi")
+//	rec.Verify(t)
+//
+// Request N is compared with Dir/NNN.request, holding the method, path and
+// body with JSON indented and multipart boundaries replaced so the files are
+// stable. Its response is the next one queued with Respond, else the body of
+// Dir/NNN.response.json if there is one, else a generic success. With Next
+// set, requests go to it instead and Update also saves its responses.
+type Recorder struct {
+	Dir string
+	// Update makes Verify write the golden files instead of comparing.
+	Update bool
+	Next   http.RoundTripper
+
+	mu        sync.Mutex
+	requests  []string
+	responses []cannedResponse
+}
+
+type cannedResponse struct {
+	status int
+	body   string
+}
+
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{Dir: dir}
+}
+
+// Client returns an *http.Client using the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Respond queues a response for the next request.
+func (r *Recorder) Respond(status int, body string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, cannedResponse{status: status, body: body})
+}
+
+// Requests returns the normalized requests recorded so far.
+func (r *Recorder) Requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.requests...)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	r.requests = append(r.requests, normalizeRequest(req, body))
+	n := len(r.requests)
+	var canned *cannedResponse
+	if len(r.responses) > 0 {
+		canned = &r.responses[0]
+		r.responses = r.responses[1:]
+	}
+	r.mu.Unlock()
+
+	if canned != nil {
+		return cannedHTTPResponse(req, canned.status, []byte(canned.body)), nil
+	}
+	if r.Next != nil {
+		forward := req.Clone(req.Context())
+		forward.Body = io.NopCloser(bytes.NewReader(body))
+		resp, err := r.Next.RoundTrip(forward)
+		if err != nil || !r.Update {
+			return resp, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if err := r.write(r.responsePath(n), data); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	if data, err := os.ReadFile(r.responsePath(n)); err == nil {
+		return cannedHTTPResponse(req, http.StatusOK, data), nil
+	}
+	return cannedHTTPResponse(req, http.StatusOK, defaultResponse(req, n)), nil
+}
+
+// Verify compares the recorded requests with the golden files, or writes
+// them when Update is set.
+func (r *Recorder) Verify(t TB) {
+	t.Helper()
+	requests := r.Requests()
+	if r.Update {
+		stale, _ := filepath.Glob(filepath.Join(r.Dir, "*.request"))
+		for _, path := range stale {
+			os.Remove(path)
+		}
+		for i, got := range requests {
+			if err := r.write(r.requestPath(i+1), []byte(got)); err != nil {
+				t.Fatalf("recorder: %v", err)
+			}
+		}
+		return
+	}
+
+	for i, got := range requests {
+		path := r.requestPath(i + 1)
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("recorder: request %d has no golden file (run with Update to create it):\n%s", i+1, got)
+		}
+		if string(want) != got {
+			t.Fatalf("recorder: request %d differs from %s\n--- want\n%s\n--- got\n%s", i+1, path, want, got)
+		}
+	}
+	if _, err := os.Stat(r.requestPath(len(requests) + 1)); err == nil {
+		t.Fatalf("recorder: %d requests made, but %s expects more", len(requests), r.Dir)
+	}
+}
+
+func (r *Recorder) requestPath(n int) string {
+	return filepath.Join(r.Dir, fmt.Sprintf("%03d.request", n))
+}
+
+func (r *Recorder) responsePath(n int) string {
+	return filepath.Join(r.Dir, fmt.Sprintf("%03d.response.json", n))
+}
+
+func (r *Recorder) write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// normalizeRequest renders req for a golden file. The Authorization header
+// and anything else outside the method, path and body are left out.
+func normalizeRequest(req *http.Request, body []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", req.Method, req.URL.RequestURI())
+	if len(body) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n")
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json":
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if dec.Decode(&v) == nil {
+			// Re-encoding sorts object keys, so field order changes don't
+			// show up as diffs.
+			pretty, _ := json.MarshalIndent(v, "", "  ")
+			body = pretty
+		}
+	case strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "":
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), []byte("BOUNDARY"))
+	}
+	b.Write(body)
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// defaultResponse is a plausible success body for req.
+func defaultResponse(req *http.Request, n int) []byte {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/messages"):
+		return []byte(fmt.Sprintf(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.RECORDED%03d"}]}`, n))
+	case strings.HasSuffix(req.URL.Path, "/media"):
+		return []byte(fmt.Sprintf(`{"id":"media-%03d"}`, n))
+	default:
+		return []byte(`{"success":true}`)
+	}
+}
+
+func cannedHTTPResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
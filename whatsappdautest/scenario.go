@@ -0,0 +1,356 @@
+package whatsappdautest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+// TB is the subset of testing.TB a Scenario needs.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// Expectation checks one outbound bot message. It returns a description of
+// the mismatch, or "" when call is acceptable.
+type Expectation struct {
+	Description string
+	Check       func(call Call) string
+}
+
+// Scenario is a scripted conversation between a user and a bot:
+//
+//	NewScenario("order tracking").
+//		UserSends("hi").
+//		BotReplies(ListWithRows("track_order")).
+//		UserSelectsRow("track_order", "Track order").
+//		BotReplies(TextContaining("on its way")).
+//		Run(t, dispatcher, mock)
+//
+// Each user step is dispatched as a webhook; the bot's replies are the calls
+// it makes on the MockClient in response.
+type Scenario struct {
+	name        string
+	from        string
+	contactName string
+	steps       []scenarioStep
+}
+
+type scenarioStep struct {
+	describe string
+	message  *whatsappdau.IncomingMessage
+	expect   []Expectation
+	silent   bool
+}
+
+func NewScenario(name string) *Scenario {
+	return &Scenario{name: name, from: "15551230000", contactName: "Test User"}
+}
+
+// From sets who the simulated user is.
+func (s *Scenario) From(waID, name string) *Scenario {
+	s.from = waID
+	s.contactName = name
+	return s
+}
+
+func (s *Scenario) UserSends(text string) *Scenario {
+	return s.user(fmt.Sprintf("user sends %q", text), whatsappdau.IncomingMessage{
+		Type: "text",
+		Text: &whatsappdau.IncomingText{Body: text},
+	})
+}
+
+func (s *Scenario) UserTapsButton(id, title string) *Scenario {
+	return s.user(fmt.Sprintf("user taps button %q", id), whatsappdau.IncomingMessage{
+		Type: "interactive",
+		Interactive: &whatsappdau.IncomingInteractive{
+			Type:        "button_reply",
+			ButtonReply: &whatsappdau.InteractiveReply{ID: id, Title: title},
+		},
+	})
+}
+
+func (s *Scenario) UserSelectsRow(id, title string) *Scenario {
+	return s.user(fmt.Sprintf("user selects row %q", id), whatsappdau.IncomingMessage{
+		Type: "interactive",
+		Interactive: &whatsappdau.IncomingInteractive{
+			Type:      "list_reply",
+			ListReply: &whatsappdau.InteractiveReply{ID: id, Title: title},
+		},
+	})
+}
+
+func (s *Scenario) UserSendsLocation(latitude, longitude float64) *Scenario {
+	return s.user(fmt.Sprintf("user shares location %v,%v", latitude, longitude), whatsappdau.IncomingMessage{
+		Type:     "location",
+		Location: &whatsappdau.IncomingLocation{Latitude: latitude, Longitude: longitude},
+	})
+}
+
+// UserSendsMessage dispatches msg as-is, for message types without a helper.
+// From, ID and Timestamp are filled in when empty.
+func (s *Scenario) UserSendsMessage(msg whatsappdau.IncomingMessage) *Scenario {
+	return s.user(fmt.Sprintf("user sends %s message", msg.Type), msg)
+}
+
+func (s *Scenario) user(describe string, msg whatsappdau.IncomingMessage) *Scenario {
+	s.steps = append(s.steps, scenarioStep{describe: describe, message: &msg})
+	return s
+}
+
+// BotReplies expects the bot to answer the previous user step with exactly
+// these messages, in order.
+func (s *Scenario) BotReplies(expectations ...Expectation) *Scenario {
+	descs := make([]string, len(expectations))
+	for i, e := range expectations {
+		descs[i] = e.Description
+	}
+	s.steps = append(s.steps, scenarioStep{describe: "bot replies with " + strings.Join(descs, ", then "), expect: expectations})
+	return s
+}
+
+// BotSendsNothing expects no reply to the previous user step.
+func (s *Scenario) BotSendsNothing() *Scenario {
+	s.steps = append(s.steps, scenarioStep{describe: "bot sends nothing", silent: true})
+	return s
+}
+
+// Run plays the scenario against d, with the bot sending through mock.
+func (s *Scenario) Run(t TB, d *whatsappdau.Dispatcher, mock *MockClient) {
+	t.Helper()
+	ctx := context.Background()
+
+	var transcript []string
+	var pending []Call
+	seq := 0
+	fail := func(step int, format string, args ...interface{}) {
+		t.Helper()
+		t.Fatalf("scenario %q failed at step %d (%s):\n%s\n\ntranscript:\n%s",
+			s.name, step+1, s.steps[step].describe, fmt.Sprintf(format, args...), strings.Join(transcript, "\n"))
+	}
+
+	for i, step := range s.steps {
+		if step.message != nil {
+			if len(pending) > 0 {
+				fail(i, "bot sent %d message(s) nobody expected:\n%s", len(pending), describeCalls(pending))
+			}
+			seq++
+			msg := *step.message
+			if msg.From == "" {
+				msg.From = s.from
+			}
+			if msg.ID == "" {
+				msg.ID = fmt.Sprintf("wamid.SCENARIO%04d", seq)
+			}
+			if msg.Timestamp == "" {
+				msg.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+			}
+
+			before := len(mock.Calls())
+			transcript = append(transcript, "  > "+step.describe)
+			notification := NewWebhook().Contact(msg.From, s.contactName).Message(msg).Notification()
+			if err := d.Dispatch(ctx, notification); err != nil {
+				fail(i, "handler returned error: %v", err)
+			}
+			pending = outbound(mock.Calls()[before:])
+			for _, c := range pending {
+				transcript = append(transcript, "  < "+describeCall(c))
+			}
+			continue
+		}
+
+		if step.silent {
+			if len(pending) > 0 {
+				fail(i, "expected no reply, got:\n%s", describeCalls(pending))
+			}
+			continue
+		}
+
+		if len(pending) != len(step.expect) {
+			fail(i, "expected %d message(s), got %d:\n%s", len(step.expect), len(pending), describeCalls(pending))
+		}
+		for j, e := range step.expect {
+			if problem := e.Check(pending[j]); problem != "" {
+				fail(i, "message %d: expected %s\n  got:  %s\n  diff: %s", j+1, e.Description, describeCall(pending[j]), problem)
+			}
+		}
+		pending = nil
+	}
+
+	if len(pending) > 0 {
+		t.Fatalf("scenario %q: bot sent %d message(s) after the last step nobody expected:\n%s\n\ntranscript:\n%s",
+			s.name, len(pending), describeCalls(pending), strings.Join(transcript, "\n"))
+	}
+}
+
+var outboundMethods = map[string]bool{
+	"SendMessage":            true,
+	"SendRaw":                true,
+	"SendTemplate":           true,
+	"SendContacts":           true,
+	"SendAudioToWhatsApp":    true,
+	"SendImageToWhatsApp":    true,
+	"SendAudioFrom":          true,
+	"SendImageFrom":          true,
+	"SendVideoToWhatsApp":    true,
+	"SendVideoFrom":          true,
+	"SendInteractiveList":    true,
+	"SendInteractiveButtons": true,
+	"SendWhatsAppLocation":   true,
+}
+
+// outbound filters calls down to the ones that send a message.
+func outbound(calls []Call) []Call {
+	var sent []Call
+	for _, c := range calls {
+		if outboundMethods[c.Method] {
+			sent = append(sent, c)
+		}
+	}
+	return sent
+}
+
+func describeCalls(calls []Call) string {
+	lines := make([]string, len(calls))
+	for i, c := range calls {
+		lines[i] = "  - " + describeCall(c)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describeCall(c Call) string {
+	switch c.Method {
+	case "SendMessage":
+		return fmt.Sprintf("text %q", c.Args[1])
+	case "SendInteractiveList":
+		items, _ := c.Args[3].([]whatsappdau.ListItem)
+		return fmt.Sprintf("list %q rows %v", c.Args[1], listRowIDs(items))
+	case "SendInteractiveButtons":
+		buttons, _ := c.Args[3].([]whatsappdau.ButtonItem)
+		return fmt.Sprintf("%s %q buttons %v", c.Args[1], c.Args[2], buttonIDs(buttons))
+	case "SendTemplate":
+		t, _ := c.Args[1].(whatsappdau.TemplateMessage)
+		return fmt.Sprintf("template %s (%s)", t.Name, t.Language.Code)
+	case "SendContacts":
+		contacts, _ := c.Args[1].([]whatsappdau.Contact)
+		names := make([]string, len(contacts))
+		for i, contact := range contacts {
+			names[i] = contact.Name.FormattedName
+		}
+		return fmt.Sprintf("contacts %q", names)
+	case "SendWhatsAppLocation":
+		return fmt.Sprintf("location %v,%v %q", c.Args[1], c.Args[2], c.Args[3])
+	case "SendImageToWhatsApp", "SendAudioToWhatsApp", "SendVideoToWhatsApp":
+		return fmt.Sprintf("%s %s", strings.TrimSuffix(strings.TrimPrefix(c.Method, "Send"), "ToWhatsApp"), c.Args[1])
+	case "SendImageFrom", "SendAudioFrom", "SendVideoFrom":
+		return fmt.Sprintf("%s %s", strings.TrimSuffix(strings.TrimPrefix(c.Method, "Send"), "From"), c.Args[1])
+	default:
+		return fmt.Sprintf("%s%v", c.Method, c.Args)
+	}
+}
+
+func listRowIDs(items []whatsappdau.ListItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func buttonIDs(buttons []whatsappdau.ButtonItem) []string {
+	ids := make([]string, len(buttons))
+	for i, b := range buttons {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+// AnyReply accepts any outbound message.
+func AnyReply() Expectation {
+	return Expectation{Description: "any message", Check: func(Call) string { return "" }}
+}
+
+func TextEquals(want string) Expectation {
+	return Expectation{
+		Description: fmt.Sprintf("text %q", want),
+		Check: func(c Call) string {
+			if c.Method != "SendMessage" {
+				return "not a text message"
+			}
+			if got := c.Args[1].(string); got != want {
+				return fmt.Sprintf("-%q\n        +%q", want, got)
+			}
+			return ""
+		},
+	}
+}
+
+func TextContaining(substr string) Expectation {
+	return Expectation{
+		Description: fmt.Sprintf("text containing %q", substr),
+		Check: func(c Call) string {
+			if c.Method != "SendMessage" {
+				return "not a text message"
+			}
+			if !strings.Contains(c.Args[1].(string), substr) {
+				return fmt.Sprintf("%q not found", substr)
+			}
+			return ""
+		},
+	}
+}
+
+// ListWithRows expects a list message containing at least the given row IDs.
+func ListWithRows(rowIDs ...string) Expectation {
+	return Expectation{
+		Description: fmt.Sprintf("list with rows %v", rowIDs),
+		Check: func(c Call) string {
+			if c.Method != "SendInteractiveList" {
+				return "not a list message"
+			}
+			items, _ := c.Args[3].([]whatsappdau.ListItem)
+			return missing(rowIDs, listRowIDs(items), "row")
+		},
+	}
+}
+
+// ButtonsWithIDs expects a button message containing at least the given
+// button IDs.
+func ButtonsWithIDs(ids ...string) Expectation {
+	return Expectation{
+		Description: fmt.Sprintf("buttons %v", ids),
+		Check: func(c Call) string {
+			if c.Method != "SendInteractiveButtons" {
+				return "not a button message"
+			}
+			buttons, _ := c.Args[3].([]whatsappdau.ButtonItem)
+			return missing(ids, buttonIDs(buttons), "button")
+		},
+	}
+}
+
+func missing(want, got []string, what string) string {
+	var absent []string
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if g == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			absent = append(absent, w)
+		}
+	}
+	if len(absent) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("missing %s(s) %v, have %v", what, absent, got)
+}
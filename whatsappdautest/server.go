@@ -0,0 +1,313 @@
+package whatsappdautest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+const (
+	FakeAPIVersion    = whatsappdau.DefaultAPIVersion
+	FakePhoneNumberID = "106540352242922"
+)
+
+// SentMessage is a message request received by a FakeServer.
+type SentMessage struct {
+	To      string
+	Type    string
+	Body    []byte
+	Payload map[string]interface{}
+}
+
+// UploadedMedia is a media upload received by a FakeServer.
+type UploadedMedia struct {
+	ID       string
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+// FakeServer is an httptest server that speaks enough of the Cloud API for
+// WhatsappClient: message sends, media upload, media URL lookup, media
+// download and read receipts. Everything it receives is recorded.
+type FakeServer struct {
+	*httptest.Server
+
+	// OnSend, if set, is called for every message received. It runs with
+	// the server locked and must not call FakeServer methods.
+	OnSend func(SentMessage)
+
+	mu       sync.Mutex
+	sent     []SentMessage
+	uploads  []UploadedMedia
+	media    map[string]UploadedMedia
+	read     []string
+	failures []fakeFailure
+	nextID   int
+	tier     whatsappdau.MessagingTier
+}
+
+type fakeFailure struct {
+	status int
+	body   string
+}
+
+func NewFakeServer() *FakeServer {
+	s := newFakeServer()
+	s.Server.Start()
+	return s
+}
+
+// NewFakeServerListener is like NewFakeServer but serves on l, so the
+// address is known in advance.
+func NewFakeServerListener(l net.Listener) *FakeServer {
+	s := newFakeServer()
+	s.Server.Listener.Close()
+	s.Server.Listener = l
+	s.Server.Start()
+	return s
+}
+
+func newFakeServer() *FakeServer {
+	s := &FakeServer{
+		media: make(map[string]UploadedMedia),
+		tier:  whatsappdau.Tier1K,
+	}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// MessagesURL is the apiURL to pass to whatsappdau.NewWhatsappClient.
+func (s *FakeServer) MessagesURL() string {
+	return fmt.Sprintf("%s/%s/%s/messages", s.URL, FakeAPIVersion, FakePhoneNumberID)
+}
+
+// Endpoint is MessagesURL in parts, for whatsappdau.NewWhatsappClientWithEndpoint.
+func (s *FakeServer) Endpoint() whatsappdau.Endpoint {
+	return whatsappdau.Endpoint{BaseURL: s.URL, Version: FakeAPIVersion, PhoneNumberID: FakePhoneNumberID}
+}
+
+// HTTPClient returns a client that sends every request to the fake server,
+// including those the library addresses to graph.facebook.com directly.
+func (s *FakeServer) HTTPClient() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: &rewriteTransport{target: target, next: s.Client().Transport}}
+}
+
+// NewClient returns a WhatsappClient wired to the fake server.
+func (s *FakeServer) NewClient(opts ...whatsappdau.Option) whatsappdau.Whatsapp {
+	return whatsappdau.NewWhatsappClient(context.Background(), s.MessagesURL(), "test-token", s.HTTPClient(), opts...)
+}
+
+// FailNext makes the next request fail with the given status and body.
+// Calls queue up, one failure per request.
+func (s *FakeServer) FailNext(status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, fakeFailure{status: status, body: body})
+}
+
+// SetMessagingTier sets the messaging_limit_tier reported for the phone number.
+func (s *FakeServer) SetMessagingTier(tier whatsappdau.MessagingTier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tier = tier
+}
+
+// AddMedia registers downloadable media under id.
+func (s *FakeServer) AddMedia(id, mimeType string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.media[id] = UploadedMedia{ID: id, MimeType: mimeType, Data: data}
+}
+
+func (s *FakeServer) Sent() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
+
+func (s *FakeServer) Uploads() []UploadedMedia {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]UploadedMedia(nil), s.uploads...)
+}
+
+// ReadReceipts returns the message IDs marked as read.
+func (s *FakeServer) ReadReceipts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.read...)
+}
+
+func (s *FakeServer) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+	s.uploads = nil
+	s.read = nil
+	s.failures = nil
+	s.media = make(map[string]UploadedMedia)
+}
+
+func (s *FakeServer) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(f.status)
+		io.WriteString(rw, f.body)
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "download/"):
+		s.serveDownload(rw, strings.TrimPrefix(path, "download/"))
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/"+FakePhoneNumberID):
+		s.servePhoneNumber(rw)
+	case r.Method == http.MethodGet:
+		s.serveMediaURL(rw, path[strings.LastIndex(path, "/")+1:])
+	case r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/"):
+		s.serveUpload(rw, r)
+	case r.Method == http.MethodPost:
+		s.serveMessage(rw, r)
+	default:
+		http.Error(rw, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *FakeServer) serveMessage(rw http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeGraphError(rw, http.StatusBadRequest, 100, err.Error())
+		return
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeGraphError(rw, http.StatusBadRequest, 100, "invalid JSON: "+err.Error())
+		return
+	}
+
+	if status, _ := payload["status"].(string); status == "read" {
+		id, _ := payload["message_id"].(string)
+		s.read = append(s.read, id)
+		writeJSON(rw, map[string]bool{"success": true})
+		return
+	}
+
+	to, _ := payload["to"].(string)
+	typ, _ := payload["type"].(string)
+	sent := SentMessage{To: to, Type: typ, Body: body, Payload: payload}
+	s.sent = append(s.sent, sent)
+	s.nextID++
+	if s.OnSend != nil {
+		s.OnSend(sent)
+	}
+
+	writeJSON(rw, whatsappdau.MessageResponse{
+		MessagingProduct: "whatsapp",
+		Contacts:         []whatsappdau.Contacts{{Input: to, WaId: to}},
+		Messages:         []whatsappdau.Messages{{Id: fmt.Sprintf("wamid.FAKE%06d", s.nextID)}},
+	})
+}
+
+func (s *FakeServer) serveUpload(rw http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeGraphError(rw, http.StatusBadRequest, 100, fmt.Sprintf("invalid multipart body: %v", err))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeGraphError(rw, http.StatusBadRequest, 100, "missing file part")
+		return
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+
+	s.nextID++
+	upload := UploadedMedia{
+		ID:       fmt.Sprintf("%d", 900000000+s.nextID),
+		Filename: header.Filename,
+		MimeType: r.FormValue("type"),
+		Data:     data,
+	}
+	s.uploads = append(s.uploads, upload)
+	s.media[upload.ID] = upload
+	writeJSON(rw, map[string]string{"id": upload.ID})
+}
+
+func (s *FakeServer) servePhoneNumber(rw http.ResponseWriter) {
+	writeJSON(rw, map[string]interface{}{
+		"id":                   FakePhoneNumberID,
+		"messaging_limit_tier": s.tier,
+		"quality_rating":       "GREEN",
+		"throughput":           map[string]string{"level": "STANDARD"},
+	})
+}
+
+func (s *FakeServer) serveMediaURL(rw http.ResponseWriter, id string) {
+	m, ok := s.media[id]
+	if !ok {
+		writeGraphError(rw, http.StatusNotFound, 100, "unknown media id "+id)
+		return
+	}
+	writeJSON(rw, whatsappdau.MediaUrl{
+		Id:       m.ID,
+		MimeType: m.MimeType,
+		FileSize: len(m.Data),
+		Url:      s.URL + "/download/" + m.ID,
+	})
+}
+
+func (s *FakeServer) serveDownload(rw http.ResponseWriter, id string) {
+	m, ok := s.media[id]
+	if !ok {
+		http.Error(rw, "not found", http.StatusNotFound)
+		return
+	}
+	rw.Header().Set("Content-Type", m.MimeType)
+	rw.Write(m.Data)
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func writeGraphError(rw http.ResponseWriter, status, code int, message string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": message,
+			"type":    "OAuthException",
+			"code":    code,
+		},
+	})
+}
+
+type rewriteTransport struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,236 @@
+package whatsappdautest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daulet140/whatsappdau"
+)
+
+// WebhookBuilder produces webhook notifications shaped like Meta's, for
+// testing handlers without fixtures:
+//
+//	body := whatsappdautest.NewWebhook().
+//		Contact("15551234567", "Ann").
+//		Text("15551234567", "hi").
+//		Image("15551234567", "media-1", "image/jpeg").
+//		Status("wamid.X", "15551234567", "delivered").
+//		JSON()
+//
+// Everything added goes into a single change on the FakePhoneNumberID
+// number. Message IDs are generated as wamid.TEST001, wamid.TEST002, ...
+type WebhookBuilder struct {
+	value  whatsappdau.WebhookValue
+	names  map[string]string
+	now    func() time.Time
+	nextID int
+}
+
+func NewWebhook() *WebhookBuilder {
+	return &WebhookBuilder{
+		value: whatsappdau.WebhookValue{
+			MessagingProduct: "whatsapp",
+			Metadata:         whatsappdau.WebhookMetadata{DisplayPhoneNumber: "15550000000", PhoneNumberID: FakePhoneNumberID},
+		},
+		names: make(map[string]string),
+		now:   time.Now,
+	}
+}
+
+// PhoneNumberID sets the business number the notification is for.
+func (b *WebhookBuilder) PhoneNumberID(id string) *WebhookBuilder {
+	b.value.Metadata.PhoneNumberID = id
+	return b
+}
+
+// Contact sets the profile name sent with messages from waID.
+func (b *WebhookBuilder) Contact(waID, name string) *WebhookBuilder {
+	b.names[waID] = name
+	for i := range b.value.Contacts {
+		if b.value.Contacts[i].WaID == waID {
+			b.value.Contacts[i].Profile.Name = name
+		}
+	}
+	return b
+}
+
+// Message adds msg, filling in ID and Timestamp when they are empty.
+func (b *WebhookBuilder) Message(msg whatsappdau.IncomingMessage) *WebhookBuilder {
+	if msg.ID == "" {
+		msg.ID = b.newID()
+	}
+	if msg.Timestamp == "" {
+		msg.Timestamp = b.timestamp()
+	}
+	b.addContact(msg.From)
+	b.value.Messages = append(b.value.Messages, msg)
+	return b
+}
+
+func (b *WebhookBuilder) Text(from, body string) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{From: from, Type: "text", Text: &whatsappdau.IncomingText{Body: body}})
+}
+
+// Reply adds a text message from from quoting the message contextID.
+func (b *WebhookBuilder) Reply(from, contextID, body string) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{
+		From:    from,
+		Type:    "text",
+		Text:    &whatsappdau.IncomingText{Body: body},
+		Context: &whatsappdau.MessageContext{From: FakePhoneNumberID, ID: contextID},
+	})
+}
+
+func (b *WebhookBuilder) Image(from, mediaID, mimeType string) *WebhookBuilder {
+	return b.media(from, "image", &whatsappdau.IncomingMedia{ID: mediaID, MimeType: mimeType})
+}
+
+func (b *WebhookBuilder) Audio(from, mediaID, mimeType string) *WebhookBuilder {
+	return b.media(from, "audio", &whatsappdau.IncomingMedia{ID: mediaID, MimeType: mimeType})
+}
+
+func (b *WebhookBuilder) Video(from, mediaID, mimeType string) *WebhookBuilder {
+	return b.media(from, "video", &whatsappdau.IncomingMedia{ID: mediaID, MimeType: mimeType})
+}
+
+func (b *WebhookBuilder) Document(from, mediaID, mimeType, filename string) *WebhookBuilder {
+	return b.media(from, "document", &whatsappdau.IncomingMedia{ID: mediaID, MimeType: mimeType, Filename: filename})
+}
+
+func (b *WebhookBuilder) media(from, typ string, media *whatsappdau.IncomingMedia) *WebhookBuilder {
+	media.Sha256 = "dGVzdA=="
+	msg := whatsappdau.IncomingMessage{From: from, Type: typ}
+	switch typ {
+	case "image":
+		msg.Image = media
+	case "audio":
+		msg.Audio = media
+	case "video":
+		msg.Video = media
+	case "document":
+		msg.Document = media
+	}
+	return b.Message(msg)
+}
+
+func (b *WebhookBuilder) Location(from string, latitude, longitude float64) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{From: from, Type: "location", Location: &whatsappdau.IncomingLocation{Latitude: latitude, Longitude: longitude}})
+}
+
+func (b *WebhookBuilder) ButtonReply(from, id, title string) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{From: from, Type: "interactive", Interactive: &whatsappdau.IncomingInteractive{
+		Type:        "button_reply",
+		ButtonReply: &whatsappdau.InteractiveReply{ID: id, Title: title},
+	}})
+}
+
+func (b *WebhookBuilder) ListReply(from, id, title string) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{From: from, Type: "interactive", Interactive: &whatsappdau.IncomingInteractive{
+		Type:      "list_reply",
+		ListReply: &whatsappdau.InteractiveReply{ID: id, Title: title},
+	}})
+}
+
+// QuickReply adds a tap on a template quick-reply button set with
+// QuickReplyButton.
+func (b *WebhookBuilder) QuickReply(from, payload, text string) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{From: from, Type: "button", Button: &whatsappdau.IncomingButton{Payload: payload, Text: text}})
+}
+
+func (b *WebhookBuilder) Reaction(from, messageID, emoji string) *WebhookBuilder {
+	return b.Message(whatsappdau.IncomingMessage{From: from, Type: "reaction", Reaction: &whatsappdau.IncomingReaction{MessageID: messageID, Emoji: emoji}})
+}
+
+// Status adds a status update for an outgoing message, e.g. "sent",
+// "delivered" or "read".
+func (b *WebhookBuilder) Status(messageID, recipient, status string) *WebhookBuilder {
+	b.value.Statuses = append(b.value.Statuses, whatsappdau.StatusUpdate{
+		ID:          messageID,
+		Status:      status,
+		Timestamp:   b.timestamp(),
+		RecipientID: recipient,
+	})
+	return b
+}
+
+// FailedStatus adds a "failed" status carrying the given error.
+func (b *WebhookBuilder) FailedStatus(messageID, recipient string, code int, title string) *WebhookBuilder {
+	b.Status(messageID, recipient, "failed")
+	b.value.Statuses[len(b.value.Statuses)-1].Errors = []whatsappdau.WebhookError{{Code: code, Title: title}}
+	return b
+}
+
+// Echo adds a text message a business agent sent to to from the WhatsApp
+// Business app. A notification holding only echoes is on the
+// smb_message_echoes field.
+func (b *WebhookBuilder) Echo(to, body string) *WebhookBuilder {
+	b.value.MessageEchoes = append(b.value.MessageEchoes, whatsappdau.MessageEcho{
+		IncomingMessage: whatsappdau.IncomingMessage{
+			From:      b.value.Metadata.DisplayPhoneNumber,
+			ID:        b.newID(),
+			Timestamp: b.timestamp(),
+			Type:      "text",
+			Text:      &whatsappdau.IncomingText{Body: body},
+		},
+		To: to,
+	})
+	return b
+}
+
+// Notification returns the built notification as ParseWebhook returns it,
+// so Raw is set throughout.
+func (b *WebhookBuilder) Notification() *whatsappdau.WebhookNotification {
+	notification, err := whatsappdau.ParseWebhook(b.JSON())
+	if err != nil {
+		panic(fmt.Sprintf("whatsappdautest: parse built webhook: %v", err))
+	}
+	return notification
+}
+
+// JSON returns the notification as Meta would post it.
+func (b *WebhookBuilder) JSON() []byte {
+	field := "messages"
+	if len(b.value.MessageEchoes) > 0 && len(b.value.Messages) == 0 && len(b.value.Statuses) == 0 {
+		field = "smb_message_echoes"
+	}
+	data, err := json.Marshal(&whatsappdau.WebhookNotification{
+		Object: "whatsapp_business_account",
+		Entry: []whatsappdau.WebhookEntry{{
+			ID:      "0",
+			Changes: []whatsappdau.WebhookChange{{Field: field, Value: b.value}},
+		}},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("whatsappdautest: marshal webhook: %v", err))
+	}
+	return data
+}
+
+// Request returns the notification as a delivery request, see
+// NewWebhookRequest.
+func (b *WebhookBuilder) Request(target, appSecret string) *http.Request {
+	return NewWebhookRequest(target, b.JSON(), appSecret)
+}
+
+func (b *WebhookBuilder) addContact(waID string) {
+	for _, c := range b.value.Contacts {
+		if c.WaID == waID {
+			return
+		}
+	}
+	contact := whatsappdau.WebhookContact{WaID: waID}
+	contact.Profile.Name = b.names[waID]
+	b.value.Contacts = append(b.value.Contacts, contact)
+}
+
+func (b *WebhookBuilder) newID() string {
+	b.nextID++
+	return fmt.Sprintf("wamid.TEST%03d", b.nextID)
+}
+
+func (b *WebhookBuilder) timestamp() string {
+	return strconv.FormatInt(b.now().Unix(), 10)
+}
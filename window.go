@@ -0,0 +1,272 @@
+package whatsappdau
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RecipientStore persists the recipients counted by a RecipientWindow. Admit
+// must be atomic so several processes sharing one store can't overshoot the
+// limit together.
+type RecipientStore interface {
+	// Admit records recipient at now unless the number of distinct recipients
+	// seen in (now-period, now] has reached limit. A recipient already in that
+	// range is always admitted and keeps its original timestamp. A negative
+	// limit means unlimited. oldest is the earliest timestamp still in range,
+	// or now if the range is empty.
+	Admit(ctx context.Context, recipient string, now time.Time, period time.Duration, limit int) (admitted bool, oldest time.Time, err error)
+	// Release removes recipient if Admit recorded it at at, handing back the
+	// slot of a send that did not go out.
+	Release(ctx context.Context, recipient string, at time.Time) error
+	// Count returns the number of distinct recipients seen after since.
+	Count(ctx context.Context, since time.Time) (int, error)
+}
+
+// RecipientWindow counts unique business-initiated recipients over a rolling
+// period, 24 hours by default, as Meta does for messaging limits.
+type RecipientWindow struct {
+	Period time.Duration
+	Store  RecipientStore
+
+	now func() time.Time
+}
+
+// NewRecipientWindow returns a 24 hour window backed by store, or by an
+// in-memory store when store is nil.
+func NewRecipientWindow(store RecipientStore) *RecipientWindow {
+	if store == nil {
+		store = NewMemoryRecipientStore()
+	}
+	return &RecipientWindow{
+		Period: 24 * time.Hour,
+		Store:  store,
+		now:    time.Now,
+	}
+}
+
+// Admit records recipient, normalized to a wa_id, unless doing so would take
+// the window over limit. When the recipient is refused, retryAt is when the
+// oldest entry expires.
+func (w *RecipientWindow) Admit(ctx context.Context, recipient string, limit int) (ok bool, retryAt time.Time, err error) {
+	ok, retryAt, _, err = w.reserve(ctx, recipient, limit)
+	return ok, retryAt, err
+}
+
+// reserve is Admit that also returns a func undoing the admission, for when
+// the send it was made for does not go out.
+func (w *RecipientWindow) reserve(ctx context.Context, recipient string, limit int) (ok bool, retryAt time.Time, release func(), err error) {
+	// Keyed like ServiceWindows, so "+1 555..." and "1555..." are one
+	// recipient.
+	recipient = normalizeWAID(recipient)
+	now := w.now()
+	ok, oldest, err := w.Store.Admit(ctx, recipient, now, w.Period, limit)
+	if err != nil {
+		return false, time.Time{}, nil, err
+	}
+	if !ok {
+		return false, oldest.Add(w.Period), nil, nil
+	}
+	release = func() {
+		// The send may have failed because ctx was cancelled.
+		if err := w.Store.Release(context.WithoutCancel(ctx), recipient, now); err != nil {
+			logger().Warn("recipient window: release failed", "recipient", recipient, "error", err)
+		}
+	}
+	return true, time.Time{}, release, nil
+}
+
+// Count returns the number of unique recipients currently in the window.
+func (w *RecipientWindow) Count(ctx context.Context) (int, error) {
+	return w.Store.Count(ctx, w.now().Add(-w.Period))
+}
+
+// BudgetStats is a snapshot of a limiter's window. Limit and Remaining are -1
+// when no limit applies.
+type BudgetStats struct {
+	Unique    int
+	Limit     int
+	Remaining int
+}
+
+func budgetStats(ctx context.Context, w *RecipientWindow, limit int) (BudgetStats, error) {
+	unique, err := w.Count(ctx)
+	if err != nil {
+		return BudgetStats{}, err
+	}
+	stats := BudgetStats{Unique: unique, Limit: limit, Remaining: -1}
+	if limit >= 0 {
+		stats.Remaining = limit - unique
+		if stats.Remaining < 0 {
+			stats.Remaining = 0
+		}
+	}
+	return stats, nil
+}
+
+// MemoryRecipientStore keeps the window in memory. Entries are also queued
+// by timestamp, so expiring them costs nothing until they are due.
+type MemoryRecipientStore struct {
+	mu    sync.Mutex
+	seen  map[string]time.Time
+	queue []seenRecipient
+}
+
+type seenRecipient struct {
+	recipient string
+	at        time.Time
+}
+
+func NewMemoryRecipientStore() *MemoryRecipientStore {
+	return &MemoryRecipientStore{seen: make(map[string]time.Time)}
+}
+
+func (s *MemoryRecipientStore) Admit(ctx context.Context, recipient string, now time.Time, period time.Duration, limit int) (bool, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now.Add(-period))
+	if _, seen := s.seen[recipient]; seen {
+		return true, s.oldest(now), nil
+	}
+	if limit >= 0 && len(s.seen) >= limit {
+		return false, s.oldest(now), nil
+	}
+	s.seen[recipient] = now
+	// Timestamps normally only grow; an earlier one, e.g. from a replay, is
+	// inserted in order.
+	i := sort.Search(len(s.queue), func(i int) bool { return s.queue[i].at.After(now) })
+	s.queue = append(s.queue, seenRecipient{})
+	copy(s.queue[i+1:], s.queue[i:])
+	s.queue[i] = seenRecipient{recipient: recipient, at: now}
+	return true, s.oldest(now), nil
+}
+
+func (s *MemoryRecipientStore) Release(ctx context.Context, recipient string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.seen[recipient]; !ok || !t.Equal(at) {
+		return nil
+	}
+	delete(s.seen, recipient)
+	i := sort.Search(len(s.queue), func(i int) bool { return !s.queue[i].at.Before(at) })
+	for ; i < len(s.queue) && s.queue[i].at.Equal(at); i++ {
+		if s.queue[i].recipient == recipient {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *MemoryRecipientStore) Count(ctx context.Context, since time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(since)
+	return len(s.seen), nil
+}
+
+// expire drops entries at or before since.
+func (s *MemoryRecipientStore) expire(since time.Time) {
+	for len(s.queue) > 0 && !s.queue[0].at.After(since) {
+		delete(s.seen, s.queue[0].recipient)
+		s.queue[0] = seenRecipient{}
+		s.queue = s.queue[1:]
+	}
+}
+
+// oldest returns the earliest timestamp in the window, or now if it is
+// empty.
+func (s *MemoryRecipientStore) oldest(now time.Time) time.Time {
+	if len(s.queue) == 0 {
+		return now
+	}
+	return s.queue[0].at
+}
+
+// ServiceWindows tracks the customer service window a customer opens by
+// messaging the business, 24 hours by default. Replies inside it are not
+// business-initiated, so a client with WithServiceWindows sends them past
+// its limiters without spending the tier budget. Register it on the
+// Dispatcher that receives the customers' messages.
+type ServiceWindows struct {
+	Period time.Duration
+
+	mu        sync.Mutex
+	last      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewServiceWindows() *ServiceWindows {
+	return &ServiceWindows{Period: 24 * time.Hour, last: make(map[string]time.Time), now: time.Now}
+}
+
+// WithServiceWindows lets sends to recipients with an open window in s skip
+// the limiters.
+func WithServiceWindows(s *ServiceWindows) Option {
+	return func(w *WhatsappClient) {
+		w.serviceWindows = s
+	}
+}
+
+// Register opens a window for every message d receives, before any handler
+// runs, so their replies already count as inside it.
+func (s *ServiceWindows) Register(d *Dispatcher) {
+	d.Use(func(next WebhookHandler) WebhookHandler {
+		return func(ctx context.Context, n *WebhookNotification) error {
+			for _, entry := range n.Entry {
+				for _, change := range entry.Changes {
+					for _, msg := range change.Value.Messages {
+						s.Observe(msg.From, parseWebhookTime(msg.Timestamp))
+					}
+				}
+			}
+			return next(ctx, n)
+		}
+	})
+}
+
+// Observe records a message from waID at at.
+func (s *ServiceWindows) Observe(waID string, at time.Time) {
+	waID = normalizeWAID(waID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at.After(s.last[waID]) {
+		s.last[waID] = at
+	}
+	// Closed windows are swept at most once a minute rather than on every
+	// message.
+	if now := s.clock(); now.Sub(s.lastSweep) >= time.Minute {
+		s.lastSweep = now
+		for id, t := range s.last {
+			if !t.After(now.Add(-s.period())) {
+				delete(s.last, id)
+			}
+		}
+	}
+}
+
+// Open reports whether recipient messaged the business within Period.
+func (s *ServiceWindows) Open(recipient string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.last[normalizeWAID(recipient)]
+	return ok && at.After(s.clock().Add(-s.period()))
+}
+
+func (s *ServiceWindows) period() time.Duration {
+	if s.Period <= 0 {
+		return 24 * time.Hour
+	}
+	return s.Period
+}
+
+func (s *ServiceWindows) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
@@ -0,0 +1,201 @@
+package whatsappdau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRecipientWindowAdmit(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type step struct {
+		at        time.Duration
+		recipient string
+		ok        bool
+		retryAt   time.Duration
+		count     int
+	}
+	tests := []struct {
+		name  string
+		limit int
+		steps []step
+	}{
+		{"under limit", 2, []step{
+			{0, "15550000001", true, 0, 1},
+			{time.Hour, "15550000002", true, 0, 2},
+		}},
+		{"repeat recipient is free", 1, []step{
+			{0, "15550000001", true, 0, 1},
+			{time.Hour, "15550000001", true, 0, 1},
+		}},
+		{"over limit until the oldest expires", 2, []step{
+			{0, "15550000001", true, 0, 1},
+			{time.Hour, "15550000002", true, 0, 2},
+			{2 * time.Hour, "15550000003", false, 24 * time.Hour, 2},
+			{24 * time.Hour, "15550000003", true, 0, 2},
+			{24*time.Hour + time.Minute, "15550000004", false, 25 * time.Hour, 2},
+		}},
+		{"expired recipient counts again", 1, []step{
+			{0, "15550000001", true, 0, 1},
+			{24 * time.Hour, "15550000001", true, 0, 1},
+			{25 * time.Hour, "15550000002", false, 48 * time.Hour, 1},
+		}},
+		{"formatting variants are one recipient", 1, []step{
+			{0, "+1 555 000 0001", true, 0, 1},
+			{time.Hour, "15550000001", true, 0, 1},
+		}},
+		{"unlimited", -1, []step{
+			{0, "15550000001", true, 0, 1},
+			{0, "15550000002", true, 0, 2},
+		}},
+		{"out of order timestamp", 3, []step{
+			{time.Hour, "15550000001", true, 0, 1},
+			{0, "15550000002", true, 0, 2},
+			{24*time.Hour + time.Second, "15550000003", true, 0, 2},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			w := NewRecipientWindow(nil)
+			var now time.Time
+			w.now = func() time.Time { return now }
+			for i, s := range tt.steps {
+				now = start.Add(s.at)
+				ok, retryAt, err := w.Admit(ctx, s.recipient, tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantRetry := time.Time{}
+				if !s.ok {
+					wantRetry = start.Add(s.retryAt)
+				}
+				if ok != s.ok || !retryAt.Equal(wantRetry) {
+					t.Errorf("step %d: Admit(%q) = %v, %v; want %v, %v", i, s.recipient, ok, retryAt, s.ok, wantRetry)
+				}
+				if n, _ := w.Count(ctx); n != s.count {
+					t.Errorf("step %d: Count() = %d, want %d", i, n, s.count)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRecipientStoreExpiresQueue(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryRecipientStore()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		s.Admit(ctx, strconv.Itoa(i), start.Add(time.Duration(i)*time.Second), time.Hour, -1)
+	}
+	if n, _ := s.Count(ctx, start.Add(500*time.Second)); n != 499 {
+		t.Errorf("Count() = %d, want 499", n)
+	}
+	if len(s.queue) != len(s.seen) {
+		t.Errorf("queue holds %d entries for %d recipients", len(s.queue), len(s.seen))
+	}
+}
+
+func TestMemoryRecipientStoreRelease(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryRecipientStore()
+	s.Admit(ctx, "a", start, time.Hour, 2)
+	s.Admit(ctx, "b", start.Add(time.Minute), time.Hour, 2)
+
+	// A release for another admission of a leaves the original entry.
+	s.Release(ctx, "a", start.Add(2*time.Minute))
+	s.Release(ctx, "b", start.Add(time.Minute))
+	if n, _ := s.Count(ctx, start.Add(-time.Second)); n != 1 {
+		t.Fatalf("Count() = %d, want 1", n)
+	}
+	if ok, _, _ := s.Admit(ctx, "c", start.Add(3*time.Minute), time.Hour, 2); !ok {
+		t.Error("released slot not reusable")
+	}
+	if len(s.queue) != len(s.seen) {
+		t.Errorf("queue holds %d entries for %d recipients", len(s.queue), len(s.seen))
+	}
+}
+
+type staticTier MessagingTier
+
+func (s staticTier) MessagingLimitTier(ctx context.Context) (MessagingTier, error) {
+	return MessagingTier(s), nil
+}
+
+func TestServiceWindowsSkipLimiters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"messages":[{"id":"wamid.1"}]}`)
+	}))
+	defer srv.Close()
+
+	tier := NewTierLimiter(staticTier(Tier50))
+	windows := NewServiceWindows()
+	client := NewWhatsappClient(context.Background(), srv.URL+"/v21.0/1/messages", "token", nil, WithLimiter(tier), WithServiceWindows(windows))
+	for i := 0; i < 50; i++ {
+		if _, err := client.SendMessage(fmt.Sprintf("1555000%04d", i), "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDispatcher("", "")
+	windows.Register(d)
+	handled := false
+	d.OnMessage(func(ctx context.Context, msg *InboundMessage) error {
+		handled = true
+		if !windows.Open(msg.From) {
+			t.Error("window not open when the handler runs")
+		}
+		_, err := client.SendMessage(msg.From, "reply")
+		return err
+	})
+	n := &WebhookNotification{Entry: []WebhookEntry{{Changes: []WebhookChange{{Field: "messages", Value: WebhookValue{
+		Messages: []IncomingMessage{{From: "15559999999", Type: "text", Timestamp: strconv.FormatInt(time.Now().Unix(), 10), Text: &IncomingText{Body: "hi"}}},
+	}}}}}}
+	if err := d.Dispatch(context.Background(), n); err != nil {
+		t.Fatalf("reply inside the service window: %v", err)
+	}
+	if !handled {
+		t.Fatal("handler not called")
+	}
+
+	_, err := client.SendMessage("15558888888", "hi")
+	if !errors.Is(err, ErrSendLimitReached) {
+		t.Errorf("new business-initiated recipient: got %v, want ErrSendLimitReached", err)
+	}
+	if stats, _ := tier.Stats(context.Background()); stats.Unique != 50 {
+		t.Errorf("tier counted %d recipients, want 50", stats.Unique)
+	}
+}
+
+func TestServiceWindowsExpire(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	s := NewServiceWindows()
+	s.now = func() time.Time { return now }
+	s.Observe("+1 555 000 0001", start)
+
+	tests := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{23 * time.Hour, true},
+		{24 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		now = start.Add(tt.at)
+		if got := s.Open("15550000001"); got != tt.want {
+			t.Errorf("at %v: Open() = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+	s.Observe("15550000002", now)
+	if _, ok := s.last["15550000001"]; ok {
+		t.Error("closed window not swept")
+	}
+}